@@ -0,0 +1,199 @@
+package gpool
+
+import (
+	"runtime"
+	"sync"
+)
+
+// affinityMaxSlots 是亲和缓存最多保存的 goroutine 数量，超出后新的 goroutine 直接使用共享存储。
+const affinityMaxSlots = 1024
+
+// WithGoroutineAffinity 让 Get 优先返回调用方 goroutine 最近一次 Put 的对象，
+// 适合每个 goroutine 一个 worker 的设计中那些在同一个 goroutine 内复用很便宜、
+// 但换到其他 goroutine 使用代价较高的对象（例如持有类似线程本地状态的对象）。
+//
+// 每个 goroutine 在亲和缓存中最多占有一个对象，Put 时它之前占有的对象被移入共享存储。
+// 调用方 goroutine 没有亲和对象时 Get 从共享存储取出对象；共享存储也为空时，
+// Get 会先取走其他 goroutine（包括已经退出的 goroutine）留下的亲和对象，再调用 newFunc 创建新对象，
+// 因此亲和缓存不会让闲置对象无法被使用。亲和缓存中的对象与共享存储中的对象一起计入 WithCapacity 和 WithMaxIdle 的上限。
+//
+// goroutine 由解析 runtime.Stack 得到的 goroutine id 识别，每次 Get 和 Put 会因此多出数微秒的开销，
+// 只有对象迁移的代价明显高于这个开销时才值得启用。
+// 由于 sync.Pool 按 P 而不是 goroutine 缓存对象，未指定其他后端时会改用确定性后端。
+func WithGoroutineAffinity[T any]() Option[T] {
+	return func(c *config[T]) {
+		c.affinity = true
+		c.needStore = true
+	}
+}
+
+// affine 在共享存储之外为每个 goroutine 保存它最近放入的一个对象。
+type affine[T any] struct {
+	shared store[T]
+
+	mu    sync.Mutex
+	slots map[int64]T
+}
+
+func newAffine[T any](shared store[T]) *affine[T] {
+	return &affine[T]{shared: shared, slots: make(map[int64]T)}
+}
+
+func (a *affine[T]) get() (T, bool) {
+	id := goid()
+	a.mu.Lock()
+	if x, ok := a.slots[id]; ok {
+		delete(a.slots, id)
+		a.mu.Unlock()
+		return x, true
+	}
+	a.mu.Unlock()
+
+	if x, ok := a.shared.get(); ok {
+		return x, true
+	}
+
+	// 共享存储为空时取走任意一个其他 goroutine 的亲和对象。
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for k, x := range a.slots {
+		delete(a.slots, k)
+		return x, true
+	}
+	var zero T
+	return zero, false
+}
+
+func (a *affine[T]) put(x T) bool {
+	id := goid()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	old, ok := a.slots[id]
+	if !ok {
+		if len(a.slots) >= affinityMaxSlots {
+			return a.shared.put(x)
+		}
+		a.slots[id] = x
+		return true
+	}
+	// 之前的亲和对象移入共享存储；共享存储已满时保留它，拒绝新放入的对象。
+	if !a.shared.put(old) {
+		return false
+	}
+	a.slots[id] = x
+	return true
+}
+
+func (a *affine[T]) len() int {
+	a.mu.Lock()
+	n := len(a.slots)
+	a.mu.Unlock()
+	return n + a.shared.len()
+}
+
+func (a *affine[T]) drain() []T {
+	a.mu.Lock()
+	slots := a.slots
+	a.slots = make(map[int64]T)
+	a.mu.Unlock()
+
+	items := a.shared.drain()
+	for _, x := range slots {
+		items = append(items, x)
+	}
+	return items
+}
+
+func (a *affine[T]) each(fn func(T)) {
+	a.mu.Lock()
+	for _, x := range a.slots {
+		fn(x)
+	}
+	a.mu.Unlock()
+	a.shared.each(fn)
+}
+
+func (a *affine[T]) filter(drop func(T) bool) []T {
+	var dropped []T
+	a.mu.Lock()
+	for id, x := range a.slots {
+		if drop(x) {
+			delete(a.slots, id)
+			dropped = append(dropped, x)
+		}
+	}
+	a.mu.Unlock()
+	return append(dropped, a.shared.filter(drop)...)
+}
+
+func (a *affine[T]) peek(fn func(T)) bool {
+	id := goid()
+	a.mu.Lock()
+	x, ok := a.slots[id]
+	if ok {
+		fn(x)
+	}
+	a.mu.Unlock()
+	if ok {
+		return true
+	}
+	s, ok := a.shared.(peeker[T])
+	return ok && s.peek(fn)
+}
+
+func (a *affine[T]) take(x T) bool {
+	a.mu.Lock()
+	for id, y := range a.slots {
+		if any(y) == any(x) {
+			delete(a.slots, id)
+			a.mu.Unlock()
+			return true
+		}
+	}
+	a.mu.Unlock()
+	s, ok := a.shared.(taker[T])
+	return ok && s.take(x)
+}
+
+// swap 替换亲和缓存和共享存储中的全部对象，替换期间一直持有亲和缓存的锁。
+func (a *affine[T]) swap(build func(n int) []T) []T {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var old []T
+	if s, ok := a.shared.(swapper[T]); ok {
+		old = s.swap(build)
+	}
+	fresh := build(len(a.slots))
+	for id, x := range a.slots {
+		old = append(old, x)
+		if len(fresh) > 0 {
+			a.slots[id] = fresh[0]
+			fresh = fresh[1:]
+		} else {
+			delete(a.slots, id)
+		}
+	}
+	return old
+}
+
+func (a *affine[T]) unwrap() store[T] {
+	return a.shared
+}
+
+// goid 返回当前 goroutine 的 id，它取自 runtime.Stack 输出的第一行 "goroutine N [...]"。
+func goid() int64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	const prefix = "goroutine "
+	if len(b) < len(prefix) {
+		return 0
+	}
+	var id int64
+	for _, c := range b[len(prefix):] {
+		if c < '0' || c > '9' {
+			break
+		}
+		id = id*10 + int64(c-'0')
+	}
+	return id
+}
@@ -0,0 +1,114 @@
+package gpool
+
+import (
+	"sync"
+	"testing"
+)
+
+// worker 是一个带编号的测试对象，用于区分 Get 返回的是哪个对象。
+type worker struct {
+	id int
+}
+
+// TestPool_GoroutineAffinity 测试 Get 优先返回调用方 goroutine 最近放入的对象，即使其他 goroutine 之后也放入了对象。
+func TestPool_GoroutineAffinity(t *testing.T) {
+	p := New(func() *worker {
+		return &worker{}
+	}, WithGoroutineAffinity[*worker]())
+
+	mine := &worker{id: 1}
+	p.Put(mine)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		p.Put(&worker{id: 2})
+	}()
+	wg.Wait()
+
+	if got := p.Get(); got != mine {
+		t.Errorf("Get() 应该返回当前 goroutine 最近放入的对象 1, 得到 %d", got.id)
+	}
+	// 当前 goroutine 没有亲和对象时，取走其他 goroutine 留下的对象而不是新建。
+	if got := p.Get(); got.id != 2 {
+		t.Errorf("没有亲和对象时应该退回到其他 goroutine 放入的对象 2, 得到 %d", got.id)
+	}
+	if s := p.Stats(); s.Misses != 0 {
+		t.Errorf("闲置对象都应该被复用, Misses 为 %d", s.Misses)
+	}
+}
+
+// TestPool_GoroutineAffinity_Displace 测试再次 Put 时之前的亲和对象被移入共享存储，并且计入闲置对象。
+func TestPool_GoroutineAffinity_Displace(t *testing.T) {
+	p := New(func() *worker {
+		return &worker{}
+	}, WithGoroutineAffinity[*worker](), WithCapacity[*worker](2))
+
+	first, second, third := &worker{id: 1}, &worker{id: 2}, &worker{id: 3}
+	p.Put(first)
+	p.Put(second)
+	if got := p.Stats().Idle; got != 2 {
+		t.Fatalf("亲和对象和共享存储中的对象都应该计入 Idle, 期望 2, 得到 %d", got)
+	}
+	// 亲和对象和共享存储中的对象已经达到容量，新放入的对象被丢弃。
+	p.Put(third)
+	if got := p.Stats().DiscardsByReason[DiscardOverflow]; got != 1 {
+		t.Errorf("共享存储已满时应该丢弃新放入的对象, 得到 %d 次溢出", got)
+	}
+
+	if got := p.Get(); got != second {
+		t.Errorf("Get() 应该返回亲和对象 2, 得到 %d", got.id)
+	}
+	if got := p.Get(); got != first {
+		t.Errorf("Get() 应该从共享存储返回对象 1, 得到 %d", got.id)
+	}
+	c := p.Config()
+	if !c.GoroutineAffinity || c.Backend != BackendFixed || c.Capacity != 2 {
+		t.Errorf("Config 应该报告亲和模式和被包装的后端: %+v", c)
+	}
+}
+
+// TestPool_GoroutineAffinity_Capacity 测试亲和缓存中的对象计入 WithCapacity 的容量，
+// 许多 goroutine 各自放回对象之后，池保留的闲置对象也不会超过容量。
+func TestPool_GoroutineAffinity_Capacity(t *testing.T) {
+	const capacity = 4
+	p := New(func() *worker {
+		return &worker{}
+	}, WithGoroutineAffinity[*worker](), WithCapacity[*worker](capacity))
+
+	// 所有 goroutine 都取出对象之后才开始放回，使池同时借出许多对象。
+	const goroutines = 4 * capacity
+	var got, wg sync.WaitGroup
+	got.Add(goroutines)
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			xs := []*worker{p.Get(), p.Get()}
+			got.Done()
+			got.Wait()
+			for _, x := range xs {
+				p.Put(x)
+				if n := p.Stats().Idle; n > capacity {
+					t.Errorf("闲置对象数量 %d 超过了容量 %d", n, capacity)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := p.Stats().Idle; n != capacity {
+		t.Errorf("期望保留 %d 个闲置对象, 得到 %d", capacity, n)
+	}
+	if s := p.Stats(); s.DiscardsByReason[DiscardOverflow] == 0 {
+		t.Errorf("超过容量的对象应该以 DiscardOverflow 丢弃: %+v", s)
+	}
+}
+
+// BenchmarkGoid 测量获取 goroutine id 的开销。
+func BenchmarkGoid(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		goid()
+	}
+}
@@ -0,0 +1,80 @@
+package gpool
+
+import "sync"
+
+// WithAsyncPut 让 Put 把对象交给一个后台 goroutine 处理后立即返回，
+// 重置、校验、存储等放回时的工作都在后台完成，从而缩短请求路径上 Put 的尾延迟。
+// queueSize 是等待处理的对象队列的长度，队列已满时 Put 退回为同步执行。queueSize <= 0 表示不启用。
+//
+// Stats 中的 Puts 在 Put 返回前就已经计数，WithTap 的回调也在调用方 goroutine 中执行；
+// 对象直到被后台 goroutine 处理后才成为闲置对象，有界池的额度也在那时才被归还，
+// 因此紧接着的 Get 不一定能取回刚放回的对象。只有 Put 是异步的，PutAll 等方法仍然同步执行。
+//
+// 后台 goroutine 在 Stop 或 Close 时退出，退出前会处理完队列中的所有对象。
+func WithAsyncPut[T any](queueSize int) Option[T] {
+	return func(c *config[T]) {
+		c.asyncPut = queueSize
+	}
+}
+
+// asyncPutter 是处理 WithAsyncPut 队列的后台 goroutine。
+type asyncPutter[T any] struct {
+	queue chan T
+	done  chan struct{}
+
+	mu      sync.RWMutex // 保护 stopped，避免向已关闭的队列发送对象
+	stopped bool
+}
+
+// startAsyncPut 在配置了 WithAsyncPut 时启动后台 goroutine。
+func (p *Pool[T]) startAsyncPut() {
+	p.async = nil
+	if p.cfg.asyncPut <= 0 {
+		return
+	}
+	a := &asyncPutter[T]{queue: make(chan T, p.cfg.asyncPut), done: make(chan struct{})}
+	p.async = a
+	go func() {
+		defer close(a.done)
+		for x := range a.queue {
+			p.finishPut(x)
+		}
+	}()
+}
+
+// enqueue 尝试把 x 交给后台 goroutine，队列已满或已经停止时返回 false。
+func (a *asyncPutter[T]) enqueue(x T) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.stopped {
+		return false
+	}
+	select {
+	case a.queue <- x:
+		return true
+	default:
+		return false
+	}
+}
+
+// stop 停止接收新的对象，等待后台 goroutine 处理完队列中的对象后退出。重复调用是安全的。
+func (a *asyncPutter[T]) stop() {
+	a.mu.Lock()
+	if !a.stopped {
+		a.stopped = true
+		close(a.queue)
+	}
+	a.mu.Unlock()
+	<-a.done
+}
+
+// Stop 停止池的后台 goroutine（WithAsyncPut 的后台 Put、WithAutoScale 的自动伸缩）并等待它们退出。
+// WithAsyncPut 队列中的对象会在 Stop 返回前全部被处理，之后的 Put 同步执行。
+//
+// Stop 不会关闭池，池仍然可以正常使用；Close 会自动调用 Stop。重复调用是安全的。
+func (p *Pool[T]) Stop() {
+	if p.async != nil {
+		p.async.stop()
+	}
+	p.stopMaintainer()
+}
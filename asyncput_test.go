@@ -0,0 +1,115 @@
+package gpool
+
+import (
+	"bytes"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestPool_AsyncPut 测试 Put 立即返回，对象在后台被重置并最终存入池中，Stop 会处理完队列中的所有对象。
+func TestPool_AsyncPut(t *testing.T) {
+	release := make(chan struct{})
+	var resets int32
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer](), WithAsyncPut[*bytes.Buffer](8),
+		WithRecycle(func(b *bytes.Buffer) *bytes.Buffer {
+			<-release // 模拟耗时的重置
+			b.Reset()
+			atomic.AddInt32(&resets, 1)
+			return b
+		}))
+
+	bufs := []*bytes.Buffer{bytes.NewBufferString("a"), bytes.NewBufferString("b"), bytes.NewBufferString("c")}
+	done := make(chan struct{})
+	go func() {
+		for _, b := range bufs {
+			p.Put(b)
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("WithAsyncPut 的 Put 不应等待重置完成")
+	}
+	if got := p.Stats().Puts; got != 3 {
+		t.Errorf("Put 返回前就应该计数, 期望 3, 得到 %d", got)
+	}
+
+	close(release)
+	p.Stop()
+	if got := atomic.LoadInt32(&resets); got != 3 {
+		t.Errorf("Stop 应该处理完队列中的对象, 重置了 %d 个", got)
+	}
+	if got := p.Stats().Idle; got != 3 {
+		t.Errorf("对象最终应该存入池中, Idle 为 %d", got)
+	}
+	for i := 0; i < 3; i++ {
+		if b := p.Get(); b.Len() != 0 {
+			t.Errorf("取回的对象应该已被重置, 内容为 %q", b.String())
+		}
+	}
+
+	// Stop 之后 Put 同步执行。
+	p.Put(new(bytes.Buffer))
+	if got := p.Stats().Idle; got != 1 {
+		t.Errorf("Stop 之后 Put 应该同步存入对象, Idle 为 %d", got)
+	}
+}
+
+// TestPool_AsyncPut_QueueFull 测试队列已满时 Put 退回为同步执行。
+func TestPool_AsyncPut_QueueFull(t *testing.T) {
+	release := make(chan struct{})
+	var started int32
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer](), WithAsyncPut[*bytes.Buffer](1),
+		WithRecycle(func(b *bytes.Buffer) *bytes.Buffer {
+			if atomic.AddInt32(&started, 1) == 1 {
+				<-release // 阻塞后台 goroutine
+			}
+			return b
+		}))
+	defer p.Close()
+
+	p.Put(new(bytes.Buffer)) // 被后台 goroutine 取走并阻塞
+	for atomic.LoadInt32(&started) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	p.Put(new(bytes.Buffer)) // 占满队列
+	p.Put(new(bytes.Buffer)) // 同步执行
+	if got := p.Stats().Idle; got != 1 {
+		t.Errorf("队列已满时 Put 应该同步存入对象, Idle 为 %d", got)
+	}
+	close(release)
+	p.Stop()
+	if got := p.Stats().Idle; got != 3 {
+		t.Errorf("Stop 后所有对象都应该存入池中, Idle 为 %d", got)
+	}
+}
+
+// TestPool_AsyncPut_Bounded 测试有界池的额度在后台存入对象后归还，Close 会先处理完队列。
+func TestPool_AsyncPut_Bounded(t *testing.T) {
+	var closed int32
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithMax[*bytes.Buffer](1), WithDeterministic[*bytes.Buffer](), WithAsyncPut[*bytes.Buffer](4),
+		WithOnDiscard(func(_ *bytes.Buffer, reason string) {
+			if reason == DiscardClosed {
+				atomic.AddInt32(&closed, 1)
+			}
+		}))
+
+	b := p.Get()
+	p.Put(b)
+	if got := p.Get(); got != b {
+		t.Error("额度归还后, Get 应该取回后台存入的对象")
+	}
+	p.Put(b)
+	p.Close()
+	if got := atomic.LoadInt32(&closed); got != 1 {
+		t.Errorf("Close 应该处理完队列并丢弃对象, 丢弃了 %d 个", got)
+	}
+}
@@ -0,0 +1,56 @@
+package gpool
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// WithAuditLog 让池每丢弃一个对象就向 w 写入一行审计记录，格式为
+//
+//	<时间戳> discard id=<对象标识> reason=<丢弃原因>
+//
+// 时间戳是 RFC 3339 格式（纳秒精度）的 UTC 时间，丢弃原因是 Discard* 常量之一。
+// 对象标识是指针、map、切片等引用类型的值所指向的地址，其他类型的值记为 "-"；
+// 审计记录不会包含对象的内容，适合处理密钥等敏感数据的池留存丢弃记录。
+//
+// 对 w 的写入是串行的，每条记录通过一次 Write 写入，并发丢弃时各行不会交错。
+// 写入错误会被忽略；w 的写入会阻塞丢弃对象的调用方，因此 w 应该足够快或自带缓冲。
+func WithAuditLog[T any](w io.Writer) Option[T] {
+	return func(c *config[T]) {
+		c.audit = &auditLog{w: w}
+	}
+}
+
+// auditLog 串行地向 w 写入丢弃记录。
+type auditLog struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// record 写入一条对象 x 以 reason 为原因被丢弃的记录。
+func (a *auditLog) record(x any, reason string) {
+	line := fmt.Sprintf("%s discard id=%s reason=%s\n",
+		time.Now().UTC().Format(time.RFC3339Nano), objectID(x), reason)
+	a.mu.Lock()
+	// 审计日志的写入错误不影响丢弃本身。
+	a.w.Write([]byte(line))
+	a.mu.Unlock()
+}
+
+// objectID 返回审计记录中对象的标识：引用类型的值为它指向的地址，nil 为 "nil"，其他值为 "-"。
+func objectID(x any) string {
+	v := reflect.ValueOf(x)
+	switch v.Kind() {
+	case reflect.Invalid:
+		return "nil"
+	case reflect.Chan, reflect.Func, reflect.Map, reflect.Pointer, reflect.Slice, reflect.UnsafePointer:
+		if v.IsNil() {
+			return "nil"
+		}
+		return fmt.Sprintf("%#x", v.Pointer())
+	}
+	return "-"
+}
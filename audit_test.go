@@ -0,0 +1,87 @@
+package gpool
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestPool_AuditLog 测试每次丢弃都会写入一行包含时间戳、对象地址和丢弃原因的审计记录。
+func TestPool_AuditLog(t *testing.T) {
+	var log bytes.Buffer
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithCapacity[*bytes.Buffer](1), WithAuditLog[*bytes.Buffer](&log))
+
+	before := time.Now().UTC()
+	a, b := new(bytes.Buffer), new(bytes.Buffer)
+	p.Put(a)
+	p.Put(b) // 已满，以 DiscardOverflow 丢弃
+	p.Put(nil)
+	p.Clear() // a 以 DiscardCleared 丢弃
+	after := time.Now().UTC()
+
+	lines := strings.Split(strings.TrimSuffix(log.String(), "\n"), "\n")
+	want := []string{
+		fmt.Sprintf("discard id=%p reason=%s", b, DiscardOverflow),
+		fmt.Sprintf("discard id=nil reason=%s", DiscardNil),
+		fmt.Sprintf("discard id=%p reason=%s", a, DiscardCleared),
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("期望 %d 行审计记录, 得到 %d 行:\n%s", len(want), len(lines), log.String())
+	}
+	for i, line := range lines {
+		ts, rest, _ := strings.Cut(line, " ")
+		if rest != want[i] {
+			t.Errorf("第 %d 行期望 %q, 得到 %q", i, want[i], rest)
+		}
+		at, err := time.Parse(time.RFC3339Nano, ts)
+		if err != nil || at.Before(before) || at.After(after) {
+			t.Errorf("第 %d 行的时间戳 %q 无效或不在测试期间: %v", i, ts, err)
+		}
+	}
+}
+
+// TestPool_AuditLog_Concurrent 测试并发丢弃时审计记录逐行完整，不会交错。
+func TestPool_AuditLog_Concurrent(t *testing.T) {
+	var log bytes.Buffer
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithCapacity[*bytes.Buffer](0), WithAuditLog[*bytes.Buffer](&log))
+
+	const goroutines, perG = 8, 50
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perG; j++ {
+				p.Put(new(bytes.Buffer))
+			}
+		}()
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(log.String(), "\n"), "\n")
+	if len(lines) != goroutines*perG {
+		t.Fatalf("期望 %d 行审计记录, 得到 %d 行", goroutines*perG, len(lines))
+	}
+	for _, line := range lines {
+		if f := strings.Fields(line); len(f) != 4 || f[1] != "discard" || f[3] != "reason="+DiscardOverflow {
+			t.Fatalf("审计记录不完整: %q", line)
+		}
+	}
+}
+
+// TestObjectID 测试审计记录中非引用类型的值不会输出内容。
+func TestObjectID(t *testing.T) {
+	if got := objectID("secret"); got != "-" {
+		t.Errorf("字符串的标识应该为 -, 得到 %q", got)
+	}
+	if got := objectID((*int)(nil)); got != "nil" {
+		t.Errorf("nil 指针的标识应该为 nil, 得到 %q", got)
+	}
+}
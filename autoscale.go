@@ -0,0 +1,61 @@
+package gpool
+
+import "time"
+
+// autoScaler 根据观察到的平均等待时间调整有界池的上限。
+type autoScaler struct {
+	sem      *semaphore
+	min, max int64
+	target   time.Duration
+}
+
+// step 根据上一个周期的平均等待时间调整一次上限：
+// 平均等待时间超过目标时按当前上限的 1/4（至少 1）扩容，低于目标的一半时缩容 1，
+// 上限始终保持在 [min, max] 范围内。这个周期内没有获取额度、也没有等待者时不做调整。
+//
+// 队首等待者已经等待的时间同样计入平均等待时间：请求数量超过当前上限的 GetAll 会使后面的请求都排在它后面，
+// 整个周期内可能没有任何请求获得额度，不计入它就永远不会扩容。
+//
+// 调整通过 semaphore.resize 在信号量的锁内完成，缩容不会收回已借出的额度，因此不会超额借出。
+func (a *autoScaler) step() {
+	n, waited, stalled := a.sem.sample()
+	if n == 0 && stalled == 0 {
+		return
+	}
+	var avg time.Duration
+	if n > 0 {
+		avg = waited / time.Duration(n)
+	}
+	if stalled > avg {
+		avg = stalled
+	}
+	limit := a.sem.limit()
+	next := limit
+	switch {
+	case avg > a.target && limit < a.max:
+		grow := limit / 4
+		if grow < 1 {
+			grow = 1
+		}
+		next = limit + grow
+		if next > a.max {
+			next = a.max
+		}
+	case avg < a.target/2 && limit > a.min:
+		next = limit - 1
+	}
+	if next != limit {
+		a.sem.resize(next)
+	}
+}
+
+// clampInt 将 n 限制在 [lo, hi] 范围内。
+func clampInt(n, lo, hi int) int {
+	if n < lo {
+		return lo
+	}
+	if n > hi {
+		return hi
+	}
+	return n
+}
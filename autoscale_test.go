@@ -0,0 +1,193 @@
+package gpool
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestPool_AutoScale_Grow 测试 Get 的平均等待时间超过目标时，自动伸缩会提高上限且不会超过 max。
+func TestPool_AutoScale_Grow(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithMax[*bytes.Buffer](2), WithAutoScale[*bytes.Buffer](1, 3, time.Millisecond))
+	defer p.Close()
+
+	if got := p.Config().Max; got != 2 {
+		t.Fatalf("初始上限应该为 WithMax 的值 2, 得到 %d", got)
+	}
+
+	for round := 0; round < 3; round++ {
+		// 占满当前的全部额度，使下一个 Get 必须等待。
+		limit := p.Config().Max
+		held := make([]*bytes.Buffer, limit)
+		for i := range held {
+			held[i] = p.Get()
+		}
+		done := make(chan *bytes.Buffer)
+		go func() {
+			done <- p.Get()
+		}()
+		time.Sleep(20 * time.Millisecond)
+		p.Put(held[0])
+		p.Put(<-done)
+		for _, b := range held[1:] {
+			p.Put(b)
+		}
+		p.scaler.step()
+	}
+
+	if got := p.Config().Max; got != 3 {
+		t.Errorf("持续等待后上限应该提高到 max 3, 得到 %d", got)
+	}
+}
+
+// TestPool_AutoScale_Shrink 测试 Get 几乎不需要等待时，自动伸缩会逐步降低上限且不会低于 min。
+func TestPool_AutoScale_Shrink(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithMax[*bytes.Buffer](3), WithAutoScale[*bytes.Buffer](2, 4, 10*time.Millisecond))
+	defer p.Close()
+
+	for round := 0; round < 3; round++ {
+		p.Put(p.Get())
+		p.scaler.step()
+	}
+	if got := p.Config().Max; got != 2 {
+		t.Errorf("没有等待时上限应该降低到 min 2, 得到 %d", got)
+	}
+
+	// 周期内没有获取额度时不做调整。
+	p.scaler.step()
+	if got := p.Config().Max; got != 2 {
+		t.Errorf("空闲周期不应调整上限, 得到 %d", got)
+	}
+}
+
+// TestPool_AutoScale_NoOverAdmit 测试在并发负载下调整上限时，同时借出的对象数量不会超过 max。
+func TestPool_AutoScale_NoOverAdmit(t *testing.T) {
+	const max = 4
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithAutoScale[*bytes.Buffer](1, max, time.Microsecond))
+	defer p.Close()
+
+	var (
+		mu       sync.Mutex
+		out, top int
+		wg       sync.WaitGroup
+		stop     = make(chan struct{})
+	)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				b := p.Get()
+				mu.Lock()
+				out++
+				if out > top {
+					top = out
+				}
+				mu.Unlock()
+				time.Sleep(100 * time.Microsecond)
+				mu.Lock()
+				out--
+				mu.Unlock()
+				p.Put(b)
+			}
+		}()
+	}
+	for i := 0; i < 20; i++ {
+		time.Sleep(2 * time.Millisecond)
+		p.scaler.step()
+	}
+	close(stop)
+	wg.Wait()
+
+	if top > max {
+		t.Errorf("同时借出的对象数量不应超过 max %d, 得到 %d", max, top)
+	}
+	if got := p.Config().Max; got <= 1 {
+		t.Errorf("持续等待时上限应该从初始值 1 提高, 得到 %d", got)
+	}
+}
+
+// TestPool_AutoScale_Config 测试 Config 报告自动伸缩的参数，且未设置 WithMax 时池以 min 为上限变为有界池。
+func TestPool_AutoScale_Config(t *testing.T) {
+	p := New(func() int { return 0 }, WithAutoScale[int](2, 8, time.Millisecond))
+	defer p.Close()
+
+	c := p.Config()
+	if c.Max != 2 || c.AutoScaleMin != 2 || c.AutoScaleMax != 8 || c.AutoScaleTarget != time.Millisecond {
+		t.Errorf("Config 与 WithAutoScale 的参数不一致: %+v", c)
+	}
+}
+
+// TestPool_AutoScale_GetAll 测试请求数量超过当前上限但不超过 max 的 GetAll 不会 panic，
+// 而是等待自动伸缩提高上限；后台缩容使上限低于请求数量时，等待中的 GetAll 也不会失败。
+func TestPool_AutoScale_GetAll(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithAutoScale[*bytes.Buffer](1, 10, time.Millisecond))
+	defer p.Close()
+
+	getAll := func(n int) <-chan any {
+		done := make(chan any, 1)
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					done <- r
+				}
+			}()
+			done <- p.GetAll(n)
+		}()
+		return done
+	}
+	await := func(done <-chan any) []*bytes.Buffer {
+		t.Helper()
+		for {
+			select {
+			case r := <-done:
+				xs, ok := r.([]*bytes.Buffer)
+				if !ok {
+					t.Fatalf("GetAll 不应该失败, 得到 %v", r)
+				}
+				return xs
+			case <-time.After(5 * time.Millisecond):
+				p.scaler.step()
+			}
+		}
+	}
+
+	xs := await(getAll(2))
+	if len(xs) != 2 || p.Config().Max < 2 {
+		t.Fatalf("期望上限提高到至少 2 并取得 2 个对象, 得到 %d 个, 上限 %d", len(xs), p.Config().Max)
+	}
+	p.PutAll(xs[1:])
+
+	// 仍有一个对象被借出时，GetAll(2) 需要等待；期间缩容到 1 不应该使它失败。
+	done := getAll(2)
+	time.Sleep(5 * time.Millisecond)
+	p.sem.resize(1)
+	select {
+	case r := <-done:
+		t.Fatalf("缩容后等待中的 GetAll 不应该返回, 得到 %v", r)
+	case <-time.After(5 * time.Millisecond):
+	}
+	p.Put(xs[0])
+	if xs := await(done); len(xs) != 2 {
+		t.Fatalf("期望取得 2 个对象, 得到 %d 个", len(xs))
+	}
+
+	// 超过 max 的请求仍然会 panic。
+	if r := <-getAll(11); r != errExceedsMax {
+		t.Errorf("超过 max 的 GetAll 应该以 errExceedsMax panic, 得到 %v", r)
+	}
+}
@@ -0,0 +1,373 @@
+package gpool
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// semaphore 是一个带权重的 FIFO 信号量，用于限制有界池中同时借出的对象数量。
+// 一次请求多个许可时要么全部获得、要么一个都不获得，避免部分获取导致的死锁。
+type semaphore struct {
+	mu      sync.Mutex
+	size    int64
+	ceiling int64 // 容量可能增长到的最大值，自动伸缩的池为 WithAutoScale 的 max，否则为 0
+	cur     int64
+	closed  bool
+	waiters list.List // 元素类型为 *semWaiter
+
+	// acquires 和 waited 是自上次 sample 以来获得许可的次数和等待许可的总时间。
+	acquires int64
+	waited   time.Duration
+}
+
+type semWaiter struct {
+	n     int64
+	start time.Time     // 开始等待的时间
+	ready chan struct{} // 获得许可或请求失败时被关闭
+	err   error         // 请求失败的原因，在 ready 关闭前写入
+}
+
+func newSemaphore(n int64) *semaphore {
+	return &semaphore{size: n}
+}
+
+// acquire 阻塞直到获得 n 个许可或 ctx 结束，ctx 结束时返回对应类别的 PoolError。
+// n 超过信号量可能达到的最大容量时永远无法满足，直接返回 errExceedsMax；信号量已关闭时返回 ErrClosed。
+// 自动伸缩的信号量上，超过当前容量但不超过 ceiling 的请求会等待容量增长。
+func (s *semaphore) acquire(ctx context.Context, n int64) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrClosed
+	}
+	if n > s.maxSize() {
+		s.mu.Unlock()
+		return errExceedsMax
+	}
+	if s.size-s.cur >= n && s.waiters.Len() == 0 {
+		s.cur += n
+		s.acquires++
+		s.mu.Unlock()
+		return nil
+	}
+
+	w := &semWaiter{n: n, start: time.Now(), ready: make(chan struct{})}
+	elem := s.waiters.PushBack(w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return w.err
+	case <-ctx.Done():
+		s.mu.Lock()
+		select {
+		case <-w.ready:
+			if w.err != nil {
+				break
+			}
+			// 在 ctx 结束的同时获得了许可，将其归还后按取消处理。
+			s.cur -= n
+			s.notifyWaiters()
+		default:
+			isFront := s.waiters.Front() == elem
+			s.waiters.Remove(elem)
+			// 排在队首的等待者离开后，后面的等待者可能已经可以被满足。
+			if isFront && s.size > s.cur {
+				s.notifyWaiters()
+			}
+		}
+		s.mu.Unlock()
+		return contextError(ctx.Err())
+	}
+}
+
+// maxSize 返回信号量的容量可能达到的最大值，调用方必须持有 s.mu。
+func (s *semaphore) maxSize() int64 {
+	if s.ceiling > s.size {
+		return s.ceiling
+	}
+	return s.size
+}
+
+// tryAcquire 尝试在不阻塞的情况下获得 n 个许可。
+func (s *semaphore) tryAcquire(n int64) bool {
+	s.mu.Lock()
+	ok := !s.closed && s.size-s.cur >= n && s.waiters.Len() == 0
+	if ok {
+		s.cur += n
+		s.acquires++
+	}
+	s.mu.Unlock()
+	return ok
+}
+
+// release 归还 n 个许可。归还的许可多于已借出的数量时（例如放回了不是从池中取出的对象），
+// 多出的部分会被忽略。
+func (s *semaphore) release(n int64) {
+	s.mu.Lock()
+	s.cur -= n
+	if s.cur < 0 {
+		s.cur = 0
+	}
+	s.notifyWaiters()
+	s.mu.Unlock()
+}
+
+// notifyWaiters 按 FIFO 顺序唤醒可以被满足的等待者，调用方必须持有 s.mu。
+func (s *semaphore) notifyWaiters() {
+	for {
+		next := s.waiters.Front()
+		if next == nil {
+			return
+		}
+		w := next.Value.(*semWaiter)
+		if s.size-s.cur < w.n {
+			// 即使后面有更小的请求也不越过队首，避免大请求被饿死。
+			return
+		}
+		s.cur += w.n
+		s.acquires++
+		s.waited += time.Since(w.start)
+		s.waiters.Remove(next)
+		close(w.ready)
+	}
+}
+
+// close 关闭信号量：所有等待者都以 ErrClosed 失败，之后的请求也都会失败。
+func (s *semaphore) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for e := s.waiters.Front(); e != nil; e = e.Next() {
+		w := e.Value.(*semWaiter)
+		w.err = ErrClosed
+		close(w.ready)
+	}
+	s.waiters.Init()
+}
+
+// resize 将信号量的容量调整为 size。
+//
+// 扩容会立即唤醒可以被满足的等待者。缩容不会收回已借出的许可，
+// 而是在许可陆续归还时自然生效：借出数量降到新容量以下之前，新的请求都会等待。
+// 请求数量超过新容量的等待者永远无法被满足，会以 errExceedsMax 失败；
+// 自动伸缩的信号量上，只有超过 ceiling 的等待者才会失败，其余的等待者继续等待容量重新增长。
+func (s *semaphore) resize(size int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.size = size
+	max := s.maxSize()
+	for e := s.waiters.Front(); e != nil; {
+		next := e.Next()
+		if w := e.Value.(*semWaiter); w.n > max {
+			w.err = errExceedsMax
+			s.waiters.Remove(e)
+			close(w.ready)
+		}
+		e = next
+	}
+	s.notifyWaiters()
+}
+
+// sample 返回自上次调用以来获得许可的次数和等待许可的总时间，并将它们清零；
+// stalled 是排在队首的等待者到目前为止已经等待的时间，没有等待者时为 0。
+func (s *semaphore) sample() (acquires int64, waited, stalled time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	acquires, waited = s.acquires, s.waited
+	s.acquires, s.waited = 0, 0
+	if front := s.waiters.Front(); front != nil {
+		stalled = time.Since(front.Value.(*semWaiter).start)
+	}
+	return acquires, waited, stalled
+}
+
+// limit 返回信号量当前的容量。
+func (s *semaphore) limit() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+// SetMax 在运行时调整有界池同时借出对象数量的上限，n 必须大于 0。
+//
+// 调高上限会立即放行等待中的 Get。调低上限不会收回已借出的对象，
+// 而是在对象陆续被放回时生效：借出数量降到新上限以下之前，新的 Get 都会等待，
+// 因此不会超额借出，也不会使进行中的 Get 死锁。
+// 等待中的 GetAll 如果请求数量超过了新上限（启用 WithAutoScale 时为它的 max），会像直接超过上限一样 panic。
+//
+// 只能对通过 WithMax 创建的有界池调用 SetMax，否则会 panic。
+func (p *Pool[T]) SetMax(n int) {
+	if p.sem == nil {
+		panic("gpool: SetMax called on an unbounded pool")
+	}
+	if n <= 0 {
+		panic("gpool: SetMax requires a positive limit")
+	}
+	p.sem.resize(int64(n))
+}
+
+// GetContext 与 Get 相同，但等待有界池的额度时可以被 ctx 取消，适合连接等昂贵的资源：
+// 借出的对象达到 WithMax 的上限时，它会一直等待到有对象被放回或 ctx 结束，而不是创建超出上限的对象。
+// ctx 超过截止时间时返回 Kind 为 KindTimeout 的 *PoolError，被取消时返回 Kind 为 KindCancelled 的 *PoolError，
+// 它们可以分别用 errors.Is 与 ErrTimeout、ErrCancelled 比较，也可以与 ctx.Err() 比较。
+//
+// 无界池不需要等待，GetContext 总是立即返回对象。与 Get 一样，池被关闭后不再等待额度。
+func (p *Pool[T]) GetContext(ctx context.Context) (T, error) {
+	p.awaitWarm()
+	if p.sem != nil {
+		if err := p.sem.acquire(ctx, 1); err != nil && err != ErrClosed {
+			var zero T
+			return zero, err
+		}
+	}
+	p.countGets(1)
+	x := p.get()
+	p.borrowed(x)
+	return x, nil
+}
+
+// Chan 返回一个在有对象可用时送出对象的 channel，以及一个停止获取的 cancel 函数，
+// 使获取对象可以作为 select 语句中的一个分支：
+//
+//	ch, cancel := p.Chan()
+//	defer cancel()
+//	select {
+//	case obj := <-ch:
+//		defer p.Put(obj)
+//		// 使用 obj
+//	case <-ctx.Done():
+//		return ctx.Err()
+//	}
+//
+// 对于有界池，借出的对象达到上限时 channel 会一直等待，直到有对象被放回；无界池会立即送出对象。
+// channel 最多送出一个对象，从中收到的对象与 Get 得到的一样算作借出，必须通过 Put 放回。
+//
+// 调用方不再需要对象时必须调用 cancel（重复调用是安全的）：它会停止等待，
+// 如果对象已经被送入 channel 但还没有被接收，cancel 会把它放回池中，因此放弃的获取不会泄漏额度。
+// cancel 返回后 channel 中不会再有对象。
+func (p *Pool[T]) Chan() (<-chan T, func()) {
+	ch := make(chan T, 1)
+	done := make(chan struct{})
+	ctx, stop := context.WithCancel(context.Background())
+	go func() {
+		defer close(done)
+		if x, err := p.GetContext(ctx); err == nil {
+			ch <- x
+		}
+	}()
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			stop()
+			<-done
+			select {
+			case x := <-ch:
+				p.Put(x)
+			default:
+			}
+		})
+	}
+}
+
+// Acquire 在有界池中预留一个借出额度而不取出对象，使准入控制可以先于对象的获取进行，
+// 例如在分配其他资源之前先确认请求可以被处理。它会一直等待到有额度为止。
+// 之后可以用 GetWithReservation 在这个额度下取出对象，或者调用 release 放弃预留、归还额度。
+//
+// release 是幂等的，只有第一次调用生效：通过 GetWithReservation 取出的对象被放回（或丢弃、分离）时，
+// 池会自动调用 release，因此调用方可以在 Acquire 之后直接 defer release()，不会重复归还额度。
+// 无界池不需要额度，Acquire 返回一个空操作的 release；池已关闭时返回 ErrClosed。
+func (p *Pool[T]) Acquire() (release func(), err error) {
+	if p.sem == nil {
+		return func() {}, nil
+	}
+	if err := p.sem.acquire(context.Background(), 1); err != nil {
+		return func() {}, err
+	}
+	var released int32
+	return func() {
+		if atomic.CompareAndSwapInt32(&released, 0, 1) {
+			p.sem.release(1)
+		}
+	}, nil
+}
+
+// GetWithReservation 在 Acquire 预留的额度下取出一个对象而不再等待额度，release 必须是 Acquire 返回的、尚未调用过的函数。
+// 对象放回池中时，额度通过 release 归还，调用方之后再调用 release 不起作用。
+//
+// 把额度关联到对象需要以对象本身作为标识；T 不是指针、map 或 channel 这类引用对象本身的类型时，
+// 相等的值不一定是同一个对象，GetWithReservation 会先调用 release 归还预留的额度，再像 Get 一样重新等待额度，借出数量的上限仍然成立。
+// 无界池上它等同于 Get。
+func (p *Pool[T]) GetWithReservation(release func()) T {
+	if p.reserved == nil {
+		if p.sem != nil {
+			release()
+		}
+		return p.Get()
+	}
+	p.awaitWarm()
+	p.countGets(1)
+	x := p.get()
+	p.reserved.add(x, release)
+	p.borrowed(x)
+	return x
+}
+
+// releaseSlot 归还借出的对象 x 占用的额度：x 是通过 GetWithReservation 借出的时调用它的 release，
+// 否则直接归还有界池的一个额度。
+func (p *Pool[T]) releaseSlot(x T) {
+	if !p.releaseReserved(x) && p.sem != nil {
+		p.sem.release(1)
+	}
+}
+
+// releaseReserved 在 x 是通过 GetWithReservation 借出的对象时调用它的 release 并返回 true。
+func (p *Pool[T]) releaseReserved(x T) bool {
+	if p.reserved == nil {
+		return false
+	}
+	release := p.reserved.remove(x)
+	if release == nil {
+		return false
+	}
+	release()
+	return true
+}
+
+// reservedSet 记录通过 GetWithReservation 借出的对象及其额度的 release 函数。
+type reservedSet struct {
+	n  int32 // 集合中的对象数量，为 0 时 remove 不必加锁，使用原子操作访问
+	mu sync.Mutex
+	m  map[any]func()
+}
+
+func (r *reservedSet) add(x any, release func()) {
+	r.mu.Lock()
+	if r.m == nil {
+		r.m = make(map[any]func())
+	}
+	if _, ok := r.m[x]; !ok {
+		atomic.AddInt32(&r.n, 1)
+	}
+	r.m[x] = release
+	r.mu.Unlock()
+}
+
+// remove 从集合中移除 x 并返回它的 release 函数，x 不在集合中时返回 nil。
+func (r *reservedSet) remove(x any) func() {
+	if atomic.LoadInt32(&r.n) == 0 {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	release, ok := r.m[x]
+	if !ok {
+		return nil
+	}
+	delete(r.m, x)
+	atomic.AddInt32(&r.n, -1)
+	return release
+}
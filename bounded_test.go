@@ -0,0 +1,455 @@
+package gpool
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestPool_Bounded_GetBlocks 测试有界池在借出对象达到上限时 Get 会阻塞，直到有对象被放回。
+func TestPool_Bounded_GetBlocks(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithMax[*bytes.Buffer](1))
+
+	buf := p.Get()
+
+	got := make(chan *bytes.Buffer)
+	go func() {
+		got <- p.Get()
+	}()
+
+	select {
+	case <-got:
+		t.Fatal("借出对象达到上限时 Get() 应该阻塞")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	p.Put(buf)
+	select {
+	case <-got:
+	case <-time.After(time.Second):
+		t.Fatal("有对象被放回后, 阻塞的 Get() 应该返回")
+	}
+}
+
+// TestPool_GetContext 测试有界池的 GetContext 等待到有对象被放回，或者在 ctx 结束时返回对应的错误。
+func TestPool_GetContext(t *testing.T) {
+	var created int32
+	p := New(func() *bytes.Buffer {
+		atomic.AddInt32(&created, 1)
+		return new(bytes.Buffer)
+	}, WithMax[*bytes.Buffer](1), WithDeterministic[*bytes.Buffer]())
+
+	buf, err := p.GetContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := p.GetContext(ctx); !errors.Is(err, ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("期望超时错误, 得到 %v", err)
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if _, err := p.GetContext(ctx); !errors.Is(err, ErrCancelled) {
+		t.Fatalf("期望取消错误, 得到 %v", err)
+	}
+	if n := atomic.LoadInt32(&created); n != 1 {
+		t.Fatalf("等待额度时不应该创建新对象, 创建了 %d 个", n)
+	}
+
+	got := make(chan *bytes.Buffer)
+	go func() {
+		x, err := p.GetContext(context.Background())
+		if err != nil {
+			t.Error(err)
+		}
+		got <- x
+	}()
+	p.Put(buf)
+	select {
+	case x := <-got:
+		if x != buf {
+			t.Fatal("期望复用被放回的对象")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("有对象被放回后, 等待中的 GetContext 应该返回")
+	}
+	if s := p.Stats(); s.Gets != 2 || s.Outstanding != 1 {
+		t.Fatalf("失败的 GetContext 不应该计入借出, 得到 %+v", s)
+	}
+}
+
+// TestPool_Acquire 测试预留的额度被 GetWithReservation 取出的对象继承，对象放回时额度恰好被归还一次。
+func TestPool_Acquire(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer](), WithMax[*bytes.Buffer](2))
+	inUse := func() int64 {
+		p.sem.mu.Lock()
+		defer p.sem.mu.Unlock()
+		return p.sem.cur
+	}
+
+	held := p.Get()
+	release, err := p.Acquire()
+	if err != nil {
+		t.Fatalf("Acquire 返回了错误: %v", err)
+	}
+	if _, err := p.TryGetAll(1); err != ErrExhausted {
+		t.Fatalf("预留的额度应该被占用, 期望 ErrExhausted, 得到 %v", err)
+	}
+
+	x := p.GetWithReservation(release)
+	if got := inUse(); got != 2 {
+		t.Fatalf("GetWithReservation 不应该再占用额度, 期望占用 2 个, 得到 %d", got)
+	}
+	p.Put(x)
+	if got := inUse(); got != 1 {
+		t.Fatalf("放回对象时应该归还预留的额度, 期望占用 1 个, 得到 %d", got)
+	}
+	release() // 额度已经随对象归还
+	if got := inUse(); got != 1 {
+		t.Fatalf("release 不应该重复归还额度, 期望占用 1 个, 得到 %d", got)
+	}
+	p.Put(held)
+
+	// 放弃预留时只归还一次。
+	held = p.Get()
+	release, _ = p.Acquire()
+	release()
+	release()
+	if got := inUse(); got != 1 {
+		t.Errorf("放弃预留后期望占用 1 个额度, 得到 %d", got)
+	}
+	p.Put(held)
+
+	if s := p.Stats(); s.Outstanding != 0 || s.Gets != 3 {
+		t.Errorf("统计不符合预期: %+v", s)
+	}
+	p.Close()
+	if _, err := p.Acquire(); err != ErrClosed {
+		t.Errorf("池关闭后期望 ErrClosed, 得到 %v", err)
+	}
+}
+
+// TestPool_Acquire_Value 测试值类型的池上两个相等的值各自借出时，每个预留的额度都恰好被归还一次。
+func TestPool_Acquire_Value(t *testing.T) {
+	p := NewValue(func() int { return 0 }, WithMax[int](2))
+	inUse := func() int64 {
+		p.sem.mu.Lock()
+		defer p.sem.mu.Unlock()
+		return p.sem.cur
+	}
+
+	r1, _ := p.Acquire()
+	r2, _ := p.Acquire()
+	x, y := p.GetWithReservation(r1), p.GetWithReservation(r2)
+	if got := inUse(); got != 2 {
+		t.Fatalf("期望占用 2 个额度, 得到 %d", got)
+	}
+	p.Put(x)
+	p.Put(y)
+	if got := inUse(); got != 0 {
+		t.Fatalf("放回两个对象后期望额度全部归还, 得到 %d", got)
+	}
+
+	// 额度已经随对象归还，之后调用 release 不应该归还别人占用的额度。
+	p.Get()
+	r1()
+	r2()
+	if got := inUse(); got != 1 {
+		t.Fatalf("release 不应该重复归还额度, 期望占用 1 个, 得到 %d", got)
+	}
+	p.Get()
+	if _, ok := p.TryGet(); ok {
+		t.Error("占满额度后 TryGet 不应该成功")
+	}
+}
+
+// TestPool_Acquire_Unbounded 测试无界池的 Acquire 不需要额度，GetWithReservation 等同于 Get。
+func TestPool_Acquire_Unbounded(t *testing.T) {
+	p := New(func() *bytes.Buffer { return new(bytes.Buffer) })
+	release, err := p.Acquire()
+	if err != nil {
+		t.Fatalf("Acquire 返回了错误: %v", err)
+	}
+	defer release()
+	if x := p.GetWithReservation(release); x == nil {
+		t.Fatal("期望取出一个对象")
+	}
+}
+
+// TestSemaphore_Acquire_Cancel 测试等待中的请求在 ctx 取消后返回错误，且不会占用额度。
+func TestSemaphore_Acquire_Cancel(t *testing.T) {
+	s := newSemaphore(2)
+	if !s.tryAcquire(2) {
+		t.Fatal("空闲的信号量应该可以获得全部许可")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := s.acquire(ctx, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("期望得到 context.DeadlineExceeded, 得到 %v", err)
+	}
+
+	s.release(2)
+	if !s.tryAcquire(2) {
+		t.Fatal("被取消的请求不应该占用额度")
+	}
+}
+
+// TestSemaphore_FIFO 测试排在队首的大请求不会被后来的小请求越过。
+func TestSemaphore_FIFO(t *testing.T) {
+	s := newSemaphore(2)
+	s.tryAcquire(2)
+
+	done := make(chan struct{})
+	go func() {
+		_ = s.acquire(context.Background(), 2)
+		close(done)
+	}()
+	// 等待大请求进入队列。
+	for {
+		s.mu.Lock()
+		n := s.waiters.Len()
+		s.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	s.release(1)
+	if s.tryAcquire(1) {
+		t.Fatal("有等待者时, 小请求不应该越过队首的大请求")
+	}
+	s.release(1)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("额度足够后, 队首的大请求应该被满足")
+	}
+}
+
+// TestSemaphore_ExceedsMax 测试超过容量的请求会立即失败而不是永远阻塞。
+func TestSemaphore_ExceedsMax(t *testing.T) {
+	s := newSemaphore(1)
+	if err := s.acquire(context.Background(), 2); !errors.Is(err, errExceedsMax) {
+		t.Fatalf("期望得到 errExceedsMax, 得到 %v", err)
+	}
+}
+
+// TestPool_SetMax_Grow 测试调高上限会立即放行等待中的 Get。
+func TestPool_SetMax_Grow(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithMax[*bytes.Buffer](1))
+	p.Get()
+
+	got := make(chan *bytes.Buffer)
+	go func() {
+		got <- p.Get()
+	}()
+	select {
+	case <-got:
+		t.Fatal("达到上限时 Get() 应该阻塞")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	p.SetMax(2)
+	select {
+	case <-got:
+	case <-time.After(time.Second):
+		t.Fatal("调高上限后, 等待中的 Get() 应该返回")
+	}
+}
+
+// TestPool_SetMax_Shrink 测试调低上限后，借出数量降到新上限以下之前新的 Get 都会等待。
+func TestPool_SetMax_Shrink(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithMax[*bytes.Buffer](3))
+	held := p.GetAll(3)
+	p.SetMax(1)
+
+	got := make(chan *bytes.Buffer)
+	go func() {
+		got <- p.Get()
+	}()
+
+	for i, buf := range held {
+		select {
+		case <-got:
+			t.Fatalf("只放回了 %d 个对象时, 借出数量仍不低于新上限, Get() 不应该返回", i)
+		case <-time.After(20 * time.Millisecond):
+		}
+		p.Put(buf)
+	}
+	select {
+	case <-got:
+	case <-time.After(time.Second):
+		t.Fatal("借出数量降到新上限以下后, Get() 应该返回")
+	}
+}
+
+// TestPool_SetMax_Concurrent 测试在并发使用中反复调整上限不会死锁，缩容生效后也不会超额借出。
+func TestPool_SetMax_Concurrent(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithMax[*bytes.Buffer](6))
+
+	var outstanding, peak int64
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				buf := p.Get()
+				n := atomic.AddInt64(&outstanding, 1)
+				for {
+					old := atomic.LoadInt64(&peak)
+					if n <= old || atomic.CompareAndSwapInt64(&peak, old, n) {
+						break
+					}
+				}
+				time.Sleep(100 * time.Microsecond)
+				atomic.AddInt64(&outstanding, -1)
+				p.Put(buf)
+			}
+		}()
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	p.SetMax(2)
+	// 等待缩容前借出的对象全部归还后再开始统计峰值。
+	time.Sleep(20 * time.Millisecond)
+	atomic.StoreInt64(&peak, 0)
+	time.Sleep(50 * time.Millisecond)
+	if n := atomic.LoadInt64(&peak); n > 2 {
+		t.Errorf("缩容生效后同时借出的对象不应该超过 2 个, 实际峰值为 %d", n)
+	}
+
+	p.SetMax(5)
+	time.Sleep(20 * time.Millisecond)
+	close(stop)
+	wg.Wait()
+}
+
+// TestPool_SetMax_FailsOversizedWaiter 测试缩容后请求数量超过新上限的等待者会失败而不是永远阻塞。
+func TestPool_SetMax_FailsOversizedWaiter(t *testing.T) {
+	s := newSemaphore(2)
+	s.tryAcquire(1)
+
+	errc := make(chan error)
+	go func() {
+		errc <- s.acquire(context.Background(), 2)
+	}()
+	for {
+		s.mu.Lock()
+		n := s.waiters.Len()
+		s.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	s.resize(1)
+	select {
+	case err := <-errc:
+		if !errors.Is(err, errExceedsMax) {
+			t.Fatalf("期望得到 errExceedsMax, 得到 %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("请求数量超过新容量的等待者应该立即失败")
+	}
+}
+
+// TestPool_SetMax_Unbounded 测试对无界池调用 SetMax 会 panic。
+func TestPool_SetMax_Unbounded(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	})
+	defer func() {
+		if recover() == nil {
+			t.Fatal("对无界池调用 SetMax 应该 panic")
+		}
+	}()
+	p.SetMax(1)
+}
+
+// TestPool_Chan 测试通过 select 从 Chan 获取对象，以及取消等待时不会泄漏额度。
+func TestPool_Chan(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer](), WithMax[*bytes.Buffer](1))
+
+	held := p.Get()
+
+	// 借出数量已达上限，channel 在 ctx 超时前不会送出对象。
+	ch, cancel := p.Chan()
+	ctx, stop := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer stop()
+	select {
+	case <-ch:
+		t.Fatal("借出数量达到上限时不应送出对象")
+	case <-ctx.Done():
+	}
+	cancel()
+	cancel()
+
+	// 放回对象后，新的 channel 送出被放回的对象。
+	p.Put(held)
+	ch, cancel = p.Chan()
+	defer cancel()
+	select {
+	case x := <-ch:
+		if x != held {
+			t.Fatal("期望送出被放回的对象")
+		}
+		if s := p.Stats(); s.Outstanding != 1 {
+			t.Fatalf("从 channel 收到的对象应算作借出, 得到 %+v", s)
+		}
+		p.Put(x)
+	case <-time.After(time.Second):
+		t.Fatal("有可用额度时 channel 应该送出对象")
+	}
+	if _, err := p.TryGetAll(1); err != nil {
+		t.Fatalf("取消的获取不应泄漏额度, 得到 %v", err)
+	}
+}
+
+// TestPool_Chan_Unreceived 测试对象已被送入 channel 但未被接收时，cancel 会将它放回池中。
+func TestPool_Chan_Unreceived(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer](), WithMax[*bytes.Buffer](1))
+
+	_, cancel := p.Chan()
+	// 等待对象被送入 channel。
+	for p.Stats().Gets == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+
+	if s := p.Stats(); s.Outstanding != 0 || s.Idle != 1 {
+		t.Fatalf("期望未被接收的对象被放回池中, 得到 %+v", s)
+	}
+	if _, err := p.TryGetAll(1); err != nil {
+		t.Fatalf("未被接收的对象不应占用额度, 得到 %v", err)
+	}
+}
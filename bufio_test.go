@@ -0,0 +1,68 @@
+package gpool
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// failingWriter 是总是写入失败的 io.Writer。
+type failingWriter struct{ err error }
+
+func (w failingWriter) Write([]byte) (int, error) { return 0, w.err }
+
+// TestBufioWriterPool 测试复用的 bufio.Writer 在两个不同的目标之间切换时，数据都被刷新到正确的目标。
+func TestBufioWriterPool(t *testing.T) {
+	p := NewBufioWriterPool(64, WithDeterministic[*bufio.Writer]())
+
+	var a, b bytes.Buffer
+	bw := p.Get(&a)
+	bw.WriteString("to a")
+	if a.Len() != 0 {
+		t.Fatal("Put 之前数据应该仍在缓冲区中")
+	}
+	if err := p.PutE(bw); err != nil {
+		t.Fatal(err)
+	}
+	if a.String() != "to a" {
+		t.Fatalf("Put 应该把数据刷新到 a, 得到 %q", a.String())
+	}
+
+	bw2 := p.Get(&b)
+	if bw2 != bw {
+		t.Fatal("期望复用同一个 bufio.Writer")
+	}
+	if bw2.Buffered() != 0 || bw2.Size() != 64 {
+		t.Fatalf("复用的 bufio.Writer 应该没有残留的数据, 缓冲了 %d 字节", bw2.Buffered())
+	}
+	bw2.WriteString("to b")
+	p.Put(bw2)
+	if a.String() != "to a" || b.String() != "to b" {
+		t.Fatalf("数据不应该写入其他目标, a=%q b=%q", a.String(), b.String())
+	}
+	if s := p.Stats(); s.Gets != 2 || s.Hits != 1 || s.Idle != 1 {
+		t.Fatalf("统计信息不正确: %+v", s)
+	}
+}
+
+// TestBufioWriterPool_FlushError 测试刷新失败的 bufio.Writer 被丢弃，PutE 返回包含刷新错误的 *PoolError。
+func TestBufioWriterPool_FlushError(t *testing.T) {
+	p := NewBufioWriterPool(64, WithDeterministic[*bufio.Writer]())
+
+	errWrite := errors.New("write failed")
+	bw := p.Get(failingWriter{err: errWrite})
+	bw.WriteString("lost")
+	err := p.PutE(bw)
+	if !errors.Is(err, errWrite) || !errors.Is(err, &PoolError{Kind: KindRejected, Reason: DiscardInvalid}) {
+		t.Fatalf("期望包含刷新错误的 KindRejected 错误, 得到 %v", err)
+	}
+	if s := p.Stats(); s.Idle != 0 || s.Outstanding != 0 || s.DiscardsByReason[DiscardInvalid] != 1 {
+		t.Fatalf("刷新失败的 bufio.Writer 应该被丢弃, 得到 %+v", s)
+	}
+
+	var buf bytes.Buffer
+	if bw2 := p.Get(&buf); bw2 == bw {
+		t.Fatal("被丢弃的 bufio.Writer 不应该被复用")
+	}
+}
@@ -0,0 +1,100 @@
+package gpool
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// churnSampleEvery 表示每多少次借出采样一次持有时间。
+	churnSampleEvery = 4
+	// churnSamples 是计算中位数所用的样本数，同时也限制了同时跟踪的对象数量。
+	churnSamples = 32
+)
+
+// churnDetector 采样对象从 Get 到 Put 的持有时间，
+// 如果持有时间的中位数低于阈值，就通过 Logger 输出一次提示：池化可能得不偿失。
+type churnDetector struct {
+	threshold time.Duration
+	report    func(median time.Duration)
+
+	gets    int64 // 借出次数，用于决定采样哪些对象，使用原子操作访问
+	done    int32 // 是否已经输出过提示，使用原子操作访问
+	mu      sync.Mutex
+	pending map[any]time.Time // 被采样对象的借出时间
+	samples []time.Duration
+}
+
+// newChurnDetector 为类型 T 创建一个 churnDetector。
+// 检测器需要以对象本身作为键来关联 Get 和 Put，因此 T 不可比较（或是接口类型）时返回 nil。
+func newChurnDetector[T any](threshold time.Duration, report func(median time.Duration)) *churnDetector {
+	t := typeOf[T]()
+	if t.Kind() == reflect.Interface || !t.Comparable() {
+		return nil
+	}
+	return &churnDetector{
+		threshold: threshold,
+		report:    report,
+		pending:   make(map[any]time.Time),
+	}
+}
+
+// borrowed 在对象被借出时调用，按采样率记录它的借出时间。
+func (d *churnDetector) borrowed(x any) {
+	if atomic.LoadInt32(&d.done) != 0 || atomic.AddInt64(&d.gets, 1)%churnSampleEvery != 0 {
+		return
+	}
+	now := time.Now()
+	d.mu.Lock()
+	// 在检查 done 和加锁之间，提示可能已经输出、pending 已被清空，需要在锁内再检查一次。
+	if d.pending != nil && len(d.pending) < churnSamples {
+		d.pending[x] = now
+	}
+	d.mu.Unlock()
+}
+
+// forget 停止跟踪对象 x，用于不会再被放回的对象。
+func (d *churnDetector) forget(x any) {
+	d.mu.Lock()
+	delete(d.pending, x)
+	d.mu.Unlock()
+}
+
+// returned 在对象被放回时调用，如果它被采样过就记录持有时间。
+// 攒够一批样本后计算中位数，低于阈值时输出提示并停止检测，否则开始下一批采样。
+func (d *churnDetector) returned(x any) {
+	if atomic.LoadInt32(&d.done) != 0 {
+		return
+	}
+	d.mu.Lock()
+	start, ok := d.pending[x]
+	if !ok {
+		d.mu.Unlock()
+		return
+	}
+	delete(d.pending, x)
+	d.samples = append(d.samples, time.Since(start))
+	if len(d.samples) < churnSamples {
+		d.mu.Unlock()
+		return
+	}
+	median := medianDuration(d.samples)
+	d.samples = d.samples[:0]
+	fire := median < d.threshold && atomic.CompareAndSwapInt32(&d.done, 0, 1)
+	if fire {
+		d.pending = nil
+	}
+	d.mu.Unlock()
+	if fire {
+		d.report(median)
+	}
+}
+
+// medianDuration 返回 ds 的中位数，它会对 ds 原地排序。
+func medianDuration(ds []time.Duration) time.Duration {
+	sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+	return ds[len(ds)/2]
+}
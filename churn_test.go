@@ -0,0 +1,102 @@
+package gpool
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordLogger 是一个记录所有输出的 Logger。
+type recordLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *recordLogger) Printf(format string, v ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+func (l *recordLogger) Lines() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.lines...)
+}
+
+// TestPool_ChurnDetector 测试对象持有时间极短时，检测器只输出一次提示。
+func TestPool_ChurnDetector(t *testing.T) {
+	log := new(recordLogger)
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithChurnDetector[*bytes.Buffer](time.Second), WithLogger[*bytes.Buffer](log))
+
+	for i := 0; i < 10*churnSampleEvery*churnSamples; i++ {
+		p.Put(p.Get())
+	}
+
+	lines := log.Lines()
+	if len(lines) != 1 {
+		t.Fatalf("期望只输出 1 条提示, 得到 %d 条: %q", len(lines), lines)
+	}
+	if !strings.Contains(lines[0], "*bytes.Buffer") || !strings.Contains(lines[0], "not be worthwhile") {
+		t.Errorf("提示中应包含类型名和建议, 得到 %q", lines[0])
+	}
+}
+
+// TestPool_ChurnDetector_LongHolds 测试对象持有时间超过阈值时不输出提示。
+func TestPool_ChurnDetector_LongHolds(t *testing.T) {
+	log := new(recordLogger)
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithChurnDetector[*bytes.Buffer](10*time.Microsecond), WithLogger[*bytes.Buffer](log))
+
+	for i := 0; i < 2*churnSampleEvery*churnSamples; i++ {
+		x := p.Get()
+		time.Sleep(100 * time.Microsecond)
+		p.Put(x)
+	}
+
+	if lines := log.Lines(); len(lines) != 0 {
+		t.Fatalf("期望不输出提示, 得到 %q", lines)
+	}
+}
+
+// TestPool_ChurnDetector_NotComparable 测试不可比较的类型不会启用检测器。
+func TestPool_ChurnDetector_NotComparable(t *testing.T) {
+	p := New(func() []byte {
+		return make([]byte, 8)
+	}, WithChurnDetector[[]byte](time.Second))
+
+	if p.churn != nil {
+		t.Fatal("不可比较的类型不应启用检测器")
+	}
+	p.Put(p.Get())
+}
+
+// TestPool_ChurnDetector_Concurrent 测试多个 goroutine 并发 Get/Put 时，检测器输出提示前后都不会出错，且只输出一次提示。
+func TestPool_ChurnDetector_Concurrent(t *testing.T) {
+	log := new(recordLogger)
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithChurnDetector[*bytes.Buffer](time.Second), WithLogger[*bytes.Buffer](log))
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 10*churnSampleEvery*churnSamples; i++ {
+				p.Put(p.Get())
+			}
+		}()
+	}
+	wg.Wait()
+
+	if lines := log.Lines(); len(lines) != 1 {
+		t.Fatalf("期望只输出 1 条提示, 得到 %d 条: %q", len(lines), lines)
+	}
+}
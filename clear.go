@@ -0,0 +1,150 @@
+package gpool
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ClearReset 返回一个适用于切片和 map 类型 T 的重置函数，可以传给 WithRecycle 使用：
+// 对切片，它把底层数组中直到容量为止的所有元素置为零值，然后返回长度为 0 的切片；
+// 对 map，它删除所有键并返回同一个 map。
+//
+// 只把切片的长度截断为 0 是不够的：之后通过 s[:cap(s)] 等方式重新扩展切片时，上一个使用者留下的元素仍然可见，
+// 元素中的指针也会使它们引用的数据无法被回收。ClearReset 清理整个底层数组，避免这类数据泄漏。
+//
+// T 不是切片或 map 类型时 ClearReset 会 panic。它通过反射实现以适用于任意切片和 map 类型；
+// NewSlicePool 和 NewMapPool 内部使用不经反射的等价实现。
+func ClearReset[T any]() func(T) T {
+	switch k := typeOf[T]().Kind(); k {
+	case reflect.Slice:
+		return func(x T) T {
+			v := reflect.ValueOf(&x).Elem()
+			v.SetLen(v.Cap())
+			v.Clear()
+			v.SetLen(0)
+			return x
+		}
+	case reflect.Map:
+		return func(x T) T {
+			reflect.ValueOf(x).Clear()
+			return x
+		}
+	default:
+		panic(fmt.Sprintf("gpool: ClearReset requires a slice or map type, got %s", typeOf[T]()))
+	}
+}
+
+// ZeroReset 返回一个把 *T 指向的值整体置为零值（*p = T{}）的重置函数，可以传给 WithResetFields 使用：
+//
+//	gpool.New(newRequest, gpool.WithResetFields(gpool.ZeroReset[Request]()))
+//
+// 它不使用反射，是结构体指针池最快的完整重置方式，适合没有手写重置函数的结构体。
+// 注意它会丢弃对象内部已分配的容量，例如切片和 map 字段会变为 nil，
+// 因此不适合希望在多次使用之间复用这些缓冲区的结构体，这类结构体应改用按字段声明的 WithResetFields。
+func ZeroReset[T any]() func(*T) {
+	return func(p *T) {
+		var zero T
+		*p = zero
+	}
+}
+
+// WithZeroOnGet 让 Get 在交出复用的闲置对象之前把它清零，保证调用方不会看到上一个使用者留下的状态，
+// 以性能为代价换取安全，而不必依赖每个调用方在 Put 之前自行重置对象：
+//
+//   - 指针指向的值被置为零值，例如 *T 的所有字段都被清零。
+//   - 切片直到长度为止的元素被置为零值，例如 []byte 的内容被清零；长度和容量保持不变。
+//   - map 的所有键被删除。
+//   - 其他值类型本身被置为零值。
+//
+// 清零发生在 WithValidator 的校验之后；由 newFunc 新建的对象不会被清零。需要自定义清理逻辑时，
+// 可以改用 NewRecyclable，在 Prepare 中准备对象。
+// 由于 sync.Pool 无法区分取回的对象是闲置的还是刚由 New 创建的，未指定其他后端时会改用确定性后端。
+func WithZeroOnGet[T any]() Option[T] {
+	return func(c *config[T]) {
+		c.zeroOnGet = true
+		c.needStore = true
+	}
+}
+
+// zeroObject 按 WithZeroOnGet 的规则清零 x 并返回清零后的对象。
+func zeroObject[T any](x T) T {
+	if b, ok := any(x).([]byte); ok {
+		clear(b)
+		return x
+	}
+	v := reflect.ValueOf(any(x))
+	switch v.Kind() {
+	case reflect.Invalid:
+		return x
+	case reflect.Pointer:
+		if !v.IsNil() {
+			v.Elem().SetZero()
+		}
+		return x
+	case reflect.Slice, reflect.Map:
+		v.Clear()
+		return x
+	}
+	var zero T
+	return zero
+}
+
+// clearSlice 把 s 的底层数组中直到容量为止的元素置为零值，并返回长度为 0 的切片。
+func clearSlice[E any](s []E) []E {
+	clear(s[:cap(s)])
+	return s[:0]
+}
+
+// clearMap 删除 m 中的所有键并返回 m。
+func clearMap[K comparable, V any](m map[K]V) map[K]V {
+	clear(m)
+	return m
+}
+
+// NewSlicePool 创建一个保存容量为 capacity 的 []E 切片的 Pool：Put 时切片的整个底层数组都会被清零，
+// 长度被截断为 0，因此 Get 取回的总是空切片，也不会通过重新扩展看到之前的元素。
+//
+// 切片头是值类型，为了避免存入 sync.Pool 时的装箱分配，池默认使用确定性后端（同 NewValue）。
+// opts 中的后端选项可以覆盖这一默认设置；opts 中的 WithRecycle 会替换默认的清理逻辑。
+func NewSlicePool[E any](capacity int, opts ...Option[[]E]) *Pool[[]E] {
+	base := []Option[[]E]{WithDeterministic[[]E](), WithRecycle(clearSlice[E])}
+	return New(func() []E {
+		return make([]E, 0, capacity)
+	}, append(base, opts...)...)
+}
+
+// NewMapPool 创建一个保存 map[K]V 的 Pool：Put 时 map 中的所有键都会被删除，Get 取回的总是空的 map。
+// 被清空的 map 保留已分配的哈希桶，适合反复构建规模相近的 map 的场景。
+// opts 中的 WithRecycle 会替换默认的清理逻辑。
+func NewMapPool[K comparable, V any](opts ...Option[map[K]V]) *Pool[map[K]V] {
+	return New(func() map[K]V {
+		return make(map[K]V)
+	}, append([]Option[map[K]V]{WithRecycle(clearMap[K, V])}, opts...)...)
+}
+
+// NewSlicePtrPool 创建一个保存 *[]E 的 Pool，新建的切片容量为 capacity。
+// 与 NewSlicePool 不同，池中保存的是指向切片头的指针：指针可以直接存入默认的 sync.Pool 后端而无需装箱，
+// 在 Get 和 Put 之间也只传递一个指针而不是 24 字节的切片头，这是池化大块 []byte 时常见的优化。
+//
+// Put 时切片的长度通过指针被截断为 0，Get 取回的总是空切片，底层数组会被复用；
+// 为了保持开销低，底层数组的内容不会被清零，重新扩展切片时可能看到之前的元素，
+// 需要保证这一点时应当改用 NewSlicePool。append 把切片扩容后，应通过指针写回（*p = append(*p, ...)），
+// 使池保留扩容后的底层数组。
+//
+// maxCap 大于 0 时，Put 会以 DiscardOversized 为原因丢弃容量超过 maxCap 的切片，
+// 避免个别超大的切片长期占用内存。opts 中的 WithRecycle 会替换默认的截断逻辑。
+func NewSlicePtrPool[E any](capacity, maxCap int, opts ...Option[*[]E]) *Pool[*[]E] {
+	base := []Option[*[]E]{WithRecycle(func(s *[]E) *[]E {
+		*s = (*s)[:0]
+		return s
+	})}
+	if maxCap > 0 {
+		base = append(base, func(c *config[*[]E]) {
+			c.oversized = func(s *[]E) bool { return cap(*s) > maxCap }
+		})
+	}
+	return NewPointer(func() *[]E {
+		s := make([]E, 0, capacity)
+		return &s
+	}, append(base, opts...)...)
+}
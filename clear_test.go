@@ -0,0 +1,200 @@
+package gpool
+
+import "testing"
+
+// TestNewSlicePool 测试放回的切片的整个底层数组都被清零，重新扩展取回的切片也看不到之前的元素。
+func TestNewSlicePool(t *testing.T) {
+	p := NewSlicePool[*int](4)
+
+	s := p.Get()
+	if len(s) != 0 || cap(s) != 4 {
+		t.Fatalf("期望长度 0、容量 4 的切片, 得到 %d 和 %d", len(s), cap(s))
+	}
+	v := 42
+	s = append(s, &v, &v, &v)
+	s = s[:1] // 截断后放回，底层数组中仍有第 2、3 个元素
+	p.Put(s)
+
+	got := p.Get()
+	if len(got) != 0 {
+		t.Errorf("取回的切片长度应该为 0, 得到 %d", len(got))
+	}
+	for i, e := range got[:cap(got)] {
+		if e != nil {
+			t.Errorf("底层数组的第 %d 个元素应该被清零", i)
+		}
+	}
+}
+
+// TestNewSlicePtrPool 测试 Put 通过指针把切片长度截断为 0，之后取回的是同一个底层数组，
+// 容量超过 maxCap 的切片被丢弃。
+func TestNewSlicePtrPool(t *testing.T) {
+	p := NewSlicePtrPool[byte](8, 64, WithDeterministic[*[]byte]())
+
+	b := p.Get()
+	if len(*b) != 0 || cap(*b) != 8 {
+		t.Fatalf("期望长度 0、容量 8 的切片, 得到 %d 和 %d", len(*b), cap(*b))
+	}
+	*b = append(*b, "hello"...)
+	data := &(*b)[0]
+	p.Put(b)
+
+	got := p.Get()
+	if got != b {
+		t.Fatal("期望取回同一个指针")
+	}
+	if len(*got) != 0 {
+		t.Errorf("取回的切片长度应该为 0, 得到 %d", len(*got))
+	}
+	if &(*got)[:1][0] != data {
+		t.Error("期望复用同一个底层数组")
+	}
+
+	*got = append(*got, make([]byte, 100)...) // 扩容后容量超过 maxCap
+	p.Put(got)
+	if s := p.Stats(); s.Idle != 0 || s.DiscardsByReason[DiscardOversized] != 1 {
+		t.Errorf("容量超过 maxCap 的切片应该被丢弃: %+v", s)
+	}
+}
+
+// BenchmarkSlicePtrPool 对比通过指针池化切片与直接池化切片的开销。
+func BenchmarkSlicePtrPool(b *testing.B) {
+	const size = 4096
+	b.Run("SlicePool", func(b *testing.B) {
+		p := NewSlicePool[byte](size)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			s := p.Get()
+			s = append(s, 'x')
+			p.Put(s)
+		}
+	})
+	b.Run("SlicePtrPool", func(b *testing.B) {
+		p := NewSlicePtrPool[byte](size, 0)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			s := p.Get()
+			*s = append(*s, 'x')
+			p.Put(s)
+		}
+	})
+}
+
+// TestNewMapPool 测试放回的 map 被清空后复用。
+func TestNewMapPool(t *testing.T) {
+	p := NewMapPool[string, int](WithDeterministic[map[string]int]())
+
+	m := p.Get()
+	m["a"], m["b"] = 1, 2
+	p.Put(m)
+
+	got := p.Get()
+	if len(got) != 0 {
+		t.Errorf("取回的 map 应该为空, 得到 %v", got)
+	}
+	got["c"] = 3
+	if len(m) != 1 {
+		t.Error("期望复用同一个 map")
+	}
+}
+
+// TestClearReset 测试 ClearReset 对切片清零直到容量为止的元素、对 map 删除所有键，并在其他类型上 panic。
+func TestClearReset(t *testing.T) {
+	s := []int{1, 2, 3, 4}[:2]
+	s = ClearReset[[]int]()(s)
+	if len(s) != 0 {
+		t.Errorf("切片长度应该为 0, 得到 %d", len(s))
+	}
+	for i, e := range s[:cap(s)] {
+		if e != 0 {
+			t.Errorf("第 %d 个元素应该被清零, 得到 %d", i, e)
+		}
+	}
+
+	m := map[int]string{1: "a", 2: "b"}
+	if got := ClearReset[map[int]string]()(m); len(got) != 0 || len(m) != 0 {
+		t.Errorf("map 应该被原地清空, 得到 %v", got)
+	}
+
+	p := New(func() []byte { return make([]byte, 0, 8) },
+		WithDeterministic[[]byte](), WithRecycle(ClearReset[[]byte]()))
+	p.Put(append(p.Get(), "secret"...))
+	if b := p.Get(); string(b[:cap(b)]) != string(make([]byte, 8)) {
+		t.Errorf("通过 WithRecycle 使用时也应该清空底层数组, 得到 %q", b[:cap(b)])
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("T 不是切片或 map 时 ClearReset 应该 panic")
+		}
+	}()
+	ClearReset[int]()
+}
+
+// dirty 是用于检查 WithZeroOnGet 的测试对象。
+type dirty struct {
+	name  string
+	count int
+	tags  []string
+}
+
+// TestPool_ZeroOnGet 测试启用 WithZeroOnGet 时，脏的对象被放回后，下一次 Get 取回的是清零后的对象。
+func TestPool_ZeroOnGet(t *testing.T) {
+	p := New(func() *dirty {
+		return &dirty{name: "fresh"}
+	}, WithZeroOnGet[*dirty]())
+
+	if d := p.Get(); d.name != "fresh" {
+		t.Error("新建的对象不应被清零")
+	}
+	d := &dirty{name: "secret", count: 3, tags: []string{"x"}}
+	p.Put(d)
+	if got := p.Get(); got != d || got.name != "" || got.count != 0 || got.tags != nil {
+		t.Errorf("复用的对象应该被清零, 得到 %+v", got)
+	}
+	if c := p.Config(); !c.ZeroOnGet || c.Backend != BackendDeterministic {
+		t.Errorf("WithZeroOnGet 应该改用确定性后端: %+v", c)
+	}
+
+	bp := New(func() []byte { return make([]byte, 4) }, WithZeroOnGet[[]byte]())
+	bp.Put([]byte("pass"))
+	if b := bp.Get(); string(b) != "\x00\x00\x00\x00" {
+		t.Errorf("[]byte 的内容应该被清零, 得到 %q", b)
+	}
+
+	mp := New(func() map[string]int { return map[string]int{} }, WithZeroOnGet[map[string]int]())
+	mp.Put(map[string]int{"a": 1})
+	if m := mp.Get(); len(m) != 0 {
+		t.Errorf("map 应该被清空, 得到 %v", m)
+	}
+}
+
+// TestZeroReset 测试通过 WithResetFields 使用 ZeroReset 时，Put 之后结构体的所有字段都被清零。
+func TestZeroReset(t *testing.T) {
+	type record struct {
+		ID     int
+		Name   string
+		Tags   []string
+		Attrs  map[string]int
+		Parent *record
+	}
+	p := New(func() *record {
+		return new(record)
+	}, WithDeterministic[*record](), WithResetFields(ZeroReset[record]()))
+
+	r := p.Get()
+	r.ID = 42
+	r.Name = "a"
+	r.Tags = append(r.Tags, "x")
+	r.Attrs = map[string]int{"k": 1}
+	r.Parent = new(record)
+	p.Put(r)
+
+	got := p.Get()
+	if got != r {
+		t.Fatal("期望复用放回的对象")
+	}
+	if got.ID != 0 || got.Name != "" || got.Tags != nil || got.Attrs != nil || got.Parent != nil {
+		t.Fatalf("期望所有字段都被清零, 得到 %+v", got)
+	}
+}
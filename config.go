@@ -0,0 +1,223 @@
+package gpool
+
+import "time"
+
+// 以下常量是 PoolConfig.Backend 可能的取值。
+const (
+	// BackendSyncPool 表示默认的 sync.Pool 后端。
+	BackendSyncPool = "sync.Pool"
+	// BackendDeterministic 表示 WithDeterministic 的 LIFO 栈后端。
+	BackendDeterministic = "deterministic"
+	// BackendSharded 表示 WithSharded 的分片后端。
+	BackendSharded = "sharded"
+	// BackendFixed 表示 WithCapacity 的固定容量后端。
+	BackendFixed = "fixed"
+	// BackendWeak 表示 WithWeakRetention 的弱引用后端。
+	BackendWeak = "weak"
+)
+
+// PoolConfig 是池生效配置的快照，由 Config 返回。
+type PoolConfig struct {
+	// Backend 是实际使用的存储后端，取值为 Backend* 常量之一。
+	// 例如在 Go 1.24 之前的版本中 WithWeakRetention 不起作用，这里会是 BackendSyncPool。
+	Backend string
+	// Shards 是分片后端的分片数量，其他后端为 0。
+	Shards int
+	// CacheWarmth 表示分片后端是否优先返回本地分片中最近放入的对象。
+	CacheWarmth bool
+	// ShardFunc 表示分片后端是否通过 WithShardFunc 选择分片。
+	ShardFunc bool
+	// GoroutineAffinity 表示 Get 是否优先返回调用方 goroutine 最近放入的对象。
+	GoroutineAffinity bool
+	// MaxIdle 是 WithMaxIdle 设置的闲置对象数量上限，为 0 时不限制。
+	MaxIdle int
+	// MaxReuse 是 WithMaxReuse 设置的对象最多被借出的次数，未生效时（例如 T 不是指针类型）为 0。
+	MaxReuse int
+	// Capacity 是固定容量后端最多保存的闲置对象数量，其他后端为 0。
+	Capacity int
+
+	// Max 是有界池当前同时借出对象数量的上限（包括 SetMax 的调整），无界池为 0。
+	Max int
+	// AutoScaleMin 和 AutoScaleMax 是自动伸缩的上限范围，AutoScaleTarget 是希望维持的平均等待时间；
+	// 未启用 WithAutoScale 时都为 0。
+	AutoScaleMin    int
+	AutoScaleMax    int
+	AutoScaleTarget time.Duration
+	// WarmupBarrier 表示 Get 是否在 MarkWarm 之前等待预热完成，WarmupTimeout 是每个 Get 最多等待的时间。
+	WarmupBarrier bool
+	WarmupTimeout time.Duration
+	// AllocCap 是池在整个生命周期中最多创建的对象数量，为 0 时不限制。
+	AllocCap int
+	// NewRateLimit 是每秒最多创建的新对象数量，为 0 时不限制。
+	NewRateLimit int
+
+	// Validator 和 ValidateOnPut 分别表示是否设置了 Get 时和 Put 时的校验函数。
+	Validator     bool
+	ValidateOnPut bool
+	// AutoReset 表示 Put 时是否自动重置对象；T 没有实现 Resetter 时即使设置了 WithAutoReset 也为 false。
+	AutoReset bool
+	// ResetFields 是 WithResetFields 声明的字段清理函数的数量。
+	ResetFields int
+	// ResetVerify 表示是否会检查重置后的对象，即同时设置了 WithResetVerify 和 WithDebug。
+	ResetVerify bool
+	// Recycle 表示是否设置了 WithRecycle。
+	Recycle bool
+	// RetainGuard 表示是否设置了 WithRetainGuard。
+	RetainGuard bool
+	// EnabledFunc 表示是否设置了 WithEnabledFunc。
+	EnabledFunc bool
+	// Dedup 表示是否设置了 WithDedup。
+	Dedup bool
+	// ZeroOnGet 表示 Get 是否在交出复用的对象之前将其清零。
+	ZeroOnGet bool
+	// AsyncPut 是 WithAsyncPut 队列的长度，为 0 时 Put 同步执行。
+	AsyncPut int
+	// StrictNil 表示是否启用了严格的 nil 检查。
+	StrictNil bool
+	// DiscardOnPanic 表示 Do 的回调 panic 时是否丢弃对象。
+	DiscardOnPanic bool
+
+	// Stats 表示是否记录统计信息。
+	Stats bool
+	// ShardedStats 表示统计计数器是否分散在多个分片中。
+	ShardedStats bool
+	// Measure 表示是否设置了 WithMeasure。
+	Measure bool
+	// AutoCompact 表示后台维护是否定期收缩闲置对象所在的底层数组。
+	AutoCompact bool
+	// WatermarkLow 和 WatermarkHigh 是 WithWatermarks 为闲置数量设置的区间，为 0 时不限制对应的一端。
+	WatermarkLow, WatermarkHigh int
+	// ProactiveReplace 是 WithProactiveReplace 替换闲置对象的大小阈值，未生效时为 0。
+	ProactiveReplace int
+	// Tap 表示是否设置了 WithTap。
+	Tap bool
+	// OnGet 和 OnPut 分别是 WithOnGet 和 WithOnPut 设置的回调的数量。
+	OnGet, OnPut int
+	// MetricsRecorder 表示是否设置了 WithMetricsRecorder。
+	MetricsRecorder bool
+	// AuditLog 表示是否设置了 WithAuditLog。
+	AuditLog bool
+	// StateChange 表示是否设置了 WithStateChange。
+	StateChange bool
+	// UniqueIssue 表示是否启用了 WithUniqueIssue 的严格身份记账。
+	UniqueIssue bool
+	// Metadata 表示是否为对象记录 WithMetadata 的元数据，T 不是指针类型时为 false。
+	Metadata bool
+	// TTL 是 WithTTL 设置的对象过期时间，未生效时（例如 T 不是指针类型）为 0。
+	TTL time.Duration
+	// IdleTimeout 是 WithIdleTimeout 设置的闲置超时，未生效时（例如 T 不是指针类型）为 0。
+	IdleTimeout time.Duration
+	// Clock 表示是否通过 WithClock 设置了时钟。
+	Clock bool
+	// Debug 表示是否启用了调试模式。
+	Debug bool
+	// ProfileLabel 是 Get 和 Put 期间设置的 pprof 标签 gpool 的值，为空时不设置标签。
+	ProfileLabel string
+	// ChurnThreshold 是持有时间检测的阈值，为 0 时不检测。
+	ChurnThreshold time.Duration
+}
+
+// Config 返回池当前生效配置的快照，可以用来确认组合使用的多个选项都按预期生效。
+func (p *Pool[T]) Config() PoolConfig {
+	c := PoolConfig{
+		Backend:           p.backendName(),
+		GoroutineAffinity: p.cfg.affinity,
+		NewRateLimit:      p.cfg.newRate,
+		AllocCap:          p.cfg.allocCap,
+		WarmupBarrier:     p.cfg.warmupBarrier,
+		WarmupTimeout:     p.cfg.warmupTimeout,
+		Validator:         p.cfg.validate != nil,
+		ValidateOnPut:     p.cfg.validateOnPut != nil,
+		AutoReset:         p.resetMode != resetNone,
+		ResetFields:       len(p.cfg.resetFields),
+		ResetVerify:       p.cfg.debug && p.cfg.resetVerify != nil,
+		Recycle:           p.cfg.recycle != nil,
+		RetainGuard:       p.cfg.retainGuard != nil,
+		EnabledFunc:       p.cfg.enabled != nil,
+		Dedup:             p.cfg.dedup != nil,
+		StrictNil:         p.cfg.strictNil,
+		ZeroOnGet:         p.cfg.zeroOnGet,
+		AsyncPut:          p.cfg.asyncPut,
+		DiscardOnPanic:    p.cfg.discardOnPanic,
+		Stats:             p.stats != nil,
+		ShardedStats:      p.stats != nil && p.stats.shards != nil,
+		Measure:           p.cfg.measure != nil,
+		Tap:               p.cfg.tap != nil,
+		OnGet:             len(p.cfg.onGet),
+		OnPut:             len(p.cfg.onPut),
+		MetricsRecorder:   p.cfg.recorder != nil,
+		AuditLog:          p.cfg.audit != nil,
+		StateChange:       p.cfg.stateChange != nil,
+		UniqueIssue:       p.ledger != nil,
+		Metadata:          p.meta != nil,
+		Clock:             p.cfg.now != nil,
+		Debug:             p.cfg.debug,
+		ProfileLabel:      p.cfg.profileLabel,
+		ChurnThreshold:    p.cfg.churnThreshold,
+	}
+	if c.NewRateLimit < 0 {
+		c.NewRateLimit = 0
+	}
+	if c.AsyncPut < 0 {
+		c.AsyncPut = 0
+	}
+	if c.AllocCap < 0 {
+		c.AllocCap = 0
+	}
+	if p.cfg.maxIdle > 0 && p.store != nil {
+		c.MaxIdle = p.cfg.maxIdle
+	}
+	if p.retires() {
+		c.MaxReuse = p.cfg.maxReuse
+	}
+	if p.replaces() {
+		c.ProactiveReplace = p.cfg.softThreshold
+	}
+	c.AutoCompact = p.compacts()
+	if p.expires() {
+		c.TTL = p.cfg.ttl
+	}
+	if p.idleTimeouts() {
+		c.IdleTimeout = p.cfg.idleTimeout
+	}
+	if p.watermarked() {
+		c.WatermarkLow, c.WatermarkHigh = p.cfg.lowWater, p.cfg.highWater
+	}
+	if p.churn == nil {
+		c.ChurnThreshold = 0
+	}
+	switch s := baseStore(p.store).(type) {
+	case *sharded[T]:
+		c.Shards = len(s.shards)
+		c.CacheWarmth = s.cacheWarmth
+		c.ShardFunc = s.shardFunc != nil
+	case *fixed[T]:
+		c.Capacity = s.capacity
+	}
+	if p.sem != nil {
+		c.Max = int(p.sem.limit())
+	}
+	if p.scaler != nil {
+		c.AutoScaleMin = int(p.scaler.min)
+		c.AutoScaleMax = int(p.scaler.max)
+		c.AutoScaleTarget = p.scaler.target
+	}
+	return c
+}
+
+// backendName 返回池实际使用的存储后端的名称。
+func (p *Pool[T]) backendName() string {
+	switch {
+	case p.store == nil:
+		return BackendSyncPool
+	case p.cfg.weak:
+		return BackendWeak
+	}
+	switch baseStore(p.store).(type) {
+	case *sharded[T]:
+		return BackendSharded
+	case *fixed[T]:
+		return BackendFixed
+	}
+	return BackendDeterministic
+}
@@ -0,0 +1,66 @@
+package gpool
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+// TestPool_Config 测试 Config 反映组合使用的多个选项的生效配置。
+func TestPool_Config(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	},
+		WithSharded[*bytes.Buffer](3),
+		WithCacheWarmth[*bytes.Buffer](),
+		WithMax[*bytes.Buffer](8),
+		WithValidator(func(*bytes.Buffer) bool { return true }),
+		WithAutoReset[*bytes.Buffer](),
+		WithStats[*bytes.Buffer](false),
+		WithNewRateLimit[*bytes.Buffer](100),
+		WithChurnDetector[*bytes.Buffer](time.Millisecond),
+		WithDebug[*bytes.Buffer](),
+		WithAsyncPut[*bytes.Buffer](4),
+		WithAuditLog[*bytes.Buffer](io.Discard),
+		WithZeroOnGet[*bytes.Buffer](),
+		WithMetadata[*bytes.Buffer](),
+	)
+	defer p.Close()
+	p.SetMax(4)
+
+	want := PoolConfig{
+		Backend:        BackendSharded,
+		Shards:         3,
+		CacheWarmth:    true,
+		Max:            4,
+		NewRateLimit:   100,
+		Validator:      true,
+		AutoReset:      true,
+		Debug:          true,
+		ChurnThreshold: time.Millisecond,
+		AsyncPut:       4,
+		AuditLog:       true,
+		ZeroOnGet:      true,
+		Metadata:       true,
+	}
+	if got := p.Config(); got != want {
+		t.Fatalf("期望配置\n%+v\n得到\n%+v", want, got)
+	}
+}
+
+// TestPool_Config_Default 测试默认配置和各个后端的名称。
+func TestPool_Config_Default(t *testing.T) {
+	newBuffer := func() *bytes.Buffer { return new(bytes.Buffer) }
+
+	if got := New(newBuffer).Config(); got != (PoolConfig{Backend: BackendSyncPool, Stats: true}) {
+		t.Errorf("默认配置不符合预期: %+v", got)
+	}
+	if got := New(newBuffer, WithDeterministic[*bytes.Buffer]()).Config().Backend; got != BackendDeterministic {
+		t.Errorf("期望后端为 %q, 得到 %q", BackendDeterministic, got)
+	}
+	got := New(newBuffer, WithCapacity[*bytes.Buffer](5)).Config()
+	if got.Backend != BackendFixed || got.Capacity != 5 {
+		t.Errorf("期望固定容量为 5 的后端, 得到 %+v", got)
+	}
+}
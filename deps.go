@@ -0,0 +1,21 @@
+package gpool
+
+import "sync/atomic"
+
+// GetWithDeps 从池 p 中获取一个对象，用 inject 把本次借用所需的依赖 deps（例如 logger、请求 id）注入其中后返回，
+// 适合“对象本身可以复用，但每次使用都需要不同依赖”的场景。inject 的返回值就是交给调用方的对象，
+// 对于指针类型通常原地修改并返回同一个对象。
+//
+// 返回的 put 函数在放回对象之前会以 D 的零值再调用一次 inject，清除注入的依赖，
+// 使闲置在池中的对象不再引用它们，下一个使用者也看不到上一次借用的依赖。
+// 应当通过 put 而不是 p.Put 放回对象；put 只有第一次调用生效，重复调用是安全的。
+func GetWithDeps[T, D any](p *Pool[T], deps D, inject func(x T, deps D) T) (x T, put func()) {
+	x = inject(p.Get(), deps)
+	var returned int32
+	return x, func() {
+		if atomic.CompareAndSwapInt32(&returned, 0, 1) {
+			var zero D
+			p.Put(inject(x, zero))
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package gpool
+
+import "testing"
+
+// requestDeps 是每次借用时注入的依赖。
+type requestDeps struct {
+	requestID string
+}
+
+// handler 是一个可以复用、每次借用注入不同依赖的对象。
+type handler struct {
+	deps    *requestDeps
+	scratch []byte
+}
+
+func injectDeps(h *handler, deps *requestDeps) *handler {
+	h.deps = deps
+	return h
+}
+
+// TestGetWithDeps 测试依赖在 Get 时被注入，在放回时被清除，复用的对象不会引用上一次借用的依赖。
+func TestGetWithDeps(t *testing.T) {
+	p := New(func() *handler {
+		return &handler{scratch: make([]byte, 0, 64)}
+	}, WithDeterministic[*handler]())
+
+	first := &requestDeps{requestID: "req-1"}
+	h, put := GetWithDeps(p, first, injectDeps)
+	if h.deps != first {
+		t.Fatal("期望依赖在借出时被注入")
+	}
+	put()
+	put() // 重复调用不会再次放回
+	if h.deps != nil {
+		t.Error("放回之后对象不应该再引用注入的依赖")
+	}
+	if s := p.Stats(); s.Idle != 1 || s.Puts != 1 {
+		t.Fatalf("期望对象只被放回一次: %+v", s)
+	}
+
+	second := &requestDeps{requestID: "req-2"}
+	reused, put := GetWithDeps(p, second, injectDeps)
+	defer put()
+	if reused != h {
+		t.Fatal("期望复用同一个对象")
+	}
+	if reused.deps != second {
+		t.Errorf("期望注入新的依赖, 得到 %+v", reused.deps)
+	}
+}
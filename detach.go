@@ -0,0 +1,144 @@
+package gpool
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// Detach 将借出的对象 x 从池中分离：调用方决定长期持有 x（例如它逃逸出了请求的作用域），不再把它放回池中。
+//
+// 对池而言 x 不再算作借出：Stats 的 Outstanding 减少一个并计入 Detached，有界池的额度也会被归还。
+// x 不会被关闭或以其他方式丢弃，它的生命周期从此完全由调用方管理。
+//
+// 之后如果 x 被误放回池中，Put 不会存入它，也不会再次归还额度，而是以 DiscardDetached 为原因丢弃它（对象会被关闭）。
+// 识别被分离的对象需要以对象本身作为键：在 Go 1.24 及以上版本中池只通过弱引用记住 x，
+// 调用方不再使用 x 后它仍然可以被 GC 回收；更早的版本上池会一直引用 x 直到它被这样丢弃为止，
+// 分离后直接丢掉的对象不会被回收，不应在长期运行的服务中大量使用 Detach。
+// T 不是指针、map 或 channel 这类引用对象本身的类型时，相等的值不一定是同一个对象，
+// 池不会记住被分离的对象，Detach 只更新统计和额度，之后放回的相等的值仍然像其他对象一样被存入池中。
+func (p *Pool[T]) Detach(x T) {
+	if p.detached != nil {
+		p.detached.add(refPointer(x))
+	}
+	if p.churn != nil {
+		p.churn.forget(x)
+	}
+	if p.leaks != nil {
+		p.leaks.returned(x)
+	}
+	if p.meta != nil {
+		p.meta.forget(x)
+	}
+	if p.ledger != nil {
+		p.ledger.forget(x)
+	}
+	if p.stats != nil {
+		p.stats.add(statDetached, 1)
+	}
+	p.releaseSlot(x)
+}
+
+// wasDetached 报告 x 是否是之前通过 Detach 分离的对象；如果是，就以 DiscardDetached 为原因丢弃它。
+func (p *Pool[T]) wasDetached(x T) bool {
+	// 没有被分离的对象时直接返回，使这个检查可以被内联，不在 Put 的热点路径上增加函数调用。
+	if d := p.detached; d == nil || atomic.LoadInt32(&d.n) == 0 {
+		return false
+	}
+	if !p.detached.remove(refPointer(x)) {
+		return false
+	}
+	p.discard(x, DiscardDetached)
+	return true
+}
+
+// detachSet 记录通过 Detach 分离的对象，以 detachKey 返回的键识别对象。
+type detachSet struct {
+	n     int32 // 集合中的键数量，为 0 时 remove 不必加锁，使用原子操作访问
+	mu    sync.Mutex
+	m     map[any]struct{}
+	prune int // 键的数量达到 prune 时清理已被 GC 回收的对象留下的键
+	// weak 表示通过 detachKey 弱引用对象。T 为 unsafe.Pointer 时它可能指向不由 Go 管理的内存，
+	// 不能为它创建弱引用，只能直接以地址为键。
+	weak bool
+}
+
+// detachPruneMin 是 detachSet 第一次清理已被回收的对象之前可以积累的键的数量。
+const detachPruneMin = 64
+
+// newDetachSet 为类型 T 创建一个 detachSet，T 的值不能唯一地标识对象时返回 nil。
+func newDetachSet[T any]() *detachSet {
+	if !refIdentity[T]() {
+		return nil
+	}
+	return &detachSet{weak: typeOf[T]().Kind() != reflect.UnsafePointer}
+}
+
+// hasIdentity 报告类型 T 的值是否可以安全地用 == 比较以识别对象。
+// 接口类型的动态值可能不可比较，比较时会 panic，因此不算在内。
+func hasIdentity[T any]() bool {
+	t := typeOf[T]()
+	return t.Kind() != reflect.Interface && t.Comparable()
+}
+
+// refIdentity 报告类型 T 的值是否引用对象本身（指针、map 或 channel），使相等的值一定是同一个对象。
+// 对于 int 或结构体这样的值类型，两个独立借出的值完全可能相等，不能据此区分对象。
+func refIdentity[T any]() bool {
+	switch typeOf[T]().Kind() {
+	case reflect.Chan, reflect.Map, reflect.Pointer, reflect.UnsafePointer:
+		return true
+	}
+	return false
+}
+
+// add 把地址为 x 的对象加入集合。键的数量翻倍时清理一次已被回收的对象，使集合不会无限增长。
+func (d *detachSet) add(x unsafe.Pointer) {
+	k := d.key(x)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.m == nil {
+		d.m = make(map[any]struct{})
+		d.prune = detachPruneMin
+	}
+	if _, ok := d.m[k]; ok {
+		return
+	}
+	d.m[k] = struct{}{}
+	if len(d.m) >= d.prune {
+		for k := range d.m {
+			if !detachLive(k) {
+				delete(d.m, k)
+			}
+		}
+		d.prune = 2 * len(d.m)
+		if d.prune < detachPruneMin {
+			d.prune = detachPruneMin
+		}
+	}
+	atomic.StoreInt32(&d.n, int32(len(d.m)))
+}
+
+// key 返回集合中代表地址为 x 的对象的键。
+func (d *detachSet) key(x unsafe.Pointer) any {
+	if d.weak {
+		return detachKey(x)
+	}
+	return x
+}
+
+// remove 从集合中移除地址为 x 的对象，并报告它是否在集合中。
+func (d *detachSet) remove(x unsafe.Pointer) bool {
+	if atomic.LoadInt32(&d.n) == 0 {
+		return false
+	}
+	k := d.key(x)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.m[k]; !ok {
+		return false
+	}
+	delete(d.m, k)
+	atomic.AddInt32(&d.n, -1)
+	return true
+}
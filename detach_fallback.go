@@ -0,0 +1,15 @@
+//go:build !go1.24
+
+package gpool
+
+import "unsafe"
+
+// detachKey 在 Go 1.24 之前没有 weak 包可用，直接以对象的地址为键，这会使对象一直不被 GC 回收。
+func detachKey(x unsafe.Pointer) any {
+	return x
+}
+
+// detachLive 报告键 k 代表的对象是否尚未被 GC 回收，以地址为键时对象总是存活的。
+func detachLive(k any) bool {
+	return true
+}
@@ -0,0 +1,20 @@
+//go:build go1.24
+
+package gpool
+
+import (
+	"unsafe"
+	"weak"
+)
+
+// detachKey 返回 detachSet 中代表地址为 x 的对象的键，它只弱引用对象，不会阻止对象被 GC 回收。
+// 由同一个对象创建的弱引用总是相等的，即使对象已被回收、地址被其他对象重用也不会与新对象的弱引用相等。
+func detachKey(x unsafe.Pointer) any {
+	return weak.Make((*byte)(x))
+}
+
+// detachLive 报告键 k 代表的对象是否尚未被 GC 回收，直接以地址为键的对象总是存活的。
+func detachLive(k any) bool {
+	w, ok := k.(weak.Pointer[byte])
+	return !ok || w.Value() != nil
+}
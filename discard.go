@@ -0,0 +1,125 @@
+package gpool
+
+import (
+	"io"
+	"reflect"
+)
+
+// 以下常量构成了对象被丢弃原因的统一分类，
+// 会作为 reason 参数传给 WithOnDiscard 设置的回调。
+const (
+	// DiscardOversized 表示对象超过了允许的大小。
+	DiscardOversized = "oversized"
+	// DiscardInvalid 表示对象未能通过校验。
+	DiscardInvalid = "invalid"
+	// DiscardExpired 表示对象超过 WithTTL 设置的时间未被使用，或者超过 WithIdleTimeout 设置的时间一直闲置。
+	DiscardExpired = "expired"
+	// DiscardNil 表示放回的对象是 nil。
+	DiscardNil = "nil"
+	// DiscardClosed 表示对象被放回了一个已关闭的池。
+	DiscardClosed = "closed-pool"
+	// DiscardOverflow 表示池中闲置对象已满。
+	DiscardOverflow = "overflow"
+	// DiscardCleared 表示闲置对象被 Clear 清空。
+	DiscardCleared = "cleared"
+	// DiscardAborted 表示通过 StagePut 暂存的对象被 abort。
+	DiscardAborted = "aborted"
+	// DiscardPanicked 表示使用对象的 Do 回调发生了 panic。
+	DiscardPanicked = "panicked"
+	// DiscardRetains 表示对象仍然引用着外部数据，保留它会使这些数据无法被回收。
+	DiscardRetains = "retains-references"
+	// DiscardDetached 表示放回的对象之前已经通过 Detach 从池中分离。
+	DiscardDetached = "detached"
+	// DiscardRebuilt 表示闲置对象被 RebuildIdle 用新的构造函数创建的对象替换。
+	DiscardRebuilt = "rebuilt"
+	// DiscardRolledBack 表示 Update 的回调决定不保留它修改过的对象。
+	DiscardRolledBack = "rolled-back"
+	// DiscardDuplicate 表示池中已有与放回的对象相等的闲置对象，见 WithDedup。
+	DiscardDuplicate = "duplicate"
+	// DiscardDisabled 表示对象被放回时 WithEnabledFunc 设置的函数报告池化已被关闭。
+	DiscardDisabled = "disabled"
+	// DiscardRetired 表示对象已经被借出了 WithMaxReuse 设置的次数。
+	DiscardRetired = "retired"
+)
+
+// discardReasons 列出了所有丢弃原因，Stats 按这一顺序为它们计数。
+var discardReasons = [...]string{
+	DiscardOversized,
+	DiscardInvalid,
+	DiscardExpired,
+	DiscardNil,
+	DiscardClosed,
+	DiscardOverflow,
+	DiscardCleared,
+	DiscardAborted,
+	DiscardPanicked,
+	DiscardRetains,
+	DiscardDetached,
+	DiscardRebuilt,
+	DiscardRolledBack,
+	DiscardDuplicate,
+	DiscardDisabled,
+	DiscardRetired,
+}
+
+// discard 丢弃对象 x：先通知 WithOnDiscard 设置的回调，
+// 然后释放池为它持有的资源，最后如果它实现了 io.Closer 就关闭它并返回关闭的错误。
+// 所有丢弃路径都必须经过这里，以保证回调对每个对象只调用一次。
+func (p *Pool[T]) discard(x T, reason string) error {
+	if p.stats != nil {
+		p.stats.addDiscard(reason)
+	}
+	if p.cfg.audit != nil {
+		p.cfg.audit.record(x, reason)
+	}
+	if p.cfg.onDiscard != nil {
+		p.cfg.onDiscard(x, reason)
+	}
+	if p.cfg.release != nil {
+		p.cfg.release(x)
+	}
+	if p.meta != nil {
+		p.meta.forget(x)
+	}
+	if p.ledger != nil {
+		p.ledger.forget(x)
+	}
+	if c, ok := any(x).(io.Closer); ok && !isNil(x) {
+		return c.Close()
+	}
+	return nil
+}
+
+// discardAll 丢弃 xs 中的所有对象，并返回第一个关闭错误。
+func (p *Pool[T]) discardAll(xs []T, reason string) error {
+	var first error
+	for _, x := range xs {
+		if err := p.discard(x, reason); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// nilable 报告类型 T 的值是否可能为 nil。
+func nilable[T any]() bool {
+	switch reflect.TypeOf((*T)(nil)).Elem().Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map,
+		reflect.Pointer, reflect.Slice, reflect.UnsafePointer:
+		return true
+	}
+	return false
+}
+
+// isNil 报告 x 是否为 nil，包括被包装在接口中的 nil 指针等情况。
+func isNil[T any](x T) bool {
+	v := reflect.ValueOf(any(x))
+	switch v.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map,
+		reflect.Pointer, reflect.Slice, reflect.UnsafePointer:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package gpool
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestPool_OnDiscard_Nil 测试放回 nil 对象时会以 DiscardNil 为原因触发丢弃回调。
+func TestPool_OnDiscard_Nil(t *testing.T) {
+	var reasons []string
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithOnDiscard(func(b *bytes.Buffer, reason string) {
+		if b != nil {
+			t.Errorf("期望丢弃的是 nil 对象, 但得到了 %v", b)
+		}
+		reasons = append(reasons, reason)
+	}))
+
+	p.Put(nil)
+
+	if len(reasons) != 1 || reasons[0] != DiscardNil {
+		t.Fatalf("期望丢弃回调以 %q 被调用一次, 实际调用记录为 %v", DiscardNil, reasons)
+	}
+	// nil 对象不应进入池中。
+	if buf := p.Get(); buf == nil {
+		t.Fatal("放回的 nil 对象不应该被之后的 Get() 取回")
+	}
+}
+
+// TestPool_OnDiscard_NotCalledForReuse 测试正常的 Get/Put 不会触发丢弃回调。
+func TestPool_OnDiscard_NotCalledForReuse(t *testing.T) {
+	calls := 0
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithOnDiscard(func(*bytes.Buffer, string) {
+		calls++
+	}))
+
+	for i := 0; i < 10; i++ {
+		buf := p.Get()
+		p.Put(buf)
+	}
+
+	if calls != 0 {
+		t.Fatalf("正常的 Get/Put 不应该触发丢弃回调, 但实际调用了 %d 次", calls)
+	}
+}
+
+// TestPool_OnDiscard_NilInterface 测试接口类型的 nil 值（包括包装了 nil 指针的接口）同样会被丢弃。
+func TestPool_OnDiscard_NilInterface(t *testing.T) {
+	var reasons []string
+	p := New(func() any {
+		return new(bytes.Buffer)
+	}, WithOnDiscard(func(_ any, reason string) {
+		reasons = append(reasons, reason)
+	}))
+
+	p.Put(nil)
+	p.Put((*bytes.Buffer)(nil))
+
+	if len(reasons) != 2 {
+		t.Fatalf("期望两个 nil 值各触发一次丢弃回调, 实际调用记录为 %v", reasons)
+	}
+}
+
+// TestPool_Put_NonNilableType 测试值类型的对象总是会被放入池中。
+func TestPool_Put_NonNilableType(t *testing.T) {
+	calls := 0
+	p := New(func() int {
+		return 0
+	}, WithOnDiscard(func(int, string) {
+		calls++
+	}))
+
+	p.Put(0)
+
+	if calls != 0 {
+		t.Fatalf("值类型的零值不是 nil, 不应该被丢弃, 但丢弃回调被调用了 %d 次", calls)
+	}
+}
+
+// TestPool_OnDiscard_ClosedAndCleared 测试 Clear 和 Close 丢弃的对象分别以
+// DiscardCleared 和 DiscardClosed 为原因触发丢弃回调。
+func TestPool_OnDiscard_ClosedAndCleared(t *testing.T) {
+	var reasons []string
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer](), WithOnDiscard(func(_ *bytes.Buffer, reason string) {
+		reasons = append(reasons, reason)
+	}))
+
+	a, b := p.Get(), p.Get()
+	p.Put(a)
+	p.Clear()
+	p.Put(a)
+	p.Close()
+	p.Put(b)
+
+	want := []string{DiscardCleared, DiscardClosed, DiscardClosed}
+	if len(reasons) != len(want) {
+		t.Fatalf("期望丢弃原因 %v, 实际为 %v", want, reasons)
+	}
+	for i := range want {
+		if reasons[i] != want[i] {
+			t.Fatalf("期望丢弃原因 %v, 实际为 %v", want, reasons)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package gpool
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrorKind 表示池操作失败的类别。
+type ErrorKind int
+
+const (
+	// KindClosed 表示池已被关闭。
+	KindClosed ErrorKind = iota + 1
+	// KindTimeout 表示等待对象时超过了截止时间。
+	KindTimeout
+	// KindCancelled 表示等待对象时 context 被取消。
+	KindCancelled
+	// KindNewFailed 表示构造函数未能创建新对象。
+	KindNewFailed
+	// KindExhausted 表示有界池中没有足够的空闲额度满足请求。
+	KindExhausted
+	// KindRejected 表示放回的对象没有被存入池中，PoolError 的 Reason 说明了原因。
+	KindRejected
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case KindClosed:
+		return "pool closed"
+	case KindTimeout:
+		return "timeout"
+	case KindCancelled:
+		return "cancelled"
+	case KindNewFailed:
+		return "new failed"
+	case KindExhausted:
+		return "pool exhausted"
+	case KindRejected:
+		return "put rejected"
+	}
+	return "unknown error"
+}
+
+// PoolError 是池的所有操作返回的错误类型。
+// Kind 说明失败的类别，Err 是导致失败的底层错误（可能为 nil）。
+//
+// 可以使用 errors.Is 将 PoolError 与 ErrClosed 等哨兵错误比较，只要 Kind 相同即视为匹配；
+// 也可以通过 errors.Is 继续匹配底层错误，例如 context.DeadlineExceeded。
+type PoolError struct {
+	Kind ErrorKind
+	Err  error
+	// Reason 是 KindRejected 类别的错误对应的丢弃原因（Discard* 常量），其他类别为空。
+	Reason string
+}
+
+func (e *PoolError) Error() string {
+	s := "gpool: " + e.Kind.String()
+	if e.Reason != "" {
+		s += " (" + e.Reason + ")"
+	}
+	if e.Err != nil {
+		s += ": " + e.Err.Error()
+	}
+	return s
+}
+
+// Unwrap 返回底层错误。
+func (e *PoolError) Unwrap() error {
+	return e.Err
+}
+
+// Is 报告 target 是否是与 e 类别相同的哨兵错误。target 设置了 Reason 时，原因也必须相同，
+// 例如 errors.Is(err, &PoolError{Kind: KindRejected, Reason: DiscardInvalid})。
+func (e *PoolError) Is(target error) bool {
+	t, ok := target.(*PoolError)
+	return ok && t.Err == nil && t.Kind == e.Kind && (t.Reason == "" || t.Reason == e.Reason)
+}
+
+// 每一种 ErrorKind 对应的哨兵错误，用于 errors.Is 判断。
+var (
+	ErrClosed    = &PoolError{Kind: KindClosed}
+	ErrTimeout   = &PoolError{Kind: KindTimeout}
+	ErrCancelled = &PoolError{Kind: KindCancelled}
+	ErrNewFailed = &PoolError{Kind: KindNewFailed}
+	ErrExhausted = &PoolError{Kind: KindExhausted}
+	ErrRejected  = &PoolError{Kind: KindRejected}
+)
+
+// rejectError 返回 Put 以 reason 为原因丢弃对象时 PutE 报告的错误，reason 为空时返回 nil。
+func rejectError(reason string) error {
+	switch reason {
+	case "":
+		return nil
+	case DiscardClosed:
+		return ErrClosed
+	}
+	return &PoolError{Kind: KindRejected, Reason: reason}
+}
+
+// ErrAllocCap 表示池创建的对象总数已经达到 WithAllocCap 设置的上限，
+// 它作为 KindNewFailed 类别的 PoolError 的底层错误出现。
+var ErrAllocCap = errors.New("gpool: allocation cap reached")
+
+// errExceedsMax 表示一次请求的对象数量超过了有界池的上限，永远无法被满足。
+var errExceedsMax = errors.New("gpool: request exceeds pool max")
+
+// contextError 将 context 结束的原因包装为对应类别的 PoolError。
+func contextError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &PoolError{Kind: KindTimeout, Err: err}
+	}
+	return &PoolError{Kind: KindCancelled, Err: err}
+}
@@ -0,0 +1,146 @@
+package gpool
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestPoolError_Is 测试 PoolError 可以通过 errors.Is 与同类别的哨兵错误匹配，并能解包出底层错误。
+func TestPoolError_Is(t *testing.T) {
+	underlying := errors.New("dial failed")
+	err := error(&PoolError{Kind: KindNewFailed, Err: underlying})
+
+	if !errors.Is(err, ErrNewFailed) {
+		t.Error("PoolError 应该与同类别的哨兵错误匹配")
+	}
+	if errors.Is(err, ErrClosed) {
+		t.Error("PoolError 不应该与其他类别的哨兵错误匹配")
+	}
+	if !errors.Is(err, underlying) {
+		t.Error("应该可以通过 errors.Is 匹配底层错误")
+	}
+	var pe *PoolError
+	if !errors.As(err, &pe) || pe.Kind != KindNewFailed {
+		t.Errorf("应该可以通过 errors.As 取得 PoolError, 得到 %v", pe)
+	}
+	if got := err.Error(); got != "gpool: new failed: dial failed" {
+		t.Errorf("错误信息不正确: %q", got)
+	}
+}
+
+// TestPoolError_Exhausted 测试有界池额度不足时返回 KindExhausted 类别的错误。
+func TestPoolError_Exhausted(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithMax[*bytes.Buffer](1))
+	p.Get()
+
+	_, err := p.TryGetAll(1)
+	var pe *PoolError
+	if !errors.Is(err, ErrExhausted) || !errors.As(err, &pe) || pe.Kind != KindExhausted {
+		t.Fatalf("期望得到 KindExhausted 类别的 PoolError, 得到 %v", err)
+	}
+}
+
+// TestPoolError_Closed 测试已关闭的池返回 KindClosed 类别的错误，且正在等待额度的请求会被唤醒。
+func TestPoolError_Closed(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithMax[*bytes.Buffer](1))
+	p.Get()
+
+	got := make(chan *bytes.Buffer)
+	go func() {
+		got <- p.Get()
+	}()
+	time.Sleep(10 * time.Millisecond)
+	p.Close()
+
+	select {
+	case buf := <-got:
+		if buf == nil {
+			t.Fatal("池关闭后等待中的 Get() 应该返回新创建的对象")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("池关闭后等待中的 Get() 应该立即返回")
+	}
+
+	if _, err := p.TryGetAll(1); !errors.Is(err, ErrClosed) {
+		t.Fatalf("期望得到 ErrClosed, 得到 %v", err)
+	}
+	if err := p.sem.acquire(context.Background(), 1); !errors.Is(err, ErrClosed) {
+		t.Fatalf("已关闭的信号量应该返回 ErrClosed, 得到 %v", err)
+	}
+}
+
+// TestPoolError_Context 测试等待额度时 context 超时或取消分别返回 KindTimeout 和 KindCancelled 类别的错误。
+func TestPoolError_Context(t *testing.T) {
+	s := newSemaphore(1)
+	s.tryAcquire(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := s.acquire(ctx, 1)
+	if !errors.Is(err, ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("期望得到包装了 context.DeadlineExceeded 的 ErrTimeout, 得到 %v", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	err = s.acquire(ctx, 1)
+	if !errors.Is(err, ErrCancelled) || !errors.Is(err, context.Canceled) {
+		t.Errorf("期望得到包装了 context.Canceled 的 ErrCancelled, 得到 %v", err)
+	}
+}
+
+// TestPool_PutE 测试 PutE 对每一种拒绝放回的情况返回对应类别和原因的 PoolError，存入时返回 nil。
+func TestPool_PutE(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithMaxIdle[*bytes.Buffer](1), WithValidateOnPut(func(b *bytes.Buffer) bool {
+		return b.Len() == 0
+	}))
+	if err := p.PutE(new(bytes.Buffer)); err != nil {
+		t.Fatalf("存入对象时期望 nil, 得到 %v", err)
+	}
+
+	dirty := bytes.NewBufferString("dirty")
+	slices := NewSlicePtrPool[byte](8, 16)
+	big := make([]byte, 0, 64)
+	for _, tc := range []struct {
+		name   string
+		put    func() error
+		reason string
+	}{
+		{"nil", func() error { return p.PutE(nil) }, DiscardNil},
+		{"invalid", func() error { return p.PutE(dirty) }, DiscardInvalid},
+		{"max-idle", func() error { return p.PutE(new(bytes.Buffer)) }, DiscardOverflow},
+		{"oversized", func() error { return slices.PutE(&big) }, DiscardOversized},
+	} {
+		err := tc.put()
+		var pe *PoolError
+		if !errors.As(err, &pe) || pe.Kind != KindRejected || pe.Reason != tc.reason {
+			t.Errorf("%s: 期望原因为 %q 的 KindRejected 错误, 得到 %v", tc.name, tc.reason, err)
+		}
+		if !errors.Is(err, ErrRejected) || !errors.Is(err, &PoolError{Kind: KindRejected, Reason: tc.reason}) {
+			t.Errorf("%s: 错误应该与 ErrRejected 和同样原因的错误匹配", tc.name)
+		}
+	}
+	if got := rejectError(DiscardInvalid).Error(); got != "gpool: put rejected (invalid)" {
+		t.Errorf("错误信息不正确: %q", got)
+	}
+
+	p.Close()
+	if err := p.PutE(new(bytes.Buffer)); !errors.Is(err, ErrClosed) {
+		t.Errorf("池关闭后期望 ErrClosed, 得到 %v", err)
+	}
+	if p.TryPut(new(bytes.Buffer)) {
+		t.Error("池关闭后 TryPut 应该返回 false")
+	}
+	if s := p.Stats(); s.Puts != 6 {
+		t.Errorf("被拒绝的对象也应该计入 Puts: %+v", s)
+	}
+}
@@ -0,0 +1,28 @@
+package gpool
+
+import "expvar"
+
+// Publish 把池的统计信息以 name 为名称注册到 expvar，使已经暴露 /debug/vars 的服务无需引入指标库就能观察池的命中率。
+// 它在 /debug/vars 中显示为一个 JSON 对象，包含 Stats 的所有字段，以及命中率 HitRate（Hits / Gets，没有 Get 时为 0）。
+// 每次读取时都会重新获取 Stats，因此注册之后不需要再做任何事。
+//
+// 与 expvar.Publish 一样，name 已经被注册时 Publish 会 panic；注册无法撤销，池被关闭后仍会显示最后的统计信息。
+func (p *Pool[T]) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		return newPublishedStats(p.Stats())
+	}))
+}
+
+// publishedStats 是 Publish 导出的统计信息。
+type publishedStats struct {
+	Stats
+	HitRate float64
+}
+
+func newPublishedStats(s Stats) publishedStats {
+	ps := publishedStats{Stats: s}
+	if s.Gets > 0 {
+		ps.HitRate = float64(s.Hits) / float64(s.Gets)
+	}
+	return ps
+}
@@ -0,0 +1,56 @@
+package gpool
+
+import (
+	"bytes"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+// publishSeq 使每次运行测试时注册的 expvar 名称都不同，expvar 的注册无法撤销。
+var publishSeq int32
+
+// TestPool_Publish 测试 Publish 注册的 expvar 变量每次读取时都反映池当前的统计信息和命中率。
+func TestPool_Publish(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer]())
+	name := fmt.Sprintf("gpool_test_publish_%d", atomic.AddInt32(&publishSeq, 1))
+	p.Publish(name)
+
+	read := func() (s struct {
+		Gets, Hits, Misses, Outstanding int64
+		HitRate                         float64
+	}) {
+		t.Helper()
+		v := expvar.Get(name)
+		if v == nil {
+			t.Fatal("Publish 应该注册 expvar 变量")
+		}
+		if err := json.Unmarshal([]byte(v.String()), &s); err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	if s := read(); s.Gets != 0 || s.HitRate != 0 {
+		t.Fatalf("期望没有任何统计, 得到 %+v", s)
+	}
+	b := p.Get()
+	p.Put(b)
+	p.Get()
+	p.Get()
+	p.Put(b)
+	if s := read(); s.Gets != 3 || s.Hits != 1 || s.Misses != 2 || s.Outstanding != 1 || s.HitRate != 1.0/3 {
+		t.Fatalf("expvar 变量应该反映当前的统计信息, 得到 %+v", s)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("重复注册同一个名称应该 panic")
+		}
+	}()
+	p.Publish(name)
+}
@@ -0,0 +1,85 @@
+package gpool
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+// forceDrop 把内嵌的 sync.Pool 换成一个新的空 sync.Pool，模拟 GC 清空 sync.Pool 的主缓存和 victim 缓存，
+// 使依赖 GC 行为的逻辑可以被确定性地测试，而不必反复调用 runtime.GC 并在 sync.Pool 保留了对象时跳过测试。
+// New 字段保持不变；它不是并发安全的，只能在没有其他 goroutine 使用池时调用。
+//
+// 它定义在测试文件中，只在 go test 构建时存在，不会进入正式的构建。
+func (p *Pool[T]) forceDrop() {
+	p.Pool = sync.Pool{New: p.Pool.New}
+}
+
+// newMeasuredBufferPool 创建一个用 WithMeasure 统计缓冲区容量的 sync.Pool 后端池。
+func newMeasuredBufferPool() *Pool[*bytes.Buffer] {
+	return New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithMeasure(func(b *bytes.Buffer) int {
+		return b.Cap()
+	}))
+}
+
+// TestPool_ForceDrop_EstimatedBytes 测试 sync.Pool 被清空后，EstimatedBytes 的估算值在下一次 Get 时被校正为 0。
+func TestPool_ForceDrop_EstimatedBytes(t *testing.T) {
+	p := newMeasuredBufferPool()
+	for i := 0; i < 4; i++ {
+		b := new(bytes.Buffer)
+		b.Grow(256)
+		p.Put(b)
+	}
+	p.forceDrop()
+	if got := p.EstimatedBytes(); got == 0 {
+		t.Fatal("Get 之前估算值还不知道 sync.Pool 已被清空")
+	}
+
+	if b := p.Get(); b.Cap() != 0 {
+		t.Fatal("清空后 Get 应该通过 newFunc 创建新对象")
+	}
+	if got := p.EstimatedBytes(); got != 0 {
+		t.Fatalf("sync.Pool 被清空后期望估算值被校正为 0, 得到 %d", got)
+	}
+
+	b := new(bytes.Buffer)
+	b.Grow(64)
+	p.Put(b)
+	if got := p.EstimatedBytes(); got != int64(b.Cap()) {
+		t.Fatalf("校正之后期望估算值为 %d, 得到 %d", b.Cap(), got)
+	}
+}
+
+// TestPool_ForceDrop_Stats 测试 sync.Pool 被清空后，Get 新建的对象计入 Misses，闲置对象不会被复用。
+func TestPool_ForceDrop_Stats(t *testing.T) {
+	p := newMeasuredBufferPool()
+	p.Put(p.Get())
+	p.forceDrop()
+
+	p.Get()
+	if s := p.Stats(); s.Gets != 2 || s.Misses != 2 || s.Hits != 0 {
+		t.Errorf("清空后的 Get 应该计为未命中: %+v", s)
+	}
+}
+
+// TestPool_ForceDrop_Validator 测试 sync.Pool 被清空后，Get 新建的对象不会被 WithValidator 校验和丢弃。
+func TestPool_ForceDrop_Validator(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithValidator(func(b *bytes.Buffer) bool {
+		return b.Len() == 0
+	}))
+	b := p.Get()
+	b.WriteString("stale")
+	p.Put(b)
+	p.forceDrop()
+
+	if got := p.Get(); got == b || got.Len() != 0 {
+		t.Fatal("清空后应该取回新建的对象")
+	}
+	if s := p.Stats(); s.Discards != 0 {
+		t.Errorf("新建的对象不应该被丢弃: %+v", s)
+	}
+}
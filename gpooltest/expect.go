@@ -0,0 +1,31 @@
+package gpooltest
+
+import (
+	"testing"
+
+	"github.com/muzhy/gpool"
+)
+
+// ExpectMisses 运行 fn，并断言期间池 p 通过 newFunc 创建新对象的次数（Stats 的 Misses）不超过 k，
+// 否则通过 t.Errorf 报告测试失败。它用来在回归测试中发现使池化失效的改动，例如：
+//
+//	p.WarmUp(4)
+//	gpooltest.ExpectMisses(t, p, 0, func() {
+//		handle(p) // 应当只复用预热的对象
+//	})
+//
+// 它比较的是 fn 运行前后的 Misses，与 fn 并发使用同一个池的其他 goroutine 造成的未命中也会被计入。
+// 默认的 sync.Pool 后端的闲置对象随时可能被 GC 清空，结果不稳定，断言精确的次数时应当使用确定性后端等其他后端。
+// 通过 gpool.WithStats(false) 禁用了统计的池无法统计未命中，ExpectMisses 会报告测试失败。
+func ExpectMisses[T any](t testing.TB, p *gpool.Pool[T], k int64, fn func()) {
+	t.Helper()
+	if !p.Config().Stats {
+		t.Errorf("gpooltest: ExpectMisses requires a pool with statistics enabled")
+		return
+	}
+	before := p.Stats().Misses
+	fn()
+	if got := p.Stats().Misses - before; got > k {
+		t.Errorf("gpooltest: newFunc was called %d times, expected at most %d", got, k)
+	}
+}
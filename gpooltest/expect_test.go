@@ -0,0 +1,55 @@
+package gpooltest
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/muzhy/gpool"
+)
+
+// recordingTB 记录测试失败信息而不终止测试，用于检查 ExpectMisses 是否报告了失败。
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// TestExpectMisses 测试对象被复用时 ExpectMisses 通过，池化失效、未命中次数超过上限时报告失败。
+func TestExpectMisses(t *testing.T) {
+	p := gpool.New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, gpool.WithDeterministic[*bytes.Buffer]())
+	p.WarmUp(1)
+
+	rec := &recordingTB{TB: t}
+	ExpectMisses(rec, p, 0, func() {
+		for i := 0; i < 10; i++ {
+			p.Put(p.Get())
+		}
+	})
+	if len(rec.errors) != 0 {
+		t.Fatalf("对象被复用时不应该报告失败, 得到 %q", rec.errors)
+	}
+
+	ExpectMisses(rec, p, 1, func() {
+		for i := 0; i < 3; i++ {
+			p.Get() // 从不放回，每次都需要新建
+		}
+	})
+	if len(rec.errors) != 1 {
+		t.Fatalf("未命中次数超过上限时应该报告一次失败, 得到 %q", rec.errors)
+	}
+
+	rec.errors = nil
+	noStats := gpool.New(func() int { return 0 }, gpool.WithStats[int](false))
+	ExpectMisses(rec, noStats, 0, func() {})
+	if len(rec.errors) != 1 {
+		t.Errorf("禁用统计的池应该报告失败, 得到 %q", rec.errors)
+	}
+}
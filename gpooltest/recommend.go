@@ -0,0 +1,133 @@
+// Package gpooltest 提供开发和测试 gpool 的使用方式时的辅助工具。
+package gpooltest
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"time"
+
+	"github.com/muzhy/gpool"
+)
+
+// DefaultIterations 是 Recommend 对每种配置运行负载的次数。
+const DefaultIterations = 10000
+
+// Result 是一种池配置运行负载的测量结果。
+type Result struct {
+	// Name 是配置的简短名称，例如 "sharded"、"fixed-64"。
+	Name string
+	// Constructor 是创建这种配置的池的 Go 代码，newFunc 表示传给 Recommend 的构造函数。
+	Constructor string
+	// NsPerOp 和 AllocsPerOp 是每次运行负载的平均耗时（纳秒）和平均内存分配次数。
+	NsPerOp     int64
+	AllocsPerOp int64
+}
+
+// Recommendation 是 Recommend 的结果。
+type Recommendation struct {
+	// Best 是 Results 中表现最好的配置：分配次数最少，分配次数相同时耗时最短。
+	Best Result
+	// Results 按测试顺序列出所有配置的测量结果。
+	Results []Result
+}
+
+// String 返回推荐的构造调用及各配置的测量结果。
+func (r Recommendation) String() string {
+	s := "recommended: " + r.Best.Constructor + "\n"
+	for _, res := range r.Results {
+		s += fmt.Sprintf("  %-12s %8d ns/op %6d allocs/op\n", res.Name, res.NsPerOp, res.AllocsPerOp)
+	}
+	return s
+}
+
+// Recommend 对几种常用的池配置（默认的 sync.Pool、确定性后端、分片后端，以及几种容量的固定容量后端）
+// 分别运行 DefaultIterations 次 workload，并推荐每次运行分配最少、耗时最短的配置。
+//
+// workload 应该模拟真实的访问模式，通常是一次或几次 Get 和 Put。每种配置使用一个新建的池，
+// 正式测量前会先运行一次 workload 作为预热。这是开发期间选择后端的辅助工具，
+// 测量在当前 goroutine 中串行进行，结果只反映单个 goroutine 的访问模式，也会受到机器负载的影响。
+func Recommend[T any](newFunc func() T, workload func(*gpool.Pool[T])) Recommendation {
+	return RecommendN(DefaultIterations, newFunc, workload)
+}
+
+// RecommendN 与 Recommend 相同，但对每种配置运行 n 次 workload。n <= 0 时使用 DefaultIterations。
+func RecommendN[T any](n int, newFunc func() T, workload func(*gpool.Pool[T])) Recommendation {
+	if n <= 0 {
+		n = DefaultIterations
+	}
+	var r Recommendation
+	for i, c := range candidates[T]() {
+		res := measure(n, gpool.New(newFunc, c.opts...), workload)
+		res.Name, res.Constructor = c.name, c.constructor
+		r.Results = append(r.Results, res)
+		if i == 0 || better(res, r.Best) {
+			r.Best = res
+		}
+	}
+	return r
+}
+
+// candidate 是 Recommend 测试的一种池配置。
+type candidate[T any] struct {
+	name        string
+	constructor string
+	opts        []gpool.Option[T]
+}
+
+// fixedSizes 是 Recommend 测试的固定容量后端的容量。
+var fixedSizes = []int{16, 64, 256}
+
+func candidates[T any]() []candidate[T] {
+	typ := reflect.TypeOf((*T)(nil)).Elem().String()
+	cs := []candidate[T]{
+		{name: "sync.Pool", constructor: "gpool.New(newFunc)"},
+		{
+			name:        "deterministic",
+			constructor: fmt.Sprintf("gpool.New(newFunc, gpool.WithDeterministic[%s]())", typ),
+			opts:        []gpool.Option[T]{gpool.WithDeterministic[T]()},
+		},
+		{
+			name:        "sharded",
+			constructor: fmt.Sprintf("gpool.New(newFunc, gpool.WithSharded[%s](0))", typ),
+			opts:        []gpool.Option[T]{gpool.WithSharded[T](0)},
+		},
+	}
+	for _, n := range fixedSizes {
+		cs = append(cs, candidate[T]{
+			name:        fmt.Sprintf("fixed-%d", n),
+			constructor: fmt.Sprintf("gpool.New(newFunc, gpool.WithCapacity[%s](%d))", typ, n),
+			opts:        []gpool.Option[T]{gpool.WithCapacity[T](n)},
+		})
+	}
+	return cs
+}
+
+// measure 对 p 运行 n 次 workload，返回平均耗时和平均分配次数，结束后关闭 p。
+func measure[T any](n int, p *gpool.Pool[T], workload func(*gpool.Pool[T])) Result {
+	defer p.Close()
+	workload(p)
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for i := 0; i < n; i++ {
+		workload(p)
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	return Result{
+		NsPerOp:     elapsed.Nanoseconds() / int64(n),
+		AllocsPerOp: int64(after.Mallocs-before.Mallocs) / int64(n),
+	}
+}
+
+// better 报告 a 是否比 b 更好：分配次数更少，或分配次数相同而耗时更短。
+func better(a, b Result) bool {
+	if a.AllocsPerOp != b.AllocsPerOp {
+		return a.AllocsPerOp < b.AllocsPerOp
+	}
+	return a.NsPerOp < b.NsPerOp
+}
@@ -0,0 +1,66 @@
+package gpooltest
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/muzhy/gpool"
+)
+
+// TestRecommend 测试 Recommend 对每种配置都运行了负载，并返回完整的推荐结果。
+func TestRecommend(t *testing.T) {
+	const n = 100
+	runs := make(map[*gpool.Pool[*bytes.Buffer]]int)
+	r := RecommendN(n, func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, func(p *gpool.Pool[*bytes.Buffer]) {
+		runs[p]++
+		b := p.Get()
+		b.WriteString("payload")
+		b.Reset()
+		p.Put(b)
+	})
+
+	want := len(candidates[*bytes.Buffer]())
+	if len(r.Results) != want || len(runs) != want {
+		t.Fatalf("期望测试 %d 种配置, 得到 %d 个结果, 负载运行在 %d 个池上", want, len(r.Results), len(runs))
+	}
+	for p, got := range runs {
+		// 每种配置在正式测量前还会预热一次。
+		if got != n+1 {
+			t.Errorf("每个池应该运行 %d 次负载, 池 %p 运行了 %d 次", n+1, p, got)
+		}
+	}
+
+	names := make(map[string]bool)
+	for _, res := range r.Results {
+		if res.Name == "" || !strings.HasPrefix(res.Constructor, "gpool.New(newFunc") || res.NsPerOp < 0 || res.AllocsPerOp < 0 {
+			t.Errorf("结果不完整: %+v", res)
+		}
+		names[res.Name] = true
+	}
+	for _, name := range []string{"sync.Pool", "deterministic", "sharded", "fixed-16", "fixed-64", "fixed-256"} {
+		if !names[name] {
+			t.Errorf("缺少配置 %s 的结果", name)
+		}
+	}
+
+	for _, res := range r.Results {
+		if better(res, r.Best) {
+			t.Errorf("Best %+v 不是最好的配置, %+v 更好", r.Best, res)
+		}
+	}
+	if !names[r.Best.Name] || !strings.Contains(r.String(), r.Best.Constructor) {
+		t.Errorf("推荐结果应该是测试过的配置之一: %s", r)
+	}
+}
+
+// TestCandidates_Constructor 测试推荐的构造调用使用了正确的类型参数。
+func TestCandidates_Constructor(t *testing.T) {
+	for _, c := range candidates[*bytes.Buffer]() {
+		if c.name == "fixed-64" && c.constructor != "gpool.New(newFunc, gpool.WithCapacity[*bytes.Buffer](64))" {
+			t.Errorf("构造调用不正确: %s", c.constructor)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package gpool
+
+import "sync"
+
+// Managed 是 PoolGroup 可以统一管理的池所需实现的接口。
+// 任意类型参数的 *Pool[T] 都实现了它。
+type Managed interface {
+	Clear() error
+	Close() error
+	Stats() Stats
+}
+
+// PoolGroup 统一管理一组池（元素类型可以各不相同）的生命周期和统计信息。
+// 零值即可使用，所有方法都是并发安全的。
+type PoolGroup struct {
+	mu    sync.Mutex
+	pools []Managed
+}
+
+// Add 将池 p 加入组中。
+func (g *PoolGroup) Add(p Managed) {
+	g.mu.Lock()
+	g.pools = append(g.pools, p)
+	g.mu.Unlock()
+}
+
+// snapshot 返回当前组内所有池的副本，使后续操作不必持有锁。
+func (g *PoolGroup) snapshot() []Managed {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]Managed(nil), g.pools...)
+}
+
+// CloseAll 关闭组内的所有池，并返回遇到的第一个错误。
+// 即使某个池关闭失败，其余的池也都会被关闭。
+func (g *PoolGroup) CloseAll() error {
+	var first error
+	for _, p := range g.snapshot() {
+		if err := p.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// ClearAll 清空组内所有池的闲置对象，并返回遇到的第一个错误。
+func (g *PoolGroup) ClearAll() error {
+	var first error
+	for _, p := range g.snapshot() {
+		if err := p.Clear(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// CombinedStats 返回组内所有池的统计信息之和。
+func (g *PoolGroup) CombinedStats() Stats {
+	var s Stats
+	for _, p := range g.snapshot() {
+		s.add(p.Stats())
+	}
+	return s
+}
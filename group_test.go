@@ -0,0 +1,76 @@
+package gpool
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// TestPoolGroup_CloseAll 测试 CloseAll 关闭组内的每一个池，即使其中某个池关闭失败。
+func TestPoolGroup_CloseAll(t *testing.T) {
+	closeErr := errors.New("close failed")
+	closers := New(func() *closerObject {
+		return &closerObject{err: closeErr}
+	}, WithDeterministic[*closerObject]())
+	buffers := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer]())
+	plain := New(func() int {
+		return 0
+	})
+
+	var g PoolGroup
+	g.Add(closers)
+	g.Add(buffers)
+	g.Add(plain)
+
+	obj := closers.Get()
+	closers.Put(obj)
+
+	if err := g.CloseAll(); !errors.Is(err, closeErr) {
+		t.Fatalf("CloseAll 应该返回关闭失败的错误, 得到 %v", err)
+	}
+	if obj.closed != 1 {
+		t.Fatalf("闲置对象应该被关闭一次, 实际 %d 次", obj.closed)
+	}
+	for name, closed := range map[string]bool{
+		"closers": closers.isClosed(),
+		"buffers": buffers.isClosed(),
+		"plain":   plain.isClosed(),
+	} {
+		if !closed {
+			t.Errorf("池 %s 应该已被关闭", name)
+		}
+	}
+}
+
+// TestPoolGroup_ClearAllAndCombinedStats 测试 ClearAll 清空组内所有池，CombinedStats 汇总统计信息。
+func TestPoolGroup_ClearAllAndCombinedStats(t *testing.T) {
+	a := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer]())
+	b := New(func() *closerObject {
+		return &closerObject{}
+	}, WithDeterministic[*closerObject]())
+
+	var g PoolGroup
+	g.Add(a)
+	g.Add(b)
+
+	a.Put(a.Get())
+	b.Put(b.Get())
+	b.Get()
+
+	s := g.CombinedStats()
+	if s.Gets != 3 || s.Misses != 2 || s.Puts != 2 || s.Outstanding != 1 || s.Idle != 1 {
+		t.Fatalf("汇总的统计信息不正确, 得到 %+v", s)
+	}
+
+	if err := g.ClearAll(); err != nil {
+		t.Fatalf("ClearAll 返回了错误: %v", err)
+	}
+	s = g.CombinedStats()
+	if s.Idle != 0 || s.DiscardsByReason[DiscardCleared] != 1 {
+		t.Fatalf("ClearAll 之后不应该有闲置对象, 且应记录 1 次清空, 得到 %+v", s)
+	}
+}
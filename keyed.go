@@ -0,0 +1,140 @@
+package gpool
+
+import (
+	"container/list"
+	"sync"
+)
+
+// KeyedLRU 按键 K 为每个键维护一个独立的子池，并限制同时存在的键的数量：
+// 键的数量超过上限时，最久未被使用的子池会被淘汰并关闭。
+// 它适合键（例如租户）不断变化的场景，使内存占用不会随着出现过的键无限增长。
+//
+// 所有方法都是并发安全的。KeyedLRU 实现了 Managed，可以加入 PoolGroup。
+type KeyedLRU[K comparable, T any] struct {
+	maxKeys int
+	factory func(K) *Pool[T]
+
+	mu     sync.Mutex
+	pools  map[K]*list.Element // 值为 *keyedEntry[K, T]
+	lru    list.List           // 从前往后按最近使用到最久未使用排列
+	closed bool
+}
+
+// keyedEntry 是 KeyedLRU 中的一个子池。
+type keyedEntry[K comparable, T any] struct {
+	key  K
+	pool *Pool[T]
+}
+
+// NewKeyedLRU 创建一个最多同时保留 maxKeys 个子池的 KeyedLRU，
+// factory 在某个键第一次被使用（或被淘汰后再次被使用）时为它创建子池。
+// maxKeys <= 0 时不限制键的数量。
+func NewKeyedLRU[K comparable, T any](maxKeys int, factory func(K) *Pool[T]) *KeyedLRU[K, T] {
+	return &KeyedLRU[K, T]{
+		maxKeys: maxKeys,
+		factory: factory,
+		pools:   make(map[K]*list.Element),
+	}
+}
+
+// Pool 返回键 key 对应的子池，必要时通过 factory 创建它，并将它标记为最近使用。
+// 创建新的子池使键的数量超过上限时，最久未被使用的子池会被淘汰：它不再属于 KeyedLRU，并被 Close。
+//
+// 从子池中取出的对象应放回同一个子池：
+//
+//	p := k.Pool(tenant)
+//	x := p.Get()
+//	defer p.Put(x)
+//
+// 这样即使子池在此期间被淘汰，对象也会随着放回已关闭的池而被正确丢弃。
+// KeyedLRU 被 Close 之后，Pool 返回的是一个已经关闭且不被保留的新子池。
+func (k *KeyedLRU[K, T]) Pool(key K) *Pool[T] {
+	k.mu.Lock()
+	if e, ok := k.pools[key]; ok {
+		k.lru.MoveToFront(e)
+		k.mu.Unlock()
+		return e.Value.(*keyedEntry[K, T]).pool
+	}
+	if k.closed {
+		k.mu.Unlock()
+		p := k.factory(key)
+		p.Close()
+		return p
+	}
+
+	// 在锁内调用 factory，保证同一个键只会创建一个子池。
+	p := k.factory(key)
+	k.pools[key] = k.lru.PushFront(&keyedEntry[K, T]{key: key, pool: p})
+	var evicted []*Pool[T]
+	for k.maxKeys > 0 && k.lru.Len() > k.maxKeys {
+		e := k.lru.Remove(k.lru.Back()).(*keyedEntry[K, T])
+		delete(k.pools, e.key)
+		evicted = append(evicted, e.pool)
+	}
+	k.mu.Unlock()
+
+	// 在锁外关闭被淘汰的子池，避免关闭对象时阻塞其他键的访问。
+	for _, e := range evicted {
+		e.Close()
+	}
+	return p
+}
+
+// Len 返回当前保留的子池数量。
+func (k *KeyedLRU[K, T]) Len() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.lru.Len()
+}
+
+// snapshot 返回当前保留的所有子池。
+func (k *KeyedLRU[K, T]) snapshot() []*Pool[T] {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	pools := make([]*Pool[T], 0, k.lru.Len())
+	for e := k.lru.Front(); e != nil; e = e.Next() {
+		pools = append(pools, e.Value.(*keyedEntry[K, T]).pool)
+	}
+	return pools
+}
+
+// Clear 清空所有子池的闲置对象，并返回遇到的第一个错误。
+func (k *KeyedLRU[K, T]) Clear() error {
+	var first error
+	for _, p := range k.snapshot() {
+		if err := p.Clear(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Close 关闭并移除所有子池，并返回遇到的第一个错误。
+func (k *KeyedLRU[K, T]) Close() error {
+	k.mu.Lock()
+	k.closed = true
+	var pools []*Pool[T]
+	for e := k.lru.Front(); e != nil; e = e.Next() {
+		pools = append(pools, e.Value.(*keyedEntry[K, T]).pool)
+	}
+	k.pools = make(map[K]*list.Element)
+	k.lru.Init()
+	k.mu.Unlock()
+
+	var first error
+	for _, p := range pools {
+		if err := p.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Stats 返回当前保留的所有子池的统计信息之和。
+func (k *KeyedLRU[K, T]) Stats() Stats {
+	var s Stats
+	for _, p := range k.snapshot() {
+		s.add(p.Stats())
+	}
+	return s
+}
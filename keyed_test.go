@@ -0,0 +1,105 @@
+package gpool
+
+import (
+	"sync"
+	"testing"
+)
+
+// newKeyedCloserLRU 创建一个子池保存 closerObject 的 KeyedLRU，并记录创建过的所有子池。
+func newKeyedCloserLRU(maxKeys int) (*KeyedLRU[string, *closerObject], map[string][]*Pool[*closerObject]) {
+	created := make(map[string][]*Pool[*closerObject])
+	k := NewKeyedLRU(maxKeys, func(key string) *Pool[*closerObject] {
+		p := New(func() *closerObject {
+			return &closerObject{}
+		}, WithDeterministic[*closerObject]())
+		created[key] = append(created[key], p)
+		return p
+	})
+	return k, created
+}
+
+// TestKeyedLRU_Evict 测试键的数量超过上限时，最久未被使用的子池被淘汰并关闭。
+func TestKeyedLRU_Evict(t *testing.T) {
+	k, created := newKeyedCloserLRU(2)
+
+	a := k.Pool("a")
+	b := k.Pool("b")
+	x := b.Get()
+	b.Put(x)
+	if k.Pool("a") != a {
+		t.Fatal("同一个键应该返回同一个子池")
+	}
+
+	// a 刚被使用过，c 的加入使 b 被淘汰。
+	k.Pool("c")
+	if k.Len() != 2 {
+		t.Fatalf("期望保留 2 个子池, 实际 %d 个", k.Len())
+	}
+	if !b.isClosed() || a.isClosed() {
+		t.Fatal("期望最久未被使用的子池 b 被关闭, a 保持打开")
+	}
+	if x.closed != 1 {
+		t.Error("被淘汰的子池中的闲置对象应该被关闭")
+	}
+
+	// 被淘汰的键再次使用时会创建新的子池。
+	if nb := k.Pool("b"); nb == b || len(created["b"]) != 2 {
+		t.Fatal("被淘汰的键再次使用时应该创建新的子池")
+	}
+	if !a.isClosed() {
+		t.Error("b 的重新加入应该淘汰 a")
+	}
+}
+
+// TestKeyedLRU_Close 测试 Close 关闭所有子池，之后返回的子池都是已关闭的。
+func TestKeyedLRU_Close(t *testing.T) {
+	k, _ := newKeyedCloserLRU(0)
+	var g PoolGroup
+	g.Add(k)
+
+	a, b := k.Pool("a"), k.Pool("b")
+	a.Put(a.Get())
+	if s := g.CombinedStats(); s.Idle != 1 {
+		t.Fatalf("期望合计 1 个闲置对象, 得到 %+v", s)
+	}
+	if err := g.CloseAll(); err != nil {
+		t.Fatal(err)
+	}
+	if !a.isClosed() || !b.isClosed() || k.Len() != 0 {
+		t.Fatal("Close 应该关闭并移除所有子池")
+	}
+	if p := k.Pool("a"); !p.isClosed() || k.Len() != 0 {
+		t.Fatal("Close 之后返回的子池应该是已关闭且不被保留的")
+	}
+}
+
+// TestKeyedLRU_Concurrent 测试并发访问时同一个键只会创建一个子池，且键的数量不超过上限。
+func TestKeyedLRU_Concurrent(t *testing.T) {
+	var mu sync.Mutex
+	created := 0
+	k := NewKeyedLRU(4, func(key int) *Pool[*closerObject] {
+		mu.Lock()
+		created++
+		mu.Unlock()
+		return New(func() *closerObject {
+			return &closerObject{}
+		})
+	})
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				p := k.Pool(i % 4)
+				p.Put(p.Get())
+			}
+		}()
+	}
+	wg.Wait()
+
+	if created != 4 || k.Len() != 4 {
+		t.Fatalf("期望只创建并保留 4 个子池, 创建了 %d 个, 保留 %d 个", created, k.Len())
+	}
+}
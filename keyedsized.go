@@ -0,0 +1,195 @@
+package gpool
+
+import (
+	"container/list"
+	"io"
+	"sync"
+)
+
+// KeyedSized 按键 K 分别保存闲置对象，但所有键共享同一个内存预算：
+// 闲置对象的总大小（由 weigh 测得）超过预算时，闲置时间最长的对象会被淘汰，不论它属于哪个键。
+// 它适合多租户等场景，用一个统一的预算限制所有键保留的内存，而不必为每个键单独估算上限。
+//
+// Get 优先取出该键最近放回的对象，没有闲置对象时通过 factory 创建新对象。
+// 被淘汰、被 Clear 清空或在关闭后放回的对象，如果实现了 io.Closer 会被关闭。
+// 所有方法都是并发安全的。KeyedSized 实现了 Managed，可以加入 PoolGroup。
+type KeyedSized[K comparable, T any] struct {
+	maxBytes int64
+	weigh    func(T) int
+	factory  func(K) T
+
+	mu     sync.Mutex
+	idle   list.List             // 闲置对象，从前往后按放回时间从新到旧排列，值为 *sizedItem[K, T]
+	keys   map[K][]*list.Element // 每个键的闲置对象，从旧到新排列
+	bytes  int64                 // 闲置对象的总大小
+	stats  Stats                 // 除 Idle 和 Outstanding 以外的统计信息
+	closed bool
+}
+
+// sizedItem 是 KeyedSized 中的一个闲置对象。
+type sizedItem[K comparable, T any] struct {
+	key    K
+	x      T
+	weight int64
+}
+
+// NewKeyedSized 创建一个闲置对象总大小最多为 maxTotalBytes 字节的 KeyedSized，
+// weigh 返回对象占用的字节数，factory 为键创建新对象。maxTotalBytes <= 0 时不限制总大小。
+func NewKeyedSized[K comparable, T any](maxTotalBytes int, weigh func(T) int, factory func(K) T) *KeyedSized[K, T] {
+	return &KeyedSized[K, T]{
+		maxBytes: int64(maxTotalBytes),
+		weigh:    weigh,
+		factory:  factory,
+		keys:     make(map[K][]*list.Element),
+	}
+}
+
+// Get 取出键 key 最近放回的闲置对象，没有闲置对象时通过 factory 创建新对象。
+// 取出的对象应当用同一个键放回。
+func (k *KeyedSized[K, T]) Get(key K) T {
+	k.mu.Lock()
+	k.stats.Gets++
+	if items := k.keys[key]; len(items) > 0 {
+		e := items[len(items)-1]
+		// 清空槽位，避免底层数组继续引用已取出的对象。
+		items[len(items)-1] = nil
+		k.setItems(key, items[:len(items)-1])
+		it := k.idle.Remove(e).(*sizedItem[K, T])
+		k.bytes -= it.weight
+		k.mu.Unlock()
+		return it.x
+	}
+	k.stats.Misses++
+	k.mu.Unlock()
+	return k.factory(key)
+}
+
+// Put 以键 key 放回对象 x。放回使闲置对象的总大小超过预算时，闲置时间最长的对象会被淘汰，
+// 如果 x 本身就超过了预算，它也会被立即淘汰。KeyedSized 被关闭后，放回的对象会被丢弃。
+func (k *KeyedSized[K, T]) Put(key K, x T) {
+	w := int64(k.weigh(x))
+	k.mu.Lock()
+	k.stats.Puts++
+	if k.closed {
+		k.countDiscard(DiscardClosed)
+		k.mu.Unlock()
+		closeObject(x)
+		return
+	}
+	e := k.idle.PushFront(&sizedItem[K, T]{key: key, x: x, weight: w})
+	k.keys[key] = append(k.keys[key], e)
+	k.bytes += w
+	var evicted []T
+	for k.maxBytes > 0 && k.bytes > k.maxBytes {
+		evicted = append(evicted, k.evictOldest())
+	}
+	k.mu.Unlock()
+
+	// 在锁外关闭被淘汰的对象，避免关闭对象时阻塞其他键的访问。
+	for _, x := range evicted {
+		closeObject(x)
+	}
+}
+
+// evictOldest 淘汰闲置时间最长的对象并返回它，调用方必须持有 k.mu，且至少有一个闲置对象。
+func (k *KeyedSized[K, T]) evictOldest() T {
+	it := k.idle.Remove(k.idle.Back()).(*sizedItem[K, T])
+	// 闲置时间最长的对象也是它所属的键中最旧的对象。把其余对象前移并清空最后的槽位，
+	// 而不是重新切片，避免底层数组继续引用被淘汰的对象。
+	items := k.keys[it.key]
+	n := copy(items, items[1:])
+	items[n] = nil
+	k.setItems(it.key, items[:n])
+	k.bytes -= it.weight
+	k.countDiscard(DiscardOverflow)
+	return it.x
+}
+
+// setItems 更新键 key 的闲置对象列表，列表为空时删除这个键，调用方必须持有 k.mu。
+func (k *KeyedSized[K, T]) setItems(key K, items []*list.Element) {
+	if len(items) == 0 {
+		delete(k.keys, key)
+		return
+	}
+	k.keys[key] = items
+}
+
+// countDiscard 为丢弃原因 reason 计数，调用方必须持有 k.mu。
+func (k *KeyedSized[K, T]) countDiscard(reason string) {
+	if k.stats.DiscardsByReason == nil {
+		k.stats.DiscardsByReason = make(map[string]int64)
+	}
+	k.stats.DiscardsByReason[reason]++
+	k.stats.Discards++
+}
+
+// Bytes 返回当前闲置对象的总大小。
+func (k *KeyedSized[K, T]) Bytes() int64 {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.bytes
+}
+
+// drain 取出所有闲置对象，并按 reason 为它们计数。
+func (k *KeyedSized[K, T]) drain(reason string) []T {
+	k.mu.Lock()
+	xs := make([]T, 0, k.idle.Len())
+	for e := k.idle.Front(); e != nil; e = e.Next() {
+		xs = append(xs, e.Value.(*sizedItem[K, T]).x)
+		k.countDiscard(reason)
+	}
+	k.idle.Init()
+	k.keys = make(map[K][]*list.Element)
+	k.bytes = 0
+	k.mu.Unlock()
+	return xs
+}
+
+// Clear 丢弃所有闲置对象，并返回关闭对象时遇到的第一个错误。
+func (k *KeyedSized[K, T]) Clear() error {
+	return closeAll(k.drain(DiscardCleared))
+}
+
+// Close 丢弃所有闲置对象，之后放回的对象也都会被丢弃，并返回关闭对象时遇到的第一个错误。
+func (k *KeyedSized[K, T]) Close() error {
+	k.mu.Lock()
+	k.closed = true
+	k.mu.Unlock()
+	return closeAll(k.drain(DiscardClosed))
+}
+
+// Stats 返回所有键的统计信息之和。
+func (k *KeyedSized[K, T]) Stats() Stats {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	s := k.stats
+	if k.stats.DiscardsByReason != nil {
+		s.DiscardsByReason = make(map[string]int64, len(k.stats.DiscardsByReason))
+		for r, n := range k.stats.DiscardsByReason {
+			s.DiscardsByReason[r] = n
+		}
+	}
+	s.Hits = s.Gets - s.Misses
+	s.Outstanding = s.Gets - s.Puts
+	s.Idle = int64(k.idle.Len())
+	return s
+}
+
+// closeObject 在 x 实现了 io.Closer 时关闭它。
+func closeObject[T any](x T) error {
+	if c, ok := any(x).(io.Closer); ok && !isNil(x) {
+		return c.Close()
+	}
+	return nil
+}
+
+// closeAll 关闭 xs 中实现了 io.Closer 的对象，并返回第一个错误。
+func closeAll[T any](xs []T) error {
+	var first error
+	for _, x := range xs {
+		if err := closeObject(x); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
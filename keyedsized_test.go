@@ -0,0 +1,178 @@
+package gpool
+
+import (
+	"sync"
+	"testing"
+)
+
+// sizedObject 是 KeyedSized 测试使用的对象，size 是它占用的字节数。
+type sizedObject struct {
+	closerObject
+	key  string
+	size int
+}
+
+// newKeyedSizedObjects 创建一个保存 sizedObject 的 KeyedSized，新对象的大小都为 size。
+func newKeyedSizedObjects(maxBytes, size int) *KeyedSized[string, *sizedObject] {
+	return NewKeyedSized(maxBytes, func(x *sizedObject) int {
+		return x.size
+	}, func(key string) *sizedObject {
+		return &sizedObject{key: key, size: size}
+	})
+}
+
+// TestKeyedSized_ReleasesSlots 测试取出和淘汰的对象不会继续被键的闲置列表的底层数组引用，可以被 GC 回收。
+func TestKeyedSized_ReleasesSlots(t *testing.T) {
+	k := newKeyedSizedObjects(30, 10)
+	held := func(key string) int {
+		items := k.keys[key]
+		n := 0
+		for _, e := range items[:cap(items)] {
+			if e != nil {
+				n++
+			}
+		}
+		return n
+	}
+
+	a1, a2, a3 := k.Get("a"), k.Get("a"), k.Get("a")
+	k.Put("a", a1)
+	k.Put("a", a2)
+	k.Put("a", a3)
+	k.Get("a")
+	if n := held("a"); n != 2 {
+		t.Fatalf("取出对象后底层数组应该只引用 2 个闲置对象, 得到 %d", n)
+	}
+
+	k.Put("b", &sizedObject{key: "b", size: 20}) // 淘汰 a1
+	if a1.closed != 1 {
+		t.Fatal("期望闲置时间最长的 a1 被淘汰")
+	}
+	if n := held("a"); n != 1 {
+		t.Fatalf("淘汰对象后底层数组应该只引用 1 个闲置对象, 得到 %d", n)
+	}
+}
+
+// TestKeyedSized_Evict 测试闲置对象的总大小超过预算时，所有键中闲置时间最长的对象最先被淘汰。
+func TestKeyedSized_Evict(t *testing.T) {
+	k := newKeyedSizedObjects(30, 10)
+
+	a1, b1, a2 := k.Get("a"), k.Get("b"), k.Get("a")
+	k.Put("a", a1)
+	k.Put("b", b1)
+	k.Put("a", a2)
+	if k.Bytes() != 30 {
+		t.Fatalf("期望闲置 30 字节, 实际 %d 字节", k.Bytes())
+	}
+
+	// c1 使总大小超过预算，最早放回的 a1 被淘汰，即使 a 还有其他闲置对象。
+	c1 := k.Get("c")
+	k.Put("c", c1)
+	if a1.closed != 1 || b1.closed != 0 || a2.closed != 0 {
+		t.Fatal("期望所有键中闲置时间最长的 a1 被淘汰并关闭")
+	}
+	if k.Bytes() != 30 {
+		t.Fatalf("淘汰后期望闲置 30 字节, 实际 %d 字节", k.Bytes())
+	}
+
+	// 一个大对象需要淘汰多个旧对象才能放入。
+	big := &sizedObject{key: "c", size: 20}
+	k.Put("c", big)
+	if b1.closed != 1 || a2.closed != 1 || c1.closed != 0 {
+		t.Fatal("期望按闲置时间从旧到新淘汰 b1 和 a2")
+	}
+	if k.Bytes() != 30 {
+		t.Fatalf("期望闲置 30 字节, 实际 %d 字节", k.Bytes())
+	}
+
+	// Get 取出该键最近放回的对象，其他键的对象不受影响。
+	if x := k.Get("c"); x != big {
+		t.Fatal("期望取出 c 最近放回的对象")
+	}
+	if x := k.Get("c"); x != c1 {
+		t.Fatal("期望取出 c 剩下的闲置对象")
+	}
+	if x := k.Get("a"); x.closed != 0 || x == a1 || x == a2 {
+		t.Fatal("a 的闲置对象都已被淘汰，期望创建新对象")
+	}
+
+	s := k.Stats()
+	if s.Discards != 3 || s.DiscardsByReason[DiscardOverflow] != 3 || s.Idle != 0 || k.Bytes() != 0 {
+		t.Fatalf("期望 3 个对象因超出预算被丢弃且没有闲置对象, 得到 %+v", s)
+	}
+}
+
+// TestKeyedSized_Oversized 测试单个超过预算的对象放回后立即被淘汰。
+func TestKeyedSized_Oversized(t *testing.T) {
+	k := newKeyedSizedObjects(10, 10)
+	x := k.Get("a")
+	k.Put("a", x)
+
+	huge := &sizedObject{key: "b", size: 11}
+	k.Put("b", huge)
+	if huge.closed != 1 || x.closed != 1 || k.Bytes() != 0 {
+		t.Fatal("期望超过预算的对象和为它腾出空间的对象都被淘汰")
+	}
+}
+
+// TestKeyedSized_Close 测试 Clear 和 Close 丢弃所有闲置对象，Close 之后放回的对象也被丢弃。
+func TestKeyedSized_Close(t *testing.T) {
+	k := newKeyedSizedObjects(0, 10)
+	var g PoolGroup
+	g.Add(k)
+
+	a, b := k.Get("a"), k.Get("b")
+	k.Put("a", a)
+	k.Put("b", b)
+	if s := g.CombinedStats(); s.Idle != 2 || k.Bytes() != 20 {
+		t.Fatalf("期望合计 2 个闲置对象, 得到 %+v", s)
+	}
+	if err := k.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if a.closed != 1 || b.closed != 1 || k.Bytes() != 0 {
+		t.Fatal("Clear 应该关闭所有闲置对象")
+	}
+
+	c := k.Get("c")
+	k.Put("c", c)
+	if err := g.CloseAll(); err != nil {
+		t.Fatal(err)
+	}
+	x := k.Get("c")
+	k.Put("c", x)
+	if c.closed != 1 || x.closed != 1 || k.Bytes() != 0 {
+		t.Fatal("Close 应该关闭闲置对象以及之后放回的对象")
+	}
+	if s := k.Stats(); s.DiscardsByReason[DiscardCleared] != 2 || s.DiscardsByReason[DiscardClosed] != 2 {
+		t.Fatalf("期望 2 个对象被清空、2 个对象因关闭被丢弃, 得到 %+v", s)
+	}
+}
+
+// TestKeyedSized_Concurrent 测试并发访问时闲置对象的总大小始终不超过预算。
+func TestKeyedSized_Concurrent(t *testing.T) {
+	k := newKeyedSizedObjects(50, 10)
+	keys := []string{"a", "b", "c", "d"}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				key := keys[(g+i)%len(keys)]
+				x := k.Get(key)
+				if x.key != key {
+					t.Errorf("键 %s 取出了属于 %s 的对象", key, x.key)
+					return
+				}
+				k.Put(key, x)
+				if n := k.Bytes(); n > 50 {
+					t.Errorf("闲置 %d 字节, 超过了 50 字节的预算", n)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,155 @@
+package gpool
+
+import (
+	"context"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// leakStackDepth 是记录借出位置时保留的最大调用栈深度。
+const leakStackDepth = 32
+
+// OutstandingInfo 描述一个在池关闭时仍未放回的对象。
+type OutstandingInfo struct {
+	// Object 是未放回的对象。
+	Object any
+	// AcquiredAt 是对象被借出的时间，Age 是从借出到关闭池经过的时间。
+	AcquiredAt time.Time
+	Age        time.Duration
+	// Stack 是借出对象时的调用栈，每行一个 "函数名\n\t文件:行号" 形式的栈帧。
+	Stack string
+	// TraceID 是通过 GetTraced 借出对象时从 context 中提取的追踪标识，其他方式借出时为空。
+	TraceID string
+}
+
+// CloseWithReport 与 Close 相同，但同时返回仍被借出（已 Get 但尚未 Put 或 Detach）的对象的信息，
+// 按借出时间从早到晚排序，使关闭池时可以报告哪些对象泄漏了。
+//
+// 跟踪借出的对象需要在每次借出时记录调用栈，因此只在 WithDebug 启用的调试模式下进行，
+// 并且要求 T 可以比较（不是接口类型）；其他情况下 CloseWithReport 不产生额外开销，报告总是 nil。
+// 重复调用时，之后的调用与 Close 一样直接返回 nil。
+func (p *Pool[T]) CloseWithReport() ([]OutstandingInfo, error) {
+	if p.isClosed() {
+		return nil, nil
+	}
+	var report []OutstandingInfo
+	if p.leaks != nil {
+		report = p.leaks.report()
+	}
+	return report, p.Close()
+}
+
+// WithTraceExtractor 设置从 context 中提取追踪标识（例如 trace id 或 span id）的函数，供 GetTraced 使用。
+// 调试模式下，通过 GetTraced 借出的对象会记录提取到的标识，CloseWithReport 的报告据此把泄漏的对象归因到具体的请求。
+func WithTraceExtractor[T any](fn func(ctx context.Context) string) Option[T] {
+	return func(c *config[T]) {
+		c.traceID = fn
+	}
+}
+
+// GetTraced 与 Get 相同，但在调试模式下会用 WithTraceExtractor 设置的函数从 ctx 中提取追踪标识，
+// 并把它与借出的对象一起记录下来，使 CloseWithReport 报告的泄漏对象可以关联到借出它的请求。
+//
+// ctx 只用于提取追踪标识，不影响等待：与 Get 一样，有界池的 GetTraced 会一直等待到有额度为止。
+// 未启用调试模式或没有设置提取函数时，GetTraced 等同于 Get。
+func (p *Pool[T]) GetTraced(ctx context.Context) T {
+	x := p.Get()
+	if p.leaks != nil && p.cfg.traceID != nil {
+		if id := p.cfg.traceID(ctx); id != "" {
+			p.leaks.traced(x, id)
+		}
+	}
+	return x
+}
+
+// leakTracker 记录调试模式下借出的对象及其借出位置。
+type leakTracker struct {
+	mu  sync.Mutex
+	out map[any]borrowRecord
+}
+
+// borrowRecord 是一次借出的时间、调用栈和追踪标识。
+type borrowRecord struct {
+	at    time.Time
+	pcs   []uintptr
+	trace string
+}
+
+// newLeakTracker 在调试模式下为可以比较的 T 创建一个 leakTracker，否则返回 nil。
+func newLeakTracker[T any](debug bool) *leakTracker {
+	if !debug || !hasIdentity[T]() {
+		return nil
+	}
+	return &leakTracker{out: make(map[any]borrowRecord)}
+}
+
+// borrowed 记录对象 x 被借出。记录的调用栈从 Pool.borrowed 的调用方开始。
+func (t *leakTracker) borrowed(x any) {
+	pcs := make([]uintptr, leakStackDepth)
+	// 跳过 runtime.Callers、leakTracker.borrowed 和 Pool.borrowed 三帧。
+	pcs = pcs[:runtime.Callers(3, pcs)]
+	t.mu.Lock()
+	t.out[x] = borrowRecord{at: time.Now(), pcs: pcs}
+	t.mu.Unlock()
+}
+
+// traced 为仍在跟踪的对象 x 记录追踪标识。
+func (t *leakTracker) traced(x any, id string) {
+	t.mu.Lock()
+	if r, ok := t.out[x]; ok {
+		r.trace = id
+		t.out[x] = r
+	}
+	t.mu.Unlock()
+}
+
+// returned 停止跟踪对象 x。
+func (t *leakTracker) returned(x any) {
+	t.mu.Lock()
+	delete(t.out, x)
+	t.mu.Unlock()
+}
+
+// report 返回所有仍被借出的对象的信息，按借出时间排序。
+func (t *leakTracker) report() []OutstandingInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.out) == 0 {
+		return nil
+	}
+	now := time.Now()
+	infos := make([]OutstandingInfo, 0, len(t.out))
+	for x, r := range t.out {
+		infos = append(infos, OutstandingInfo{
+			Object:     x,
+			AcquiredAt: r.at,
+			Age:        now.Sub(r.at),
+			Stack:      formatStack(r.pcs),
+			TraceID:    r.trace,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].AcquiredAt.Before(infos[j].AcquiredAt) })
+	return infos
+}
+
+// formatStack 把程序计数器格式化为与 panic 输出类似的调用栈文本。
+func formatStack(pcs []uintptr) string {
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, more := frames.Next()
+		b.WriteString(f.Function)
+		b.WriteString("\n\t")
+		b.WriteString(f.File)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(f.Line))
+		b.WriteByte('\n')
+		if !more {
+			return b.String()
+		}
+	}
+}
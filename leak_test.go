@@ -0,0 +1,101 @@
+package gpool
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// leakyHandler 借出一个对象后不放回，用于检查报告中记录的借出位置。
+func leakyHandler(p *Pool[*bytes.Buffer]) *bytes.Buffer {
+	return p.Get()
+}
+
+// TestPool_CloseWithReport 测试调试模式下 CloseWithReport 列出所有未放回的对象及其借出时间和调用栈。
+func TestPool_CloseWithReport(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer](), WithDebug[*bytes.Buffer]())
+
+	first := leakyHandler(p)
+	time.Sleep(time.Millisecond)
+	second := p.Get()
+	p.Put(p.Get())    // 已放回的对象不在报告中
+	p.Detach(p.Get()) // 分离的对象也不在报告中
+	got := p.GetAll(1)[0]
+
+	report, err := p.CloseWithReport()
+	if err != nil {
+		t.Fatalf("CloseWithReport 返回了错误: %v", err)
+	}
+	if len(report) != 3 {
+		t.Fatalf("期望报告 3 个未放回的对象, 得到 %d 个", len(report))
+	}
+	if report[0].Object != first || report[1].Object != second || report[2].Object != got {
+		t.Error("报告应该按借出时间从早到晚排序")
+	}
+	if report[0].Age < time.Millisecond || report[0].Age < report[1].Age {
+		t.Errorf("Age 应该是从借出到关闭经过的时间, 得到 %v 和 %v", report[0].Age, report[1].Age)
+	}
+	if !strings.Contains(report[0].Stack, "leakyHandler") || !strings.Contains(report[0].Stack, "leak_test.go:") {
+		t.Errorf("调用栈应该包含借出对象的函数, 得到:\n%s", report[0].Stack)
+	}
+
+	if report, err := p.CloseWithReport(); report != nil || err != nil {
+		t.Error("重复调用应该直接返回 nil")
+	}
+}
+
+// TestPool_CloseWithReport_Disabled 测试未启用调试模式时不跟踪借出的对象，报告为 nil。
+func TestPool_CloseWithReport_Disabled(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer]())
+	p.Get()
+
+	if p.leaks != nil {
+		t.Error("未启用调试模式时不应跟踪借出的对象")
+	}
+	if report, err := p.CloseWithReport(); report != nil || err != nil {
+		t.Errorf("未启用调试模式时报告应该为 nil, 得到 %v, %v", report, err)
+	}
+}
+
+// traceKey 是测试中保存追踪标识的 context 键。
+type traceKey struct{}
+
+// TestPool_GetTraced 测试通过 GetTraced 借出的对象在泄漏报告中带有借出时 context 中的追踪标识。
+func TestPool_GetTraced(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer](), WithDebug[*bytes.Buffer](),
+		WithTraceExtractor[*bytes.Buffer](func(ctx context.Context) string {
+			id, _ := ctx.Value(traceKey{}).(string)
+			return id
+		}))
+
+	leaked := p.GetTraced(context.WithValue(context.Background(), traceKey{}, "req-42"))
+	p.Put(p.GetTraced(context.WithValue(context.Background(), traceKey{}, "req-43")))
+	plain := p.GetTraced(context.Background())
+
+	report, _ := p.CloseWithReport()
+	if len(report) != 2 {
+		t.Fatalf("期望报告 2 个未放回的对象, 得到 %d 个", len(report))
+	}
+	for _, info := range report {
+		switch info.Object {
+		case leaked:
+			if info.TraceID != "req-42" {
+				t.Errorf("泄漏的对象应该带有追踪标识 req-42, 得到 %q", info.TraceID)
+			}
+		case plain:
+			if info.TraceID != "" {
+				t.Errorf("没有追踪标识的 context 不应记录标识, 得到 %q", info.TraceID)
+			}
+		default:
+			t.Error("报告中出现了已放回的对象")
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package gpool
+
+import "log"
+
+// Logger 是池输出诊断信息所用的日志接口，*log.Logger 满足该接口。
+type Logger interface {
+	Printf(format string, v ...any)
+}
+
+// logger 返回池使用的 Logger，未通过 WithLogger 设置时使用标准库的默认 Logger。
+func (p *Pool[T]) logger() Logger {
+	if p.cfg.logger != nil {
+		return p.cfg.logger
+	}
+	return log.Default()
+}
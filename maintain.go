@@ -0,0 +1,148 @@
+package gpool
+
+import (
+	"sync"
+	"time"
+)
+
+// maintainInterval 是后台维护 goroutine 的运行间隔。
+const maintainInterval = time.Second
+
+// maintainer 是池的后台维护 goroutine，定期执行自动伸缩、替换过大的闲置对象等需要周期运行的任务。
+type maintainer struct {
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// startMaintainer 在池配置了需要周期运行的任务时启动后台维护 goroutine。
+func (p *Pool[T]) startMaintainer() {
+	p.maint = nil
+	if p.scaler == nil && !p.replaces() && !p.watermarked() && !p.compacts() && !p.expires() && !p.idleTimeouts() {
+		return
+	}
+	m := &maintainer{stop: make(chan struct{}), done: make(chan struct{})}
+	p.maint = m
+	go func() {
+		defer close(m.done)
+		t := time.NewTicker(maintainInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-m.stop:
+				return
+			case <-t.C:
+				p.maintain()
+			}
+		}
+	}()
+}
+
+// stopMaintainer 停止后台维护 goroutine 并等待它退出。重复调用是安全的。
+func (p *Pool[T]) stopMaintainer() {
+	m := p.maint
+	if m == nil {
+		return
+	}
+	m.once.Do(func() { close(m.stop) })
+	<-m.done
+}
+
+// maintain 执行一轮后台维护。
+func (p *Pool[T]) maintain() {
+	if p.scaler != nil {
+		p.scaler.step()
+	}
+	if p.replaces() {
+		p.replaceOversized()
+	}
+	if p.watermarked() {
+		p.keepWatermarks()
+	}
+	if p.expires() || p.idleTimeouts() {
+		p.expireIdle()
+	}
+	if p.compacts() {
+		baseStore(p.store).(compacter).compact(false)
+	}
+}
+
+// compacts 报告池是否需要通过 WithAutoCompact 定期收缩底层数组。
+func (p *Pool[T]) compacts() bool {
+	if !p.cfg.autoCompact || p.cfg.backend == backendFixed {
+		return false
+	}
+	_, ok := baseStore(p.store).(compacter)
+	return ok
+}
+
+// replaces 报告池是否需要通过 WithProactiveReplace 替换过大的闲置对象。
+func (p *Pool[T]) replaces() bool {
+	return p.cfg.softThreshold > 0 && p.cfg.measure != nil && p.store != nil
+}
+
+// replaceOversized 把大小达到 WithProactiveReplace 阈值的闲置对象换成新建的对象。
+func (p *Pool[T]) replaceOversized() {
+	if p.isClosed() {
+		return
+	}
+	replace := p.sweep(func(x T) bool {
+		return p.cfg.measure(x) >= p.cfg.softThreshold
+	}, DiscardOversized)
+	for i := 0; i < replace; i++ {
+		x, err := p.tryConstruct()
+		if err != nil {
+			break
+		}
+		p.put(x)
+	}
+}
+
+// expireIdle 丢弃所有按 WithTTL 过期或超过 WithIdleTimeout 设置的时间一直闲置的对象。
+func (p *Pool[T]) expireIdle() {
+	if p.isClosed() {
+		return
+	}
+	p.sweep(p.stale, DiscardExpired)
+}
+
+// sweep 以 reason 为原因丢弃 drop 返回 true 的闲置对象，并返回丢弃的数量。
+// 对象在存储中原地筛选，其余对象一直留在存储中，并发的 Get 和 Put 不会看到一个被临时清空的池。
+// drop 可能在持有存储的锁时被调用，它不得访问池。
+func (p *Pool[T]) sweep(drop func(x T) bool, reason string) int {
+	dropped := p.store.filter(drop)
+	p.discardAll(dropped, reason)
+	return len(dropped)
+}
+
+// watermarked 报告池是否需要把闲置数量保持在 WithWatermarks 设置的区间内。
+func (p *Pool[T]) watermarked() bool {
+	return (p.cfg.lowWater > 0 || p.cfg.highWater > 0) && p.store != nil
+}
+
+// keepWatermarks 把闲置数量移回 WithWatermarks 设置的区间：低于下限时补充到下限，高于上限时裁剪到上限。
+// 它只移动到区间的边界，因此放回和取出造成的小幅波动不会引起反复的创建和丢弃。
+func (p *Pool[T]) keepWatermarks() {
+	if p.isClosed() {
+		return
+	}
+	idle := p.store.len()
+	// 补充时不超过池能保留的闲置数量，否则多出的对象会被存储拒绝，每一轮都白白创建。
+	for low := p.idleLimit(p.cfg.lowWater); idle < low; idle++ {
+		x, err := p.tryConstruct()
+		if err != nil {
+			break
+		}
+		p.put(x)
+	}
+	if p.cfg.highWater <= 0 {
+		return
+	}
+	for ; idle > p.cfg.highWater; idle-- {
+		x, ok := p.store.get()
+		if !ok {
+			break
+		}
+		p.discard(x, DiscardOverflow)
+	}
+}
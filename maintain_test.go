@@ -0,0 +1,145 @@
+package gpool
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestPool_ProactiveReplace 测试后台维护把大小达到阈值的闲置对象换成新对象，较小的对象保持不变。
+func TestPool_ProactiveReplace(t *testing.T) {
+	var discarded []string
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer](),
+		WithMeasure(func(b *bytes.Buffer) int { return b.Cap() }),
+		WithProactiveReplace[*bytes.Buffer](1024),
+		WithOnDiscard(func(_ *bytes.Buffer, reason string) {
+			discarded = append(discarded, reason)
+		}))
+	defer p.Close()
+
+	small := bytes.NewBuffer(make([]byte, 0, 64))
+	big := bytes.NewBuffer(make([]byte, 0, 4096))
+	p.Put(small)
+	p.Put(big)
+
+	p.maintain()
+
+	if len(discarded) != 1 || discarded[0] != DiscardOversized {
+		t.Errorf("只有过大的对象应该以 DiscardOversized 被丢弃, 得到 %v", discarded)
+	}
+	if s := p.Stats(); s.Idle != 2 || s.Misses != 0 {
+		t.Errorf("过大的对象应该被替换而闲置数量不变, 且替换不计入 Misses: %+v", s)
+	}
+	a, b := p.Get(), p.Get()
+	if a == big || b == big {
+		t.Error("过大的对象不应该留在池中")
+	}
+	if a != small && b != small {
+		t.Error("较小的对象应该保留在池中")
+	}
+	if got := p.Config().ProactiveReplace; got != 1024 {
+		t.Errorf("Config 应该报告替换阈值 1024, 得到 %d", got)
+	}
+}
+
+// TestPool_ProactiveReplace_NoMeasure 测试未设置 WithMeasure 时不会启动后台维护。
+func TestPool_ProactiveReplace_NoMeasure(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer](), WithProactiveReplace[*bytes.Buffer](1024))
+	defer p.Close()
+
+	if p.maint != nil || p.Config().ProactiveReplace != 0 {
+		t.Error("未设置 WithMeasure 时 WithProactiveReplace 不应生效")
+	}
+}
+
+// TestPool_Watermarks 测试后台维护把低于下限的闲置数量补充到下限、把高于上限的裁剪到上限，区间内保持不变。
+func TestPool_Watermarks(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithWatermarks[*bytes.Buffer](2, 4))
+	defer p.Close()
+
+	idle := func() int64 { return p.Stats().Idle }
+	p.maintain()
+	if got := idle(); got != 2 {
+		t.Fatalf("低于下限时期望补充到 2 个闲置对象, 得到 %d", got)
+	}
+	if s := p.Stats(); s.Misses != 0 {
+		t.Errorf("补充的对象不应该计入 Misses: %+v", s)
+	}
+
+	p.Put(new(bytes.Buffer)) // 区间内
+	p.maintain()
+	if got := idle(); got != 3 {
+		t.Fatalf("区间内的闲置数量不应该改变, 得到 %d", got)
+	}
+
+	p.PutAll([]*bytes.Buffer{new(bytes.Buffer), new(bytes.Buffer), new(bytes.Buffer)})
+	p.maintain()
+	if s := p.Stats(); s.Idle != 4 || s.DiscardsByReason[DiscardOverflow] != 2 {
+		t.Fatalf("高于上限时期望裁剪到 4 个并丢弃 2 个, 得到 %+v", s)
+	}
+	p.maintain()
+	if got := idle(); got != 4 {
+		t.Errorf("到达上限后再次维护不应该继续裁剪, 得到 %d", got)
+	}
+
+	p.GetAll(4)
+	p.maintain()
+	if got := idle(); got != 2 {
+		t.Errorf("闲置对象被取空后期望补充到 2 个, 得到 %d", got)
+	}
+	if c := p.Config(); c.Backend != BackendDeterministic || c.WatermarkLow != 2 || c.WatermarkHigh != 4 {
+		t.Errorf("Config 不符合预期: %+v", c)
+	}
+}
+
+// TestPool_MinIdle 测试后台维护在对象被取走后把闲置数量补充回下限，并在 Stop 之后退出。
+func TestPool_MinIdle(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithMinIdle[*bytes.Buffer](3))
+	defer p.Close()
+
+	p.maintain()
+	if s := p.Stats(); s.Idle != 3 || s.Misses != 0 {
+		t.Fatalf("期望补充到 3 个闲置对象且不计入 Misses, 得到 %+v", s)
+	}
+	p.GetAll(2)
+	p.maintain()
+	if got := p.Stats().Idle; got != 3 {
+		t.Fatalf("需求高峰之后期望补充回 3 个闲置对象, 得到 %d", got)
+	}
+	p.PutAll([]*bytes.Buffer{new(bytes.Buffer), new(bytes.Buffer)})
+	p.maintain()
+	if got := p.Stats().Idle; got != 5 {
+		t.Errorf("WithMinIdle 不应该裁剪多出的闲置对象, 得到 %d", got)
+	}
+	if c := p.Config(); c.WatermarkLow != 3 || c.WatermarkHigh != 0 {
+		t.Errorf("Config 不符合预期: %+v", c)
+	}
+
+	p.Stop()
+	select {
+	case <-p.maint.done:
+	default:
+		t.Error("Stop 之后后台维护 goroutine 应该已经退出")
+	}
+}
+
+// TestPool_Watermarks_Fixed 测试固定容量的后端补充时不超过它的容量。
+func TestPool_Watermarks_Fixed(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithCapacity[*bytes.Buffer](3), WithWatermarks[*bytes.Buffer](8, 0))
+	defer p.Close()
+
+	p.maintain()
+	p.maintain()
+	if s := p.Stats(); s.Idle != 3 || s.Discards != 0 {
+		t.Errorf("期望补充到容量 3 且不丢弃任何对象, 得到 %+v", s)
+	}
+}
@@ -0,0 +1,117 @@
+package gpool
+
+import "sync/atomic"
+
+// WithMaxIdle 限制池中闲置对象的数量最多为 n 个：闲置对象已达上限时，之后放回的对象以 DiscardOverflow 为原因被丢弃，
+// 并计入 Stats 的 DiscardsByReason，这样在 Get 变慢的过载场景下池保留的内存不会无限增长，而 Get 仍然复用已保留的对象。
+//
+// 与只适用于固定容量后端的 WithCapacity 不同，WithMaxIdle 可以与分片、弱引用等任意自己持有闲置对象的后端组合；
+// 未指定其他后端时会改用确定性后端。上限包括 WithGoroutineAffinity 缓存中的对象。
+// 对于弱引用后端，被 GC 回收的对象直到上限被触及时才会从计数中扣除。n <= 0 表示不限制。
+func WithMaxIdle[T any](n int) Option[T] {
+	return func(c *config[T]) {
+		c.maxIdle = n
+		if n > 0 {
+			c.needStore = true
+		}
+	}
+}
+
+// capped 限制它包装的存储中闲置对象的数量。
+type capped[T any] struct {
+	inner   store[T]
+	max     int64
+	n       int64 // 闲置对象数量，放入前预留，使用原子操作访问
+	recount bool  // inner 中的对象可能在不经过 get 的情况下消失（弱引用后端），达到上限时需要重新计数
+}
+
+func newCapped[T any](inner store[T], max int, recount bool) *capped[T] {
+	return &capped[T]{inner: inner, max: int64(max), recount: recount}
+}
+
+func (c *capped[T]) get() (T, bool) {
+	x, ok := c.inner.get()
+	if ok {
+		atomic.AddInt64(&c.n, -1)
+	}
+	return x, ok
+}
+
+func (c *capped[T]) put(x T) bool {
+	if atomic.AddInt64(&c.n, 1) > c.max {
+		atomic.AddInt64(&c.n, -1)
+		if !c.recount {
+			return false
+		}
+		// 重新统计实际的闲置数量，扣除已被 GC 回收的对象后再尝试一次。
+		atomic.StoreInt64(&c.n, int64(c.inner.len()))
+		if atomic.AddInt64(&c.n, 1) > c.max {
+			atomic.AddInt64(&c.n, -1)
+			return false
+		}
+	}
+	if !c.inner.put(x) {
+		atomic.AddInt64(&c.n, -1)
+		return false
+	}
+	return true
+}
+
+func (c *capped[T]) len() int {
+	return c.inner.len()
+}
+
+func (c *capped[T]) drain() []T {
+	items := c.inner.drain()
+	atomic.AddInt64(&c.n, -int64(len(items)))
+	if c.recount {
+		atomic.StoreInt64(&c.n, int64(c.inner.len()))
+	}
+	return items
+}
+
+func (c *capped[T]) each(fn func(T)) {
+	c.inner.each(fn)
+}
+
+func (c *capped[T]) filter(drop func(T) bool) []T {
+	items := c.inner.filter(drop)
+	atomic.AddInt64(&c.n, -int64(len(items)))
+	if c.recount {
+		atomic.StoreInt64(&c.n, int64(c.inner.len()))
+	}
+	return items
+}
+
+func (c *capped[T]) peek(fn func(T)) bool {
+	s, ok := c.inner.(peeker[T])
+	return ok && s.peek(fn)
+}
+
+func (c *capped[T]) take(x T) bool {
+	s, ok := c.inner.(taker[T])
+	if !ok || !s.take(x) {
+		return false
+	}
+	atomic.AddInt64(&c.n, -1)
+	return true
+}
+
+func (c *capped[T]) swap(build func(n int) []T) []T {
+	s, ok := c.inner.(swapper[T])
+	if !ok {
+		return nil
+	}
+	var built int
+	old := s.swap(func(n int) []T {
+		xs := build(n)
+		built = len(xs)
+		return xs
+	})
+	atomic.AddInt64(&c.n, int64(built-len(old)))
+	return old
+}
+
+func (c *capped[T]) unwrap() store[T] {
+	return c.inner
+}
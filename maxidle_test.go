@@ -0,0 +1,66 @@
+package gpool
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+// TestPool_MaxIdle 测试闲置对象达到上限后放回的对象被丢弃并计入统计，Get 仍然复用已保留的对象。
+func TestPool_MaxIdle(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithSharded[*bytes.Buffer](4), WithMaxIdle[*bytes.Buffer](2))
+
+	kept := []*bytes.Buffer{new(bytes.Buffer), new(bytes.Buffer)}
+	p.PutAll(kept)
+	for i := 0; i < 3; i++ {
+		p.Put(new(bytes.Buffer))
+	}
+
+	s := p.Stats()
+	if s.Idle != 2 || s.DiscardsByReason[DiscardOverflow] != 3 {
+		t.Errorf("超出上限的 3 个对象应该以 DiscardOverflow 被丢弃, 得到 Idle %d, %v", s.Idle, s.DiscardsByReason)
+	}
+	got := map[*bytes.Buffer]bool{p.Get(): true, p.Get(): true}
+	if !got[kept[0]] || !got[kept[1]] || p.Stats().Misses != 0 {
+		t.Error("Get 应该复用已保留的对象")
+	}
+
+	// 取出之后又有空位可以保存放回的对象。
+	p.Put(kept[0])
+	if s := p.Stats(); s.Idle != 1 || s.DiscardsByReason[DiscardOverflow] != 3 {
+		t.Errorf("低于上限时放回的对象应该被保存: %+v", s)
+	}
+	if c := p.Config(); c.MaxIdle != 2 || c.Backend != BackendSharded {
+		t.Errorf("Config 应该报告闲置上限和分片后端: %+v", c)
+	}
+}
+
+// TestPool_MaxIdle_Concurrent 测试并发放回时闲置对象的数量也不会超过上限。
+func TestPool_MaxIdle_Concurrent(t *testing.T) {
+	const max = 10
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithMaxIdle[*bytes.Buffer](max))
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				p.Put(new(bytes.Buffer))
+			}
+		}()
+	}
+	wg.Wait()
+
+	s := p.Stats()
+	if s.Idle != max || s.DiscardsByReason[DiscardOverflow] != 800-max {
+		t.Errorf("期望保留 %d 个对象并丢弃其余的, 得到 Idle %d, 丢弃 %d", max, s.Idle, s.DiscardsByReason[DiscardOverflow])
+	}
+	if n := len(p.store.drain()); n != max || p.store.(*capped[*bytes.Buffer]).n != 0 {
+		t.Errorf("drain 之后计数应该归零, 取出 %d 个", n)
+	}
+}
@@ -0,0 +1,27 @@
+package gpool
+
+// WithMaxReuse 让对象在被借出 n 次之后退役：第 n 次放回时它不会被存入池中，而是以 DiscardRetired 为原因被丢弃
+// （实现了 io.Closer 的对象会被关闭），之后的 Get 会用 newFunc 创建新的对象代替它。
+// 它适合使用过程中内部状态会不断增长的对象，例如解析器和压缩流，使它们定期被替换。
+//
+// 借出次数依据 WithMetadata 记录的 Borrows 计算，因此该选项会同时启用 WithMetadata，并且只对指针类型 T 有效：
+// 其他类型的池上它不起作用，也不会改变池的后端。没有被借出过、直接 Put 进来的对象从 0 开始计数。n <= 0 表示不限制。
+func WithMaxReuse[T any](n int) Option[T] {
+	return func(c *config[T]) {
+		c.maxReuse = n
+		if n > 0 && hasMeta[T]() {
+			c.metadata = true
+			c.needStore = true
+		}
+	}
+}
+
+// retires 报告池中的对象是否会在被借出 WithMaxReuse 设置的次数之后退役。
+func (p *Pool[T]) retires() bool {
+	return p.cfg.maxReuse > 0 && p.meta != nil
+}
+
+// retired 报告对象 x 是否已经被借出了 WithMaxReuse 设置的次数。
+func (p *Pool[T]) retired(x T) bool {
+	return p.meta.borrows(x) >= int64(p.cfg.maxReuse)
+}
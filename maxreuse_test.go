@@ -0,0 +1,46 @@
+package gpool
+
+import "testing"
+
+// TestPool_MaxReuse 测试对象在被借出 n 次之后放回时被丢弃并关闭，之后的 Get 创建新的对象。
+func TestPool_MaxReuse(t *testing.T) {
+	p := New(func() *closerObject {
+		return &closerObject{}
+	}, WithMaxReuse[*closerObject](3))
+	defer p.Close()
+	if c := p.Config(); c.MaxReuse != 3 || !c.Metadata || c.Backend != BackendDeterministic {
+		t.Fatalf("期望启用元数据和确定性后端, 得到 %+v", c)
+	}
+
+	first := p.Get()
+	p.Put(first)
+	for i := 0; i < 2; i++ {
+		if x := p.Get(); x != first {
+			t.Fatalf("第 %d 次借出前应该复用同一个对象", i+2)
+		}
+		p.Put(first)
+	}
+	if s := p.Stats(); first.closed != 1 || s.Idle != 0 || s.DiscardsByReason[DiscardRetired] != 1 {
+		t.Fatalf("被借出 3 次的对象应该在放回时退役并被关闭, 得到 %+v", s)
+	}
+
+	if x := p.Get(); x == first || p.Stats().Misses != 2 {
+		t.Fatal("退役之后的 Get 应该创建新的对象")
+	}
+
+	// 直接放回的对象从 0 开始计数。
+	outsider := &closerObject{}
+	p.Put(outsider)
+	if x := p.Get(); x != outsider {
+		t.Fatal("没有被借出过的对象不应该退役")
+	}
+}
+
+// TestPool_MaxReuse_NonPointer 测试 T 不是指针类型时 WithMaxReuse 不起作用，池仍然使用 sync.Pool 后端。
+func TestPool_MaxReuse_NonPointer(t *testing.T) {
+	p := New(func() []byte { return make([]byte, 0, 64) }, WithMaxReuse[[]byte](2))
+	defer p.Close()
+	if c := p.Config(); c.Backend != BackendSyncPool || c.Metadata || c.MaxReuse != 0 {
+		t.Fatalf("WithMaxReuse 不应该对非指针类型生效或改变后端, 得到 %+v", c)
+	}
+}
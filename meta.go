@@ -0,0 +1,163 @@
+package gpool
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// ObjectMeta 是池为一个对象记录的生命周期数据，与对象本身分开保存，不需要在 T 中嵌入额外的字段。
+// 池在对象被借出和放回时更新它，因此应当只在持有对象（Get 之后、Put 之前）时读取它或修改 Tags。
+type ObjectMeta struct {
+	// CreatedAt 是对象被 newFunc 创建的时间；对于不是由池创建、直接 Put 进来的对象，是池第一次见到它的时间。
+	CreatedAt time.Time
+	// Borrows 是对象被借出的总次数。
+	Borrows int64
+	// LastBorrowedAt 是对象最近一次被借出的时间，从未被借出时为零值。
+	LastBorrowedAt time.Time
+	// LastUsedAt 是对象最近一次被创建、借出、放回或通过 Touch 标记为使用中的时间，WithTTL 据此判断对象是否过期。
+	LastUsedAt time.Time
+	// Tags 保存调用方附加在对象上的任意标签，初始为 nil。
+	Tags map[string]string
+
+	now func() time.Time // 池的时钟，参见 WithClock
+}
+
+// Reuses 返回对象被复用的次数，即第一次借出之后又被借出的次数。
+func (m *ObjectMeta) Reuses() int64 {
+	if m.Borrows <= 1 {
+		return 0
+	}
+	return m.Borrows - 1
+}
+
+// Age 返回对象自创建以来经过的时间，按 WithClock 设置的时钟计算。
+func (m *ObjectMeta) Age() time.Duration {
+	if m.now == nil {
+		return time.Since(m.CreatedAt)
+	}
+	return m.now().Sub(m.CreatedAt)
+}
+
+// WithMetadata 让池为每个对象记录一份 ObjectMeta（创建时间、借出次数和标签），可以通过 Meta 读取。
+//
+// 元数据以对象的地址为键保存，因此只对指针类型 T 有效，其他类型的池上该选项不起作用，也不会改变池的后端。
+// 对象被丢弃或通过 Detach 分离时，它的元数据也会被删除；为了使每个对象离开池时都经过这一步，
+// 未指定其他后端时会改用确定性后端，而不是会被 GC 静默清空的 sync.Pool。
+func WithMetadata[T any]() Option[T] {
+	return func(c *config[T]) {
+		if hasMeta[T]() {
+			c.metadata = true
+			c.needStore = true
+		}
+	}
+}
+
+// Meta 返回池为对象 x 记录的元数据。池没有启用 WithMetadata、T 不是指针类型，
+// 或者 x 不是池中（或借出）的对象时返回 nil。
+func (p *Pool[T]) Meta(x T) *ObjectMeta {
+	if p.meta == nil {
+		return nil
+	}
+	return p.meta.get(x)
+}
+
+// metaStore 以对象本身为键保存 ObjectMeta。
+type metaStore struct {
+	now func() time.Time
+	mu  sync.Mutex
+	m   map[any]*ObjectMeta
+}
+
+// newMetaStore 为类型 T 创建一个使用时钟 now 的 metaStore，T 不是指针类型时返回 nil。
+func newMetaStore[T any](enabled bool, now func() time.Time) *metaStore {
+	if !enabled || !hasMeta[T]() {
+		return nil
+	}
+	return &metaStore{now: now, m: make(map[any]*ObjectMeta)}
+}
+
+// hasMeta 报告池能否为类型 T 的对象记录元数据，即 T 是否为指针类型。
+func hasMeta[T any]() bool {
+	return typeOf[T]().Kind() == reflect.Pointer
+}
+
+// created 为新创建的对象 x 记录创建时间。
+func (s *metaStore) created(x any) {
+	now := s.now()
+	s.mu.Lock()
+	s.m[x] = &ObjectMeta{CreatedAt: now, LastUsedAt: now, now: s.now}
+	s.mu.Unlock()
+}
+
+// entry 返回对象 x 的元数据，池还没有见过 x 时以 now 为创建时间新建一份，调用方必须持有 s.mu。
+func (s *metaStore) entry(x any, now time.Time) *ObjectMeta {
+	m := s.m[x]
+	if m == nil {
+		m = &ObjectMeta{CreatedAt: now, now: s.now}
+		s.m[x] = m
+	}
+	return m
+}
+
+// borrowed 记录对象 x 被借出一次。
+func (s *metaStore) borrowed(x any) {
+	now := s.now()
+	s.mu.Lock()
+	m := s.entry(x, now)
+	m.Borrows++
+	m.LastBorrowedAt = now
+	m.LastUsedAt = now
+	s.mu.Unlock()
+}
+
+// used 记录对象 x 刚刚被使用过（被放回池中），池还没有见过 x 时为它新建元数据。
+func (s *metaStore) used(x any) {
+	now := s.now()
+	s.mu.Lock()
+	s.entry(x, now).LastUsedAt = now
+	s.mu.Unlock()
+}
+
+// touch 更新已记录的对象 x 的最近使用时间，池没有见过 x 时不做任何事。
+func (s *metaStore) touch(x any) {
+	now := s.now()
+	s.mu.Lock()
+	if m := s.m[x]; m != nil {
+		m.LastUsedAt = now
+	}
+	s.mu.Unlock()
+}
+
+// borrows 返回对象 x 被借出的总次数，池没有见过 x 时返回 0。
+func (s *metaStore) borrows(x any) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if m := s.m[x]; m != nil {
+		return m.Borrows
+	}
+	return 0
+}
+
+// lastUsed 返回对象 x 最近被使用的时间，池没有见过 x 时返回 false。
+func (s *metaStore) lastUsed(x any) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if m := s.m[x]; m != nil {
+		return m.LastUsedAt, true
+	}
+	return time.Time{}, false
+}
+
+func (s *metaStore) get(x any) *ObjectMeta {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m[x]
+}
+
+// forget 删除对象 x 的元数据，用于离开池的对象。
+func (s *metaStore) forget(x any) {
+	s.mu.Lock()
+	delete(s.m, x)
+	s.mu.Unlock()
+}
@@ -0,0 +1,78 @@
+package gpool
+
+import (
+	"testing"
+	"time"
+)
+
+// TestPool_Meta 测试元数据记录了对象的创建时间和借出次数，调用方可以附加标签，对象被丢弃后元数据随之删除。
+func TestPool_Meta(t *testing.T) {
+	p := New(func() *closerObject {
+		return &closerObject{}
+	}, WithMetadata[*closerObject]())
+
+	x := p.Get()
+	created := p.Meta(x)
+	if created == nil || created.CreatedAt.IsZero() {
+		t.Fatalf("期望记录对象的创建时间, 得到 %+v", created)
+	}
+	created.Tags = map[string]string{"owner": "test"}
+	p.Put(x)
+
+	time.Sleep(time.Millisecond)
+	for i := 0; i < 3; i++ {
+		if got := p.Get(); got != x {
+			t.Fatal("期望复用同一个对象")
+		}
+		p.Put(x)
+	}
+
+	m := p.Meta(x)
+	if m.Borrows != 4 || m.Reuses() != 3 {
+		t.Errorf("期望借出 4 次、复用 3 次, 得到 %d 和 %d", m.Borrows, m.Reuses())
+	}
+	if m.Age() < time.Millisecond || !m.LastBorrowedAt.After(m.CreatedAt) {
+		t.Errorf("年龄和最近借出时间不符合预期: %+v", m)
+	}
+	if m.Tags["owner"] != "test" {
+		t.Errorf("期望保留附加的标签, 得到 %v", m.Tags)
+	}
+
+	p.Clear()
+	if p.Meta(x) != nil {
+		t.Error("对象被丢弃后元数据应该被删除")
+	}
+}
+
+// TestPool_Meta_Disabled 测试未启用 WithMetadata 或 T 不是指针类型时 Meta 返回 nil。
+func TestPool_Meta_Disabled(t *testing.T) {
+	p := New(func() *closerObject { return &closerObject{} })
+	if p.Meta(p.Get()) != nil {
+		t.Error("未启用 WithMetadata 时 Meta 应该返回 nil")
+	}
+	v := New(func() int { return 1 }, WithMetadata[int]())
+	if v.Meta(v.Get()) != nil || v.Config().Metadata {
+		t.Error("T 不是指针类型时 Meta 应该返回 nil")
+	}
+	if b := v.Config().Backend; b != BackendSyncPool {
+		t.Errorf("T 不是指针类型时 WithMetadata 不应该改变后端, 得到 %s", b)
+	}
+}
+
+// TestPool_Meta_Clock 测试元数据中的时间和 Age 都按 WithClock 设置的时钟计算。
+func TestPool_Meta_Clock(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := New(func() *closerObject {
+		return &closerObject{}
+	}, WithMetadata[*closerObject](), WithClock[*closerObject](func() time.Time { return now }))
+
+	x := p.Get()
+	m := p.Meta(x)
+	if !m.CreatedAt.Equal(now) {
+		t.Fatalf("期望创建时间为 %v, 得到 %v", now, m.CreatedAt)
+	}
+	now = now.Add(time.Hour)
+	if age := m.Age(); age != time.Hour {
+		t.Errorf("期望年龄为 1h, 得到 %v", age)
+	}
+}
@@ -0,0 +1,50 @@
+package gpool
+
+import "time"
+
+// MetricsRecorder 接收池的 Get/Put 延迟和未命中事件，用来把池的行为接入 OpenTelemetry 等已有的指标系统，
+// gpool/otel 模块提供了基于 OpenTelemetry 的实现。通过 WithMetricsRecorder 设置。
+//
+// 方法在调用方的 goroutine 中同步调用，可能被并发调用，应当尽快返回。
+type MetricsRecorder interface {
+	// RecordGet 在每次 Get 返回时被调用，d 是 Get 花费的时间，包括等待有界池额度和创建新对象的时间。
+	RecordGet(d time.Duration)
+	// RecordPut 在每次 Put 返回时被调用，d 是 Put 花费的时间。
+	RecordPut(d time.Duration)
+	// RecordMiss 在池因为没有闲置对象而调用 newFunc 创建新对象时被调用，与 Stats 的 Misses 一致。
+	// 命中次数是 Get 的次数减去未命中的次数。
+	RecordMiss()
+}
+
+// WithMetricsRecorder 让池把 Get 和 Put 的延迟以及未命中事件报告给 r。
+// 只有 Get 和 Put 的延迟会被记录，GetAll、TryGet 等其他方法只会报告它们造成的未命中。
+// 记录延迟需要在每次 Get 和 Put 时读取两次时钟，因此只应在需要这些指标时启用。
+func WithMetricsRecorder[T any](r MetricsRecorder) Option[T] {
+	return func(c *config[T]) {
+		c.recorder = r
+	}
+}
+
+// getRecorded 执行一次 Get 并把它花费的时间报告给 WithMetricsRecorder 设置的 MetricsRecorder。
+func (p *Pool[T]) getRecorded() T {
+	start := time.Now()
+	var x T
+	if p.labeled {
+		x = p.getLabeled()
+	} else {
+		x = p.getOne()
+	}
+	p.cfg.recorder.RecordGet(time.Since(start))
+	return x
+}
+
+// putRecorded 执行一次 Put 并把它花费的时间报告给 WithMetricsRecorder 设置的 MetricsRecorder。
+func (p *Pool[T]) putRecorded(x T) {
+	start := time.Now()
+	if p.labeled {
+		p.putLabeled(x)
+	} else {
+		p.putOne(x)
+	}
+	p.cfg.recorder.RecordPut(time.Since(start))
+}
@@ -0,0 +1,65 @@
+package gpool
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingRecorder 是记录调用次数的 MetricsRecorder。
+type countingRecorder struct {
+	mu               sync.Mutex
+	gets, puts, miss int
+	getTime          time.Duration
+}
+
+func (r *countingRecorder) RecordGet(d time.Duration) {
+	r.mu.Lock()
+	r.gets++
+	r.getTime += d
+	r.mu.Unlock()
+}
+
+func (r *countingRecorder) RecordPut(time.Duration) {
+	r.mu.Lock()
+	r.puts++
+	r.mu.Unlock()
+}
+
+func (r *countingRecorder) RecordMiss() {
+	r.mu.Lock()
+	r.miss++
+	r.mu.Unlock()
+}
+
+// TestPool_MetricsRecorder 测试 Get、Put 和未命中都被报告给 MetricsRecorder，未命中的次数与 Stats 一致。
+func TestPool_MetricsRecorder(t *testing.T) {
+	r := &countingRecorder{}
+	p := New(func() *bytes.Buffer {
+		time.Sleep(time.Millisecond)
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer](), WithMetricsRecorder[*bytes.Buffer](r))
+	if !p.Config().MetricsRecorder || p.plain {
+		t.Fatal("设置了 MetricsRecorder 的池不应该走最短路径")
+	}
+
+	a := p.Get()
+	p.Put(a)
+	b := p.Get()
+	c := p.Get()
+	p.Put(b)
+	p.Put(c)
+	p.PutAll(p.GetAll(3))
+
+	s := p.Stats()
+	if r.gets != 3 || r.puts != 3 {
+		t.Fatalf("期望记录 3 次 Get 和 3 次 Put, 得到 %d 次和 %d 次", r.gets, r.puts)
+	}
+	if int64(r.miss) != s.Misses || r.miss != 3 {
+		t.Fatalf("期望记录 %d 次未命中, 得到 %d 次", s.Misses, r.miss)
+	}
+	if r.getTime < 2*time.Millisecond {
+		t.Fatalf("Get 的延迟应该包括创建新对象的时间, 得到 %v", r.getTime)
+	}
+}
@@ -0,0 +1,40 @@
+package gpool
+
+// ToFixed 把池迁移到固定容量后端：创建一个使用相同构造函数和选项、但改用 WithCapacity(capacity) 的新池，
+// 并把当前池的闲置对象转移过去，使调用方可以根据观察到的负载在运行时切换存储策略。
+//
+// 转移的对象保持原来的 LIFO 顺序，不会被再次重置或校验，也不计入新池的 Puts；
+// 闲置对象超过 capacity 时保留最近放回的 capacity 个，其余的以 DiscardOverflow 为原因由当前池丢弃。
+// sync.Pool 后端的闲置对象无法枚举，不会被转移，新池一开始是空的。
+//
+// 迁移后当前池仍然可用，只是没有闲置对象了；统计信息不会被转移。
+// 调用方应该在切换到新池之后不再使用当前池，并把仍借出的对象放回新池。
+func (p *Pool[T]) ToFixed(capacity int) *Pool[T] {
+	q := p.derive(WithCapacity[T](capacity))
+	if p.store == nil {
+		return q
+	}
+	items := p.store.drain()
+	if keep := q.cfg.capacity; len(items) > keep {
+		if keep < 0 {
+			keep = 0
+		}
+		p.discardAll(items[:len(items)-keep], DiscardOverflow)
+		items = items[len(items)-keep:]
+	}
+	for _, x := range items {
+		if !q.store.put(x) {
+			p.discard(x, DiscardOverflow)
+		}
+	}
+	return q
+}
+
+// derive 创建一个使用与 p 当前相同的构造函数和选项、并追加了 extra 选项的新池。
+func (p *Pool[T]) derive(extra ...Option[T]) *Pool[T] {
+	opts := append(append([]Option[T](nil), p.opts...), extra...)
+	f := p.factory.Load()
+	q := &Pool[T]{newFunc: f.fn, newFuncE: f.fnE, opts: opts}
+	q.init()
+	return q
+}
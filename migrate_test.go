@@ -0,0 +1,43 @@
+package gpool
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestPool_ToFixed 测试把确定性后端的闲置对象迁移到固定容量后端，对象及其顺序都被保留，选项也继续生效。
+func TestPool_ToFixed(t *testing.T) {
+	var discarded []string
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer](), WithValidateOnPut(func(b *bytes.Buffer) bool {
+		return b.Cap() < 1024
+	}), WithOnDiscard(func(_ *bytes.Buffer, reason string) {
+		discarded = append(discarded, reason)
+	}))
+
+	a, b, c := new(bytes.Buffer), new(bytes.Buffer), new(bytes.Buffer)
+	p.PutAll([]*bytes.Buffer{a, b, c})
+
+	q := p.ToFixed(2)
+	if got := q.Config(); got.Backend != BackendFixed || got.Capacity != 2 || !got.ValidateOnPut {
+		t.Errorf("新池应该使用固定容量后端并保留原来的选项: %+v", got)
+	}
+	if p.Stats().Idle != 0 {
+		t.Error("迁移后原来的池应该没有闲置对象")
+	}
+	if len(discarded) != 1 || discarded[0] != DiscardOverflow {
+		t.Errorf("超出容量的对象应该以 DiscardOverflow 被丢弃, 得到 %v", discarded)
+	}
+	if x, y := q.Get(), q.Get(); x != c || y != b {
+		t.Error("新池应该按原来的顺序保留最近放回的对象")
+	}
+	if s := q.Stats(); s.Misses != 0 || s.Puts != 0 {
+		t.Errorf("转移的对象不应计入新池的 Puts 或 Misses: %+v", s)
+	}
+
+	q.Put(bytes.NewBuffer(make([]byte, 0, 4096)))
+	if len(discarded) != 2 || discarded[1] != DiscardInvalid {
+		t.Errorf("新池应该继续执行原来的校验和回调, 得到 %v", discarded)
+	}
+}
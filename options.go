@@ -0,0 +1,614 @@
+package gpool
+
+import (
+	"context"
+	"time"
+)
+
+// Option 用于在 New 时配置 Pool 的可选行为。
+type Option[T any] func(*config[T])
+
+// config 保存通过 Option 设置的配置项。
+// 零值表示未启用任何可选行为。
+type config[T any] struct {
+	// onDiscard 在池丢弃对象时被调用。
+	onDiscard func(x T, reason string)
+	// audit 为每个被丢弃的对象写入审计记录，未启用时为 nil。
+	audit *auditLog
+
+	// backend 是池使用的存储后端，零值表示 sync.Pool。
+	backend backend
+	// capacity 是固定容量后端最多保存的闲置对象数量。
+	capacity int
+	// shards 是分片后端的分片数量。
+	shards int
+	// cacheWarmth 表示 Get 优先返回最近放入的、仍在 CPU 缓存中的对象。
+	cacheWarmth bool
+	// shardFunc 决定分片后端的本地分片。
+	shardFunc func() int
+	// customStore 用于创建内置后端之外的存储，返回 nil 时使用 sync.Pool。
+	customStore func() store[T]
+	// weak 表示 customStore 是只通过弱引用持有对象的存储。
+	weak bool
+
+	// max 是有界池同时借出对象数量的上限，0 表示不限制。
+	max int
+	// scaleMin 和 scaleMax 是自动伸缩的上限范围，scaleMax 为 0 时不自动伸缩；
+	// scaleTarget 是自动伸缩希望维持的平均等待时间。
+	scaleMin, scaleMax int
+	scaleTarget        time.Duration
+
+	// strictNil 表示构造函数返回 nil 时 Get 应该 panic。
+	strictNil bool
+
+	// tap 在每次借出和放回对象时被调用。
+	tap func(op string, x T)
+
+	// discardOnPanic 表示 Do 的回调 panic 时丢弃对象。
+	discardOnPanic bool
+
+	// profileLabel 是 Get 和 Put 期间设置的 pprof 标签的值，为空时不设置标签。
+	profileLabel string
+
+	// debug 表示启用调试模式下的额外检查。
+	debug bool
+
+	// logger 用于输出诊断信息，为 nil 时使用标准库的默认 Logger。
+	logger Logger
+	// churnThreshold 是持有时间检测的阈值，为 0 时不检测。
+	churnThreshold time.Duration
+
+	// warmupBarrier 表示 Get 在 MarkWarm 之前等待预热完成，最多等待 warmupTimeout。
+	warmupBarrier bool
+	warmupTimeout time.Duration
+	// allocCap 是池在整个生命周期中最多创建的对象数量，为 0 时不限制。
+	allocCap int
+	// newRate 是每秒最多创建的新对象数量，为 0 时不限制。
+	newRate int
+
+	// measure 估算对象占用的字节数。
+	measure func(T) int
+
+	// noStats 表示禁用统计。
+	noStats bool
+	// shardedStats 表示统计计数器分散在多个分片中。
+	shardedStats bool
+
+	// autoReset 表示 Put 时自动调用 Resetter 的 Reset。
+	autoReset bool
+
+	// validate 在 Get 取出闲置对象时校验它。
+	validate func(T) bool
+	// validateOnPut 在 Put 存入对象前校验它。
+	validateOnPut func(T) bool
+	// enabled 报告当前是否启用池化，为 nil 时总是启用。
+	enabled func() bool
+	// dedup 判断两个对象是否相等，Put 不存入与闲置对象相等的对象。
+	dedup func(a, b T) bool
+	// oversized 在 Put 时判断对象是否过大，过大的对象以 DiscardOversized 为原因丢弃。
+	oversized func(T) bool
+
+	// resetFields 在 Put 时依次清理对象的部分字段。
+	resetFields []func(T)
+	// resetVerify 在调试模式下检查重置后的对象是否已被清理干净。
+	resetVerify func(T) bool
+	// recycle 在 Put 时将放回的对象转换为实际存入的对象。
+	recycle func(old T) T
+	// retainGuard 报告放回的对象是否仍然引用着外部数据。
+	retainGuard func(T) bool
+
+	// beforeStore 在对象存入存储前对其进行转换。
+	beforeStore func(T) T
+	// release 在对象被丢弃时释放池为它持有的资源。
+	release func(T)
+	// zeroOnGet 表示 Get 在交出复用的对象之前将其清零。
+	zeroOnGet bool
+	// stateChange 在池变冷或变热时被调用。
+	stateChange func(warm bool)
+	// uniqueIssue 表示池严格保证同一个对象不会同时被借出两次。
+	uniqueIssue bool
+	// metadata 表示池为每个对象记录 ObjectMeta。
+	metadata bool
+	// ttl 是 WithTTL 设置的对象过期时间，为 0 时对象不会过期
+	ttl time.Duration
+	// idleTimeout 是 WithIdleTimeout 设置的闲置超时，为 0 时闲置对象不会因闲置太久被丢弃
+	idleTimeout time.Duration
+	// now 是 WithClock 设置的时钟，为 nil 时使用 time.Now
+	now func() time.Time
+	// traceID 从 context 中提取 GetTraced 记录的追踪标识。
+	traceID func(ctx context.Context) string
+	// maxIdle 是 WithMaxIdle 设置的闲置对象数量上限，为 0 时不限制。
+	maxIdle int
+	// maxReuse 是 WithMaxReuse 设置的对象最多被借出的次数，为 0 时不限制。
+	maxReuse int
+	// lowWater 和 highWater 是 WithWatermarks 为闲置数量设置的区间，为 0 时不限制对应的一端。
+	lowWater, highWater int
+	// autoCompact 表示后台维护定期收缩闲置对象所在的底层数组。
+	autoCompact bool
+	// softThreshold 是 WithProactiveReplace 替换闲置对象的大小阈值，为 0 时不替换。
+	softThreshold int
+	// prepare 和 finish 在对象被借出和放回时调用，由 NewRecyclable 设置。
+	prepare func(T)
+	finish  func(T)
+	// onGet 和 onPut 是 WithOnGet 和 WithOnPut 设置的回调，按设置的顺序调用
+	onGet, onPut []func(T)
+	// recorder 是 WithMetricsRecorder 设置的指标记录器
+	recorder MetricsRecorder
+	// asyncPut 是 WithAsyncPut 队列的长度，为 0 时 Put 同步执行。
+	asyncPut int
+	// affinity 表示 Get 优先返回调用方 goroutine 最近放入的对象。
+	affinity bool
+	// needStore 表示该配置要求池自己持有闲置对象，不能使用 sync.Pool 后端。
+	needStore bool
+}
+
+// WithOnDiscard 设置一个回调，池每丢弃一个对象都会调用它恰好一次。
+// reason 是 Discard* 常量之一，说明对象被丢弃的原因。
+//
+// 回调只针对池主动丢弃的对象，交给调用方的对象永远不会触发它；
+// 被 sync.Pool 在 GC 时静默回收的对象同样无法被观察到。
+func WithOnDiscard[T any](fn func(x T, reason string)) Option[T] {
+	return func(c *config[T]) {
+		c.onDiscard = fn
+	}
+}
+
+// WithDeterministic 让池使用一个互斥锁保护的 LIFO 栈代替 sync.Pool 存储对象。
+//
+// 与 sync.Pool 不同，放入的对象不会被 GC 静默回收，Get 的结果是确定的：
+// 总是返回最近一次放入的对象。代价是所有操作都需要竞争同一把锁。
+func WithDeterministic[T any]() Option[T] {
+	return func(c *config[T]) {
+		c.backend = backendDeterministic
+	}
+}
+
+// WithCapacity 让池使用一个最多保存 n 个闲置对象的 LIFO 栈代替 sync.Pool 存储对象，
+// 使池占用的内存可以预测。存满后放回的对象不会被存入，而是以 DiscardOverflow 为原因被丢弃
+// （实现了 io.Closer 的对象会被关闭）。
+//
+// 与 WithDeterministic 一样，放入的对象不会被 GC 静默回收。
+// WithCapacity 限制的是闲置对象的数量；限制借出对象的数量请使用 WithMax。
+// n <= 0 时池不保存任何闲置对象。
+func WithCapacity[T any](n int) Option[T] {
+	return func(c *config[T]) {
+		c.backend = backendFixed
+		c.capacity = n
+	}
+}
+
+// WithSharded 让池使用 n 个分片栈代替 sync.Pool 存储对象，以减少高并发下的锁竞争。
+// n <= 0 时使用 runtime.GOMAXPROCS(0) 个分片。
+//
+// 与 WithDeterministic 一样，放入的对象不会被 GC 静默回收。
+func WithSharded[T any](n int) Option[T] {
+	return func(c *config[T]) {
+		c.backend = backendSharded
+		c.shards = n
+	}
+}
+
+// WithShardFunc 让分片后端通过 fn 而不是 P 的提示来选择本地分片，
+// 以便按租户等逻辑分区确定性地放置对象，提高分区化负载的局部性。
+// fn 的返回值会对分片数取模，因此任意整数（包括负数）都是合法的。
+//
+// Put 总是放入 fn 选中的分片；Get 优先从该分片取对象，为空时才查找其他分片。
+// 该选项只对 WithSharded 后端有效。
+func WithShardFunc[T any](fn func() int) Option[T] {
+	return func(c *config[T]) {
+		c.shardFunc = fn
+	}
+}
+
+// WithCacheWarmth 让 Get 优先返回最近放入的对象，这些对象很可能仍在 CPU 缓存中，
+// 有利于频繁访问对象内存的紧密计算循环。
+//
+// 确定性后端本身就是 LIFO 的，这一选项对它没有额外影响；
+// 分片后端会优先从当前 P 的本地分片取对象，为空时才查找其他分片；
+// sync.Pool 本身已按 P 缓存最近放入的对象，这一选项同样不改变其行为。
+func WithCacheWarmth[T any]() Option[T] {
+	return func(c *config[T]) {
+		c.cacheWarmth = true
+	}
+}
+
+// WithWeakRetention 让池只通过弱引用（weak.Pointer）持有闲置对象，只能用于指针类型的池。
+//
+// 只要 GC 尚未回收，闲置对象就可以被后续的 Get 复用；一旦被回收，Get 会跳过它，
+// 必要时回退到 newFunc 创建新对象。与 sync.Pool 相比，闲置对象按 LIFO 顺序复用，
+// 且每个对象是否存活完全由 GC 决定。
+//
+// 该选项依赖 Go 1.24 引入的 weak 包；在更早的 Go 版本下编译时它不起作用，池仍使用 sync.Pool。
+func WithWeakRetention[E any]() Option[*E] {
+	return func(c *config[*E]) {
+		c.customStore = newWeakStore[E]
+		c.weak = true
+	}
+}
+
+// WithMax 将池变为有界池：同时借出（已 Get 但尚未 Put）的对象最多为 n 个。
+// 达到上限后 Get 会阻塞，直到有对象被放回。n <= 0 表示不限制。
+//
+// 有界池适合数据库连接之类的昂贵资源。每次 Put 都会归还一个额度，
+// 因此不应把不是从该池取出的对象放入有界池。
+func WithMax[T any](n int) Option[T] {
+	return func(c *config[T]) {
+		if n < 0 {
+			n = 0
+		}
+		c.max = n
+	}
+}
+
+// WithAutoScale 让有界池根据 Get 的平均等待时间自动调整借出上限：
+// 后台维护 goroutine 每秒检查一次上一个周期内获取额度的平均等待时间，
+// 超过 target 时提高上限，低于 target 的一半时逐步降低上限，上限始终保持在 [min, max] 范围内。
+//
+// 初始上限是 WithMax 设置的值（未设置时为 min），并被限制在 [min, max] 范围内。
+// 降低上限不会收回已借出的额度，只是让后续的 Get 等待借出数量回落到新的上限以下；
+// 请求数量超过当前上限但不超过 max 的 GetAll 会等待上限重新提高，而不是 panic。
+// min 小于 1 时按 1 处理，max 小于 min 时按 min 处理。
+func WithAutoScale[T any](min, max int, target time.Duration) Option[T] {
+	return func(c *config[T]) {
+		if min < 1 {
+			min = 1
+		}
+		if max < min {
+			max = min
+		}
+		c.scaleMin, c.scaleMax, c.scaleTarget = min, max, target
+	}
+}
+
+// WithValidator 设置一个在 Get 时运行的校验函数：从池中取出的闲置对象如果未通过校验，
+// 就以 DiscardInvalid 为原因被丢弃（实现了 io.Closer 的对象会被关闭），Get 继续尝试下一个。
+// 由 newFunc 新建的对象不会被校验。
+//
+// 对于 sync.Pool 后端，一次校验失败后 Get 会直接通过 newFunc 创建新对象，而不是继续从中取出。
+// 被覆盖的 sync.Pool.New 新建的对象无法与闲置对象区分，同样会被校验。
+//
+// 它适合检查在闲置期间可能失效的对象，例如已被对端关闭的连接。
+// 可以与 WithValidateOnPut 同时使用，两者相互独立：
+// 对象在 Put 时通过校验才会被存入，之后在 Get 时还会被再次校验。
+func WithValidator[T any](fn func(T) bool) Option[T] {
+	return func(c *config[T]) {
+		c.validate = fn
+	}
+}
+
+// WithValidateOnPut 设置一个在 Put 时运行的校验函数：未通过校验的对象不会被存入池中，
+// 而是以 DiscardInvalid 为原因被丢弃（实现了 io.Closer 的对象会被关闭），使池从不保存坏对象。
+//
+// 与 WithValidator 同时设置时，两个校验函数各自独立运行，参见 WithValidator。
+func WithValidateOnPut[T any](fn func(T) bool) Option[T] {
+	return func(c *config[T]) {
+		c.validateOnPut = fn
+	}
+}
+
+// WithEnabledFunc 设置一个在每次 Get 和 Put 时调用的函数，用来根据运行时的开关（例如功能开关）动态地启用或关闭池化，
+// 而不必替换池的实现：fn 返回 false 时，Get 不再复用闲置对象，总是通过 newFunc 创建新对象（计入 Stats 的 Misses），
+// Put 也不再存入对象，而是以 DiscardDisabled 为原因丢弃它（实现了 io.Closer 的对象会被关闭）；
+// fn 重新返回 true 后恢复正常的池化。关闭期间已经闲置在池中的对象保持不变，恢复后可以继续复用。
+//
+// fn 位于 Get 和 Put 的热点路径上，应当足够廉价，例如读取一个原子变量；未设置时没有额外开销。
+// 有界池的借出额度、统计等其他行为不受 fn 影响。
+func WithEnabledFunc[T any](fn func() bool) Option[T] {
+	return func(c *config[T]) {
+		c.enabled = fn
+	}
+}
+
+// WithDedup 让 Put 在存入对象之前检查池中是否已有与它相等（equal 返回 true）的闲置对象，
+// 如果有就不存入，而是以 DiscardDuplicate 为原因丢弃它，使闲置对象保持各不相同，适合缓存类的用法。
+//
+// 检查需要逐个比较所有闲置对象，每次 Put 的开销是 O(n)，因此只建议用于闲置对象很少的池，
+// 例如同时使用 WithMaxIdle 或 WithCapacity 限制其数量。检查与存入不是一次原子操作，
+// 并发放回的两个相等对象可能都被存入。比较的是重置（WithAutoReset、WithRecycle 等）之后的对象。
+// 由于 sync.Pool 的闲置对象无法枚举，未指定其他后端时会改用确定性后端。
+func WithDedup[T any](equal func(a, b T) bool) Option[T] {
+	return func(c *config[T]) {
+		c.dedup = equal
+		c.needStore = true
+	}
+}
+
+// WithStrictNil 启用严格模式：如果构造函数（newFunc 或被覆盖的 sync.Pool.New）返回了 nil，
+// Get 会 panic 并给出描述性信息，而不是静默地返回 T 的零值，以便尽早发现配置错误的构造函数。
+//
+// 默认的宽松模式保持兼容：Get 在这种情况下返回 T 的零值。
+func WithStrictNil[T any]() Option[T] {
+	return func(c *config[T]) {
+		c.strictNil = true
+	}
+}
+
+// WithAutoReset 让 Put 在存入对象前自动重置它，使调用方不会因为忘记清理而把脏数据泄漏给下一个使用者。
+//
+// 如果 T 实现了 DirtyResetter，只有 Dirty 返回 true 的对象才会被重置；
+// 否则如果 T 实现了 Resetter，每个对象都会被重置；两者都未实现时该选项不起作用。
+// 是否实现这些接口只在创建池时根据类型 T 检测一次。
+func WithAutoReset[T any]() Option[T] {
+	return func(c *config[T]) {
+		c.autoReset = true
+	}
+}
+
+// WithResetFields 为保存 *E 的池声明 Put 时需要清理的字段：每个 setter 清理一个（或一组）字段，
+// 它们按顺序组合成一次重置，例如：
+//
+//	gpool.WithResetFields(
+//		func(r *Request) { r.Header = r.Header[:0] },
+//		func(r *Request) { r.Body = nil },
+//	)
+//
+// 对于只有少数字段需要清理的大结构体，这比整体清零或基于反射的重置更高效，也比手写重置函数更清晰；
+// 没有列出的字段会原样保留，以便有意地复用它们（例如已分配的缓冲区）。
+// setter 在 WithAutoReset 的重置之后、WithRecycle 之前运行。多次使用该选项时，setter 会依次追加。
+func WithResetFields[E any](setters ...func(*E)) Option[*E] {
+	return func(c *config[*E]) {
+		c.resetFields = append(c.resetFields, setters...)
+	}
+}
+
+// WithResetVerify 设置一个调试用的检查函数：Put 完成重置（WithAutoReset 和 WithResetFields）之后，
+// 如果 fn 对对象返回 false（例如“缓冲区不为空”），说明重置函数有遗漏，Put 会 panic 并说明原因，
+// 以便在开发阶段尽早发现不完整的重置，而不是让残留的数据泄漏给下一个使用者。
+//
+// 检查只在同时启用了 WithDebug 时运行，因此可以一直保留这个选项，生产环境中关闭调试模式即可，没有额外开销。
+func WithResetVerify[T any](fn func(T) bool) Option[T] {
+	return func(c *config[T]) {
+		c.resetVerify = fn
+	}
+}
+
+// WithRecycle 设置一个在 Put 时运行的回收函数：fn 接收被放回的对象 old，
+// 返回代替它存入池中的对象，之后的 Get 取回的是 fn 的返回值。
+//
+// 与只能原地清理对象的 Resetter 不同，fn 可以返回一个全新的对象，
+// 同时把 old 中值得保留的状态（例如已分配的缓冲区）转移过去。
+// fn 在自动重置（WithAutoReset）之后运行；它返回 nil 时不存入任何对象，并以 DiscardNil 为原因丢弃。
+func WithRecycle[T any](fn func(old T) T) Option[T] {
+	return func(c *config[T]) {
+		c.recycle = fn
+	}
+}
+
+// 以下常量是 WithTap 的回调收到的操作名。
+const (
+	// TapGet 表示对象被借出（Get、GetAll 等）。
+	TapGet = "get"
+	// TapPut 表示对象被放回（Put、PutAll 等），回调在池处理该对象之前被调用。
+	TapPut = "put"
+)
+
+// WithTap 设置一个诊断用的回调：每次借出或放回对象时，fn 都会以操作名（TapGet 或 TapPut）
+// 和对象本身被调用，可以用来把流经池的对象记录到环形缓冲区等位置，供之后检查或回放。
+//
+// fn 收到的是对象本身而不是副本；如果需要保留对象放回时的状态，应在 fn 中自行复制。
+// fn 在调用方的 goroutine 中同步执行，可能被并发调用。可以通过 SetTap 暂停或恢复它。
+func WithTap[T any](fn func(op string, x T)) Option[T] {
+	return func(c *config[T]) {
+		c.tap = fn
+	}
+}
+
+// WithOnGet 添加一个在对象被借出（Get、GetAll 等）时调用的回调，它在 WithValidator 的校验、
+// Recyclable 的 Prepare 等所有处理完成之后、把对象交给调用方之前运行，可以用来为对象附加本次借出的上下文。
+// 多次使用该选项时，回调按设置的顺序依次调用。nil 对象不会触发回调。
+func WithOnGet[T any](fn func(x T)) Option[T] {
+	return func(c *config[T]) {
+		c.onGet = append(c.onGet, fn)
+	}
+}
+
+// WithOnPut 添加一个在对象被放回（Put、PutAll 等）时调用的回调，它在池处理对象（重置、校验、存入或丢弃）之前运行，
+// 可以用来清理使用期间留下的外部状态。多次使用该选项时，回调按设置的顺序依次调用。nil 对象不会触发回调。
+func WithOnPut[T any](fn func(x T)) Option[T] {
+	return func(c *config[T]) {
+		c.onPut = append(c.onPut, fn)
+	}
+}
+
+// WithDiscardOnPanic 让 Do 的回调发生 panic 时丢弃对象而不是放回池中：
+// 对象以 DiscardPanicked 为原因被丢弃（实现了 io.Closer 的对象会被关闭），然后 panic 继续向上传播。
+// 回调可能在修改对象到一半时 panic，这可以避免处于不一致状态的对象被之后的 Get 取回。
+func WithDiscardOnPanic[T any]() Option[T] {
+	return func(c *config[T]) {
+		c.discardOnPanic = true
+	}
+}
+
+// WithProfileLabels 让 Get 和 Put 在执行期间为当前 goroutine 设置 pprof 标签 gpool=name，
+// 使这期间的 CPU 采样（包括等待有界池额度、竞争后端锁和调用 newFunc 的开销）
+// 在 profile 中归属到这个池，便于在多个池之间定位热点。
+//
+// 标签通过 pprof.Do 设置，会在操作期间暂时替换调用方 goroutine 已有的标签，操作结束后恢复。
+// 该选项只用于观测；未启用时 Get 和 Put 只多一次分支判断，没有额外开销。
+// name 为空时不设置标签。
+func WithProfileLabels[T any](name string) Option[T] {
+	return func(c *config[T]) {
+		c.profileLabel = name
+	}
+}
+
+// WithDebug 启用调试模式下的额外检查，发现的问题通过 Logger 报告。
+// 这些检查有额外的运行时开销，应只在开发和测试中启用。目前包括：
+//
+//   - StagePut 暂存的对象既没有 commit 也没有 abort。
+//   - WithResetVerify 发现对象在重置后仍有残留（这种情况下 Put 会 panic）。
+//
+// 调试模式下池还会记录每个借出对象的借出时间和调用栈，供 CloseWithReport 报告未放回的对象。
+func WithDebug[T any]() Option[T] {
+	return func(c *config[T]) {
+		c.debug = true
+	}
+}
+
+// WithLogger 设置池输出诊断信息所用的 Logger，默认使用标准库的 log.Default()。
+func WithLogger[T any](l Logger) Option[T] {
+	return func(c *config[T]) {
+		c.logger = l
+	}
+}
+
+// WithChurnDetector 启用持有时间检测：池会采样对象从 Get 到 Put 的持有时间，
+// 如果其中位数低于 threshold，就通过 Logger 输出一次提示，说明池化这个类型可能得不偿失。
+// 对象被借出又立即放回、每秒成千上万次时，池本身的开销可能超过复用带来的收益。
+//
+// 这是一个面向调优的诊断功能，提示最多只输出一次，之后检测器停止工作。
+// 检测器以对象本身关联 Get 和 Put，因此只对可比较的非接口类型（例如指针）生效。
+func WithChurnDetector[T any](threshold time.Duration) Option[T] {
+	return func(c *config[T]) {
+		c.churnThreshold = threshold
+	}
+}
+
+// WithWarmupBarrier 让 Get 在池预热完成之前等待，直到调用 MarkWarm，
+// 使异步预热期间到达的早期 Get 复用预热好的对象，而不是各自创建新对象而使预热失去意义。
+//
+// 为了避免 MarkWarm 没有被调用时永久阻塞，每个 Get 最多等待 timeout，超时后照常获取对象；
+// timeout <= 0 时 Get 会一直等待，此时调用方必须保证最终会调用 MarkWarm（或 Close）。
+// 该等待只影响 Get、GetAll 等会阻塞的获取方法，不影响 TryGetAll 等非阻塞方法。
+// ResetPool 会使池重新回到未预热的状态。
+func WithWarmupBarrier[T any](timeout time.Duration) Option[T] {
+	return func(c *config[T]) {
+		c.warmupBarrier = true
+		c.warmupTimeout = timeout
+	}
+}
+
+// WithAllocCap 限制池在整个生命周期中最多调用 newFunc 创建 n 个对象，主要用于在测试中断言某个操作的分配预算。
+// 计数的是 newFunc 的实际调用次数（包括预热），复用闲置对象不受影响。
+//
+// 达到上限后，需要创建新对象的 Get 会 panic，panic 的值是 Kind 为 KindNewFailed、
+// 底层错误为 ErrAllocCap 的 *PoolError，可以在测试中通过 recover 和 errors.Is 判断。
+// 预热方法同样会 panic。ResetPool 会重新开始计数。n <= 0 时不限制。
+func WithAllocCap[T any](n int) Option[T] {
+	return func(c *config[T]) {
+		c.allocCap = n
+	}
+}
+
+// WithNewRateLimit 将调用 newFunc 创建新对象的速率限制在每秒 rps 次以内，
+// 避免池在冷启动时遇到突发请求而瞬间大量分配对象，造成内存和 CPU 的尖峰。
+//
+// 池为空时，Get 会等待创建额度；对于自己持有闲置对象的后端，等待期间如果有对象被放回，
+// Get 会直接复用它。由于额度总会到来，即使没有任何对象被放回 Get 也不会永久阻塞。
+// sync.Pool 后端无法在等待期间观察到被放回的对象，只能等待创建额度。
+// rps 小于等于 0 时不限制。
+func WithNewRateLimit[T any](rps int) Option[T] {
+	return func(c *config[T]) {
+		c.newRate = rps
+	}
+}
+
+// WithMeasure 设置一个估算对象占用字节数的函数，供 EstimatedBytes 统计池中闲置对象的总大小。
+// 例如对 *bytes.Buffer 可以使用 func(b *bytes.Buffer) int { return b.Cap() }。
+func WithMeasure[T any](fn func(T) int) Option[T] {
+	return func(c *config[T]) {
+		c.measure = fn
+	}
+}
+
+// WithProactiveReplace 让后台维护 goroutine 每秒检查一次闲置对象，把用 WithMeasure 测得的大小
+// 不小于 softThreshold 的对象以 DiscardOversized 为原因丢弃，并换成 newFunc 新建的对象，
+// 使长大的对象在空闲时被提前替换，而不是在请求路径上被丢弃后再重新创建。
+// softThreshold 通常取允许的最大大小的八成左右。
+//
+// 替换时会短暂取出全部闲置对象，期间的 Get 可能需要新建对象，因此它适合在负载较低时取得效果。
+// 新建的对象不计入 Stats 的 Misses；新建失败（例如达到 WithAllocCap 的上限）时只丢弃不替换。
+// 需要同时设置 WithMeasure；sync.Pool 后端无法枚举闲置对象，该选项对它不起作用。
+// softThreshold <= 0 表示不启用。
+func WithProactiveReplace[T any](softThreshold int) Option[T] {
+	return func(c *config[T]) {
+		c.softThreshold = softThreshold
+	}
+}
+
+// WithWatermarks 让后台维护 goroutine 每秒检查一次闲置对象的数量，使它保持在 [low, high] 区间内：
+// 低于 low 时用 newFunc 创建新对象补充到 low，高于 high 时以 DiscardOverflow 为原因丢弃多出的对象，裁剪到 high。
+// 这样池会一直保留一组预热好的对象，而不必手动调用 WarmUp 或 Clear。
+//
+// 维护只把闲置数量移动到区间的边界，区间内的波动不会触发任何操作，因此不会来回振荡；
+// 区间越宽，创建和丢弃越少。补充的对象不计入 Stats 的 Misses；新建失败（例如达到 WithAllocCap 的上限）时停止补充。
+// high <= 0 表示不裁剪；high 小于 low 时以 low 为上限。low 和 high 都 <= 0 时不启用。
+// 由于 sync.Pool 的闲置对象由 GC 管理，未指定其他后端时会改用确定性后端；固定容量的后端补充时不会超过它的容量。
+func WithWatermarks[T any](low, high int) Option[T] {
+	return func(c *config[T]) {
+		if low < 0 {
+			low = 0
+		}
+		if high > 0 && high < low {
+			high = low
+		}
+		c.lowWater, c.highWater = low, high
+		if low > 0 || high > 0 {
+			c.needStore = true
+		}
+	}
+}
+
+// WithMinIdle 让后台维护 goroutine 每秒检查一次闲置对象的数量，低于 n 时用 newFunc 创建新对象补充到 n，
+// 使需求高峰或 GC 清空闲置对象之后池中很快又有可用的对象。它等价于只设置下限的 WithWatermarks，
+// 与 WithWatermarks 同时使用时以后调用的为准；Stop 或 Close 会终止后台维护。n <= 0 时不启用。
+func WithMinIdle[T any](n int) Option[T] {
+	return func(c *config[T]) {
+		if n < 0 {
+			n = 0
+		}
+		c.lowWater = n
+		if c.highWater > 0 && c.highWater < n {
+			c.highWater = n
+		}
+		if n > 0 {
+			c.needStore = true
+		}
+	}
+}
+
+// WithAutoCompact 让后台维护 goroutine 每秒检查一次闲置对象所在的底层数组，
+// 在它的容量不小于 64、并且闲置对象不足容量的四分之一时像 Compact 一样收缩它，
+// 使高峰过后多余的内存不必等到手动调用 Compact 才被释放。对 Compact 不起作用的后端，该选项也不起作用。
+func WithAutoCompact[T any]() Option[T] {
+	return func(c *config[T]) {
+		c.autoCompact = true
+	}
+}
+
+// WithRetainGuard 设置一个在 Put 时检查对象是否仍然引用着外部数据的函数：
+// fn 返回 true 的对象不会被存入池中，而是以 DiscardRetains 为原因被丢弃。
+//
+// 池中的闲置对象会一直存活，如果它还引用着大块的外部数据（例如请求体、解析结果或其他对象），
+// 这些数据也会因此无法被 GC 回收，成为意料之外的内存泄漏。
+// 最好的做法是在重置对象时清空这类引用（例如将切片截断为 s[:0] 之前先将元素置零，并将指针字段置为 nil），
+// fn 则用来兜底，发现遗漏时宁可丢弃对象也不保留它。
+//
+// fn 在自动重置（WithAutoReset）和 WithRecycle 之后运行，因此检查的是重置后的对象。
+func WithRetainGuard[T any](fn func(T) bool) Option[T] {
+	return func(c *config[T]) {
+		c.retainGuard = fn
+	}
+}
+
+// WithStats 启用或禁用池的统计信息，默认启用。
+//
+// 统计计数器都是原子操作，开销很小，一般可以在生产环境中一直开启；
+// 只有在极端的热点路径上才需要禁用它们以节省最后一点开销。禁用后 Stats 总是返回零值。
+func WithStats[T any](enabled bool) Option[T] {
+	return func(c *config[T]) {
+		c.noStats = !enabled
+	}
+}
+
+// WithShardedStats 把统计计数器分散到多个分片中：每次 Get 和 Put 只更新其中一个分片，
+// Stats 读取时再把所有分片加起来。默认的计数器都位于同一条缓存行上，在 GOMAXPROCS 很大、
+// 大量 goroutine 同时使用池时会成为竞争点；分片计数器消除了热点路径上的这一竞争，代价是 Stats 略慢。
+//
+// 计数的结果与默认计数器完全一致，只有 Peak 改为采样：它可能略低于真实的最高值。
+// 通过 WithStats(false) 禁用统计时该选项不起作用。
+func WithShardedStats[T any]() Option[T] {
+	return func(c *config[T]) {
+		c.shardedStats = true
+	}
+}
@@ -0,0 +1,83 @@
+// Package otel 把 gpool 池的 Get/Put 延迟和命中情况记录为 OpenTelemetry 指标。
+//
+// 它是一个独立的模块，只有需要 OpenTelemetry 的程序才会依赖它，gpool 本身保持没有第三方依赖。
+package otel
+
+import (
+	"context"
+	"time"
+
+	"github.com/muzhy/gpool"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Recorder 是把池的行为记录为 OpenTelemetry 仪表的 gpool.MetricsRecorder，通过 gpool.WithMetricsRecorder 使用：
+//
+//	r, err := otel.NewRecorder(meter, "buffers")
+//	if err != nil {
+//		return err
+//	}
+//	pool := gpool.New(newBuffer, gpool.WithMetricsRecorder[*bytes.Buffer](r))
+//
+// 它记录以下仪表，每个测量值都带有值为池名称的 gpool.pool 属性：
+//
+//	gpool.gets          Get 的次数（计数器）
+//	gpool.misses        调用 newFunc 创建新对象的次数（计数器），命中次数即 gpool.gets 减去 gpool.misses
+//	gpool.puts          Put 的次数（计数器）
+//	gpool.get.duration  Get 花费的时间，单位为秒（直方图）
+//	gpool.put.duration  Put 花费的时间，单位为秒（直方图）
+type Recorder struct {
+	gets, misses, puts     metric.Int64Counter
+	getLatency, putLatency metric.Float64Histogram
+	attrs                  metric.MeasurementOption
+}
+
+var _ gpool.MetricsRecorder = (*Recorder)(nil)
+
+// NewRecorder 用 meter 创建记录名称为 pool 的池的 Recorder，创建仪表失败时返回错误。
+// 多个池可以共用同一个 meter，它们的测量值通过 gpool.pool 属性区分。
+func NewRecorder(meter metric.Meter, pool string) (*Recorder, error) {
+	r := &Recorder{attrs: metric.WithAttributeSet(attribute.NewSet(attribute.String("gpool.pool", pool)))}
+	var err error
+	if r.gets, err = meter.Int64Counter("gpool.gets",
+		metric.WithDescription("Number of objects taken from the pool."), metric.WithUnit("{object}")); err != nil {
+		return nil, err
+	}
+	if r.misses, err = meter.Int64Counter("gpool.misses",
+		metric.WithDescription("Number of objects created by the pool's newFunc."), metric.WithUnit("{object}")); err != nil {
+		return nil, err
+	}
+	if r.puts, err = meter.Int64Counter("gpool.puts",
+		metric.WithDescription("Number of objects returned to the pool."), metric.WithUnit("{object}")); err != nil {
+		return nil, err
+	}
+	if r.getLatency, err = meter.Float64Histogram("gpool.get.duration",
+		metric.WithDescription("Time spent in Get."), metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	if r.putLatency, err = meter.Float64Histogram("gpool.put.duration",
+		metric.WithDescription("Time spent in Put."), metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// RecordGet 实现 gpool.MetricsRecorder。
+func (r *Recorder) RecordGet(d time.Duration) {
+	ctx := context.Background()
+	r.gets.Add(ctx, 1, r.attrs)
+	r.getLatency.Record(ctx, d.Seconds(), r.attrs)
+}
+
+// RecordPut 实现 gpool.MetricsRecorder。
+func (r *Recorder) RecordPut(d time.Duration) {
+	ctx := context.Background()
+	r.puts.Add(ctx, 1, r.attrs)
+	r.putLatency.Record(ctx, d.Seconds(), r.attrs)
+}
+
+// RecordMiss 实现 gpool.MetricsRecorder。
+func (r *Recorder) RecordMiss() {
+	r.misses.Add(context.Background(), 1, r.attrs)
+}
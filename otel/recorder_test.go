@@ -0,0 +1,60 @@
+package otel
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/muzhy/gpool"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// TestRecorder 测试 Recorder 把 Get、Put 和未命中记录为带有池名称属性的仪表。
+func TestRecorder(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("gpool-test")
+	r, err := NewRecorder(meter, "buffers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := gpool.New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, gpool.WithDeterministic[*bytes.Buffer](), gpool.WithMetricsRecorder[*bytes.Buffer](r))
+
+	b := p.Get()
+	p.Put(b)
+	p.Put(p.Get())
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	want := attribute.NewSet(attribute.String("gpool.pool", "buffers"))
+	sums := map[string]int64{}
+	counts := map[string]uint64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, dp := range data.DataPoints {
+					if !dp.Attributes.Equals(&want) {
+						t.Errorf("%s 的属性不正确: %v", m.Name, dp.Attributes)
+					}
+					sums[m.Name] += dp.Value
+				}
+			case metricdata.Histogram[float64]:
+				for _, dp := range data.DataPoints {
+					counts[m.Name] += dp.Count
+				}
+			}
+		}
+	}
+	if sums["gpool.gets"] != 2 || sums["gpool.misses"] != 1 || sums["gpool.puts"] != 2 {
+		t.Fatalf("计数器的值不正确: %v", sums)
+	}
+	if counts["gpool.get.duration"] != 2 || counts["gpool.put.duration"] != 2 {
+		t.Fatalf("直方图的测量次数不正确: %v", counts)
+	}
+}
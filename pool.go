@@ -1,40 +1,1012 @@
 package gpool
 
-import "sync"
+import (
+	"context"
+	"reflect"
+	"runtime/pprof"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
 
 // Pool 是一个围绕 sync.Pool 的泛型、类型安全的包装器。
-// 它通过嵌入 sync.Pool 来继承其基本行为。
+// 它通过嵌入 sync.Pool 来继承其基本行为；
+// 也可以通过 WithDeterministic、WithSharded 等选项改用其他存储后端。
+//
+// 无论使用哪种后端，Put 一个对象与随后取回它的 Get 之间都存在 happens-before 关系：
+// Put 之前对对象（包括值类型对象引用的底层数组）的写入，对之后 Get 到它的 goroutine 可见，
+// 调用方不需要额外的同步。
 type Pool[T any] struct {
 	sync.Pool
+
+	// newFunc 和 opts 是传给 New 的原始参数，ResetPool 依赖它们恢复初始配置。
+	// 通过 NewE 创建的池使用 newFuncE，此时 newFunc 为 nil。
+	newFunc  func() T
+	newFuncE func() (T, error)
+	opts     []Option[T]
+	// factory 是当前使用的构造函数，初始为 newFunc 或 newFuncE，可以被 RebuildIdle 替换。
+	factory atomic.Pointer[factory[T]]
+
+	cfg       config[T]
+	store     store[T]        // 非 sync.Pool 的存储后端，为 nil 时使用内嵌的 sync.Pool
+	sem       *semaphore      // 有界池的借出额度，为 nil 时不限制
+	scaler    *autoScaler     // 有界池上限的自动伸缩控制器，未启用时为 nil
+	maint     *maintainer     // 后台维护 goroutine，没有需要周期运行的任务时为 nil
+	detached  *detachSet      // 通过 Detach 分离的对象，T 不可比较时为 nil
+	reserved  *reservedSet    // 通过 GetWithReservation 借出的对象，池无界或 T 不是引用类型时为 nil
+	identity  bool            // T 的值是否可以用 == 比较以识别对象，在 init 时计算一次
+	leaks     *leakTracker    // 调试模式下借出的对象，未跟踪时为 nil
+	meta      *metaStore      // WithMetadata 记录的对象元数据，未启用时为 nil
+	ledger    *issueLedger    // WithUniqueIssue 记录的对象状态，未启用时为 nil
+	async     *asyncPutter[T] // 处理 WithAsyncPut 队列的后台 goroutine，未启用时为 nil
+	stats     *counters       // 统计计数器，禁用统计时为 nil
+	nilable   bool            // T 的值是否可能为 nil，在 init 时计算一次
+	ref       bool            // T 是否为指针、map 或 channel 这类引用类型，在 init 时计算一次
+	iface     bool            // T 是否为接口类型，在 init 时计算一次
+	resetMode resetMode       // Put 时自动重置对象的方式，在 init 时计算一次
+	churn     *churnDetector  // 持有时间检测器，未启用时为 nil
+	tapOff    int32           // WithTap 设置的回调是否被暂停，使用原子操作访问
+	warm      chan struct{}   // 预热完成时被关闭，未启用 WithWarmupBarrier 时为 nil
+	warmed    int32           // MarkWarm 是否已被调用，使用原子操作访问
+	labeled   bool            // 是否为 Get 和 Put 设置 pprof 标签
+	plain     bool            // Get 和 Put 是否不需要任何可选处理，可以走最短的路径，在 init 时计算一次
+	labels    pprof.LabelSet  // WithProfileLabels 设置的 pprof 标签
+	limiter   *rateLimiter    // 创建新对象的速率限制，未启用时为 nil
+	allocated *int64          // 调用 newFunc 的总次数，只在设置了 WithAllocCap 时记录
+	syncBytes *int64          // sync.Pool 后端闲置对象大小的估算值，单独分配以满足原子操作的对齐要求
+	closed    int32           // 池是否已被 Close，使用原子操作访问
 }
 
 // New 创建一个新的 Pool。
 // 当池为空时，提供的 newFunc 函数将被调用以创建新对象。
 //
+// 可以通过 opts 启用额外的可选行为，参见各个 With* 函数。
+//
 // 为了获得最佳性能并避免不必要的内存分配，newFunc 最好返回一个指针类型 (*T)。
-func New[T any](newFunc func() T) *Pool[T] {
-	return &Pool[T]{
-		Pool: sync.Pool{
-			New: func() any {
-				return newFunc()
-			},
+func New[T any](newFunc func() T, opts ...Option[T]) *Pool[T] {
+	p := &Pool[T]{newFunc: newFunc, opts: opts}
+	p.init()
+	return p
+}
+
+// NewPointer 创建一个保存 *T 的 Pool，是推荐使用的构造函数。
+//
+// 它的签名保证池中保存的总是指针，从类型上避免了值类型在存入 sync.Pool 时被装箱而产生的额外分配。
+// 与 New 一样，放回 nil 指针是安全的：它不会被存入池中，而是以 DiscardNil 为原因被丢弃。
+func NewPointer[T any](newFunc func() *T, opts ...Option[*T]) *Pool[*T] {
+	return New(newFunc, opts...)
+}
+
+// NewValue 创建一个直接保存值类型 T 的 Pool，适合小的值类型（例如小结构体）。
+//
+// 与 New 默认使用的 sync.Pool 不同（值类型在存入 sync.Pool 时会被装箱，每次 Put 都会产生一次分配），
+// NewValue 创建的池使用互斥锁保护的 []T 空闲列表：Put 将值复制进列表，Get 将值复制出来，
+// 整个过程没有接口转换，也就没有额外的分配。它等价于使用 WithDeterministic 的 New，
+// opts 中的其他后端选项仍可以覆盖这一默认设置。
+func NewValue[T any](newFunc func() T, opts ...Option[T]) *Pool[T] {
+	return New(newFunc, append([]Option[T]{WithDeterministic[T]()}, opts...)...)
+}
+
+// factory 是池创建新对象所用的构造函数，fn 和 fnE 中只有一个不为 nil。
+type factory[T any] struct {
+	fn  func() T
+	fnE func() (T, error)
+}
+
+// NewE 与 New 相同，但构造函数可以返回错误，适合创建可能失败的资源，例如网络连接。
+//
+// Get 等需要创建新对象的方法在 newFunc 返回错误时会 panic，panic 的值是 Kind 为 KindNewFailed、
+// Err 为该错误的 *PoolError；WarmUpConcurrent 则会收集这些错误并通过返回值报告。
+func NewE[T any](newFunc func() (T, error), opts ...Option[T]) *Pool[T] {
+	p := &Pool[T]{newFuncE: newFunc, opts: opts}
+	p.init()
+	return p
+}
+
+// init 根据 newFunc 和 opts 构建一个全新的底层 sync.Pool 及配置。
+func (p *Pool[T]) init() {
+	p.cfg = config[T]{}
+	for _, opt := range p.opts {
+		opt(&p.cfg)
+	}
+	p.store = newStore(&p.cfg)
+	p.sem = nil
+	p.scaler = nil
+	if p.cfg.scaleMax > 0 {
+		// 自动伸缩的池总是有界的，初始上限取 WithMax 的值并限制在 [min, max] 范围内。
+		limit := clampInt(p.cfg.max, p.cfg.scaleMin, p.cfg.scaleMax)
+		p.sem = newSemaphore(int64(limit))
+		p.sem.ceiling = int64(p.cfg.scaleMax)
+		p.scaler = &autoScaler{sem: p.sem, min: int64(p.cfg.scaleMin), max: int64(p.cfg.scaleMax), target: p.cfg.scaleTarget}
+	} else if p.cfg.max > 0 {
+		p.sem = newSemaphore(int64(p.cfg.max))
+	}
+	p.stats = nil
+	if !p.cfg.noStats {
+		p.stats = newCounters(p.cfg.shardedStats)
+	}
+	p.nilable = nilable[T]()
+	p.ref = refIdentity[T]()
+	p.iface = typeOf[T]().Kind() == reflect.Interface
+	p.resetMode = resetNone
+	if p.cfg.autoReset {
+		p.resetMode = detectResetMode[T]()
+	}
+	p.limiter = nil
+	if p.cfg.newRate > 0 {
+		p.limiter = newRateLimiter(p.cfg.newRate)
+	}
+	p.allocated = nil
+	if p.cfg.allocCap > 0 {
+		p.allocated = new(int64)
+	}
+	p.syncBytes = nil
+	if p.store == nil && p.cfg.measure != nil {
+		p.syncBytes = new(int64)
+	}
+	p.churn = nil
+	if p.cfg.churnThreshold > 0 {
+		p.churn = newChurnDetector[T](p.cfg.churnThreshold, func(median time.Duration) {
+			p.logger().Printf("gpool: median hold time %v of pool of %s is below %v, pooling may not be worthwhile for this type",
+				median, typeOf[T](), p.cfg.churnThreshold)
+		})
+	}
+	p.warm = nil
+	if p.cfg.warmupBarrier {
+		p.warm = make(chan struct{})
+	}
+	atomic.StoreInt32(&p.warmed, 0)
+	p.labeled = p.cfg.profileLabel != ""
+	if p.labeled {
+		p.labels = pprof.Labels(profileLabelKey, p.cfg.profileLabel)
+	}
+	atomic.StoreInt32(&p.tapOff, 0)
+	atomic.StoreInt32(&p.closed, 0)
+	p.detached = newDetachSet[T]()
+	p.identity = hasIdentity[T]()
+	p.reserved = nil
+	if p.sem != nil && p.ref {
+		p.reserved = &reservedSet{}
+	}
+	p.factory.Store(&factory[T]{fn: p.newFunc, fnE: p.newFuncE})
+	p.leaks = newLeakTracker[T](p.cfg.debug)
+	p.meta = newMetaStore[T](p.cfg.metadata, p.now)
+	p.ledger = newIssueLedger[T](p.cfg.uniqueIssue)
+	p.startAsyncPut()
+	p.startMaintainer()
+	p.plain = p.isPlain()
+
+	p.Pool = sync.Pool{
+		New: func() any {
+			p.reconcile()
+			x := p.create()
+			if p.cfg.validate != nil {
+				// 标记新建的对象，使 get 不校验它，见 WithValidator。
+				return freshObject[T]{x}
+			}
+			return x
 		},
 	}
 }
 
+// freshObject 包装由 sync.Pool 的 New 新建的对象，使 get 可以把它与取回的闲置对象区分开。
+type freshObject[T any] struct {
+	x T
+}
+
+// isPlain 报告池是否没有启用任何影响 Get 和 Put 的可选功能，即 Get 和 Put 可以直接使用 sync.Pool，
+// 只需要维护统计计数、丢弃 nil 对象、处理 Close 和 Detach。它必须在 init 完成其他初始化之后调用。
+//
+// 新增影响 Get 或 Put 热点路径的功能时，必须在这里把它排除在外，否则 plain 的池会跳过它。
+func (p *Pool[T]) isPlain() bool {
+	c := &p.cfg
+	return p.store == nil && p.sem == nil && p.async == nil && !p.labeled && p.warm == nil && c.recorder == nil &&
+		p.syncBytes == nil && p.churn == nil && p.leaks == nil && p.meta == nil && p.ledger == nil &&
+		p.resetMode == resetNone && !c.debug && c.tap == nil && c.prepare == nil && c.finish == nil &&
+		len(c.onGet) == 0 && len(c.onPut) == 0 && c.validate == nil && c.validateOnPut == nil && c.enabled == nil && c.oversized == nil &&
+		len(c.resetFields) == 0 && c.recycle == nil && c.retainGuard == nil && c.beforeStore == nil
+}
+
+// create 调用 newFunc 创建一个新对象，并记录一次未命中。
+// 启用了 WithNewRateLimit 时，它会先等待创建额度。
+func (p *Pool[T]) create() T {
+	if p.limiter != nil {
+		p.limiter.wait()
+	}
+	return p.newObject()
+}
+
+// newObject 不经速率限制地调用 newFunc 创建一个新对象，并记录一次未命中。
+func (p *Pool[T]) newObject() T {
+	x := p.construct()
+	if p.stats != nil {
+		p.stats.add(statMisses, 1)
+	}
+	if p.cfg.recorder != nil {
+		p.cfg.recorder.RecordMiss()
+	}
+	return x
+}
+
+// construct 调用 newFunc 创建一个新对象。
+// 创建失败时（包括达到 WithAllocCap 的上限）它会 panic，panic 的值是 Kind 为 KindNewFailed 的 *PoolError。
+func (p *Pool[T]) construct() T {
+	x, err := p.tryConstruct()
+	if err != nil {
+		panic(&PoolError{Kind: KindNewFailed, Err: err})
+	}
+	return x
+}
+
+// tryConstruct 调用 newFunc 或 newFuncE 创建一个新对象，所有调用构造函数的路径都必须经过这里。
+// 设置了 WithAllocCap 时，创建的对象总数达到上限后它返回 ErrAllocCap；创建失败的对象不计入上限。
+func (p *Pool[T]) tryConstruct() (T, error) {
+	if p.allocated != nil && atomic.AddInt64(p.allocated, 1) > int64(p.cfg.allocCap) {
+		atomic.AddInt64(p.allocated, -1)
+		var zero T
+		return zero, ErrAllocCap
+	}
+	var x T
+	if f := p.factory.Load(); f.fnE == nil {
+		x = f.fn()
+	} else {
+		var err error
+		if x, err = f.fnE(); err != nil {
+			if p.allocated != nil {
+				atomic.AddInt64(p.allocated, -1)
+			}
+			return x, err
+		}
+	}
+	x = p.checkNil(x)
+	p.countBuilt(x)
+	if p.meta != nil && !isNil(x) {
+		p.meta.created(x)
+	}
+	return x, nil
+}
+
+// checkNil 检查构造函数返回的对象 x 是否为 nil：严格模式下 panic；
+// T 是接口类型时，把包装了 nil 指针等 nil 值的非 nil 接口规范化为 T 的零值（nil 接口），
+// 使调用方可以用 x == nil 可靠地判断，而不会在之后解引用时 panic。
+func (p *Pool[T]) checkNil(x T) T {
+	if !p.iface && !(p.cfg.strictNil && p.nilable) {
+		// 只有这两种情况需要检查，避免在热点路径上无谓地使用反射。
+		return x
+	}
+	if !isNil(x) {
+		return x
+	}
+	if p.cfg.strictNil {
+		p.panicNil()
+	}
+	var zero T
+	return zero
+}
+
+// isNilObject 报告 x 是否为 nil。引用类型的值直接与零值比较，只有其他可能为 nil 的类型才使用反射，
+// 使 Put 的热点路径不必为常见的指针类型付出反射的开销。
+func (p *Pool[T]) isNilObject(x T) bool {
+	if p.ref {
+		return isNilRef(x)
+	}
+	return p.nilable && isNil(x)
+}
+
+// isNilRef 报告引用类型（见 refIdentity）的值 x 是否为 nil。
+func isNilRef[T any](x T) bool {
+	return refPointer(x) == nil
+}
+
+// refPointer 返回引用类型（见 refIdentity）的值 x 所引用的对象的地址。这些类型的值只有一个指针大小的字，为 nil 时这个字为 0。
+func refPointer[T any](x T) unsafe.Pointer {
+	return *(*unsafe.Pointer)(unsafe.Pointer(&x))
+}
+
+// panicNil 在严格模式下报告构造函数返回了 nil。
+func (p *Pool[T]) panicNil() {
+	panic("gpool: newFunc returned nil for pool of " + typeOf[T]().String() + " (WithStrictNil)")
+}
+
 // Get 从池中获取一个 T 类型的对象，并提供类型安全。
+// 对于有界池，借出的对象达到上限时 Get 会阻塞，直到有对象被放回。
 func (p *Pool[T]) Get() T {
-	v := p.Pool.Get()
+	if p.plain {
+		// 泛型方法在这里通常不会被内联，因此手动展开最常见的情况，
+		// 使没有任何选项的池只比直接使用 sync.Pool 多几次字段检查。
+		if p.stats != nil {
+			p.countGets(1)
+		}
+		v := p.Pool.Get()
+		if x, ok := v.(T); ok && !p.iface && !p.cfg.strictNil {
+			return x
+		}
+		return p.fromSyncPool(v)
+	}
+	if p.cfg.recorder != nil {
+		return p.getRecorded()
+	}
+	if p.labeled {
+		return p.getLabeled()
+	}
+	return p.getOne()
+}
+
+// getOne 实现 Get。
+func (p *Pool[T]) getOne() T {
+	p.awaitWarm()
+	if p.sem != nil {
+		// 使用 context.Background() 时，单个对象的请求只会在池被关闭时失败，
+		// 此时不再限制借出数量，Get 照常返回新创建的对象。
+		_ = p.sem.acquire(context.Background(), 1)
+	}
+	p.countGets(1)
+	x := p.get()
+	p.borrowed(x)
+	return x
+}
+
+// GetPreferred 与 Get 相同，但优先返回 prev：如果 prev 仍然闲置在池中，就取出并返回它；
+// 否则与 Get 一样返回任意一个闲置对象，没有闲置对象时创建新对象。
+// 它适合希望复用同一个实例的调用方，例如复用按自己的负载扩容过的内部缓冲区。
+//
+// 查找 prev 需要以对象本身作为标识，并且要求后端能够取出指定的对象：
+// T 不可比较（或是接口类型）时，或者使用 sync.Pool、弱引用等不支持的后端时，GetPreferred 等同于 Get。
+// 查找需要遍历闲置对象，耗时与闲置对象的数量成正比。取回的 prev 同样要经过 WithValidator 的校验。
+func (p *Pool[T]) GetPreferred(prev T) T {
+	s, ok := p.store.(taker[T])
+	if !ok || !p.identity || p.disabled() {
+		return p.Get()
+	}
+	p.awaitWarm()
+	if p.sem != nil {
+		_ = p.sem.acquire(context.Background(), 1)
+	}
+	p.countGets(1)
+	x, ok := prev, s.take(prev)
+	if ok && p.cfg.validate != nil && !p.cfg.validate(x) {
+		p.discard(x, DiscardInvalid)
+		ok = false
+	}
+	if !ok {
+		x = p.get()
+	}
+	p.borrowed(x)
+	return x
+}
+
+// TryGet 只在池中已经有闲置对象时取出并返回它，既不阻塞也不调用 newFunc，
+// 使调用方可以在没有闲置对象时改走更便宜的路径（例如在栈上分配），而不必付出创建重量级对象的代价。
+// 取出的对象与 Get 得到的一样经过校验等处理并算作借出，必须通过 Put 放回。
+//
+// 没有闲置对象、有界池没有空闲额度，或者 WithEnabledFunc 关闭了池化时，TryGet 返回 false。
+// sync.Pool 在没有闲置对象时总会调用 New，无法只取出已有的对象，因此使用 sync.Pool 后端的池上 TryGet 总是返回 false；
+// 需要 TryGet 时请使用 WithDeterministic 等其他后端。
+func (p *Pool[T]) TryGet() (T, bool) {
+	var zero T
+	if p.store == nil || p.disabled() {
+		return zero, false
+	}
+	if p.sem != nil && !p.sem.tryAcquire(1) {
+		return zero, false
+	}
+	if x, ok := p.takeIdle(); ok {
+		p.countGets(1)
+		p.borrowed(x)
+		return x, true
+	}
+	if p.sem != nil {
+		p.sem.release(1)
+	}
+	return zero, false
+}
+
+// disabled 报告 WithEnabledFunc 设置的函数当前是否关闭了池化。
+func (p *Pool[T]) disabled() bool {
+	return p.cfg.enabled != nil && !p.cfg.enabled()
+}
+
+// get 从存储中取出一个对象，存储为空时创建新对象。它不处理有界池的额度。
+func (p *Pool[T]) get() T {
+	if p.disabled() {
+		return p.create()
+	}
+	if p.store == nil {
+		v := p.Pool.Get()
+		if f, ok := v.(freshObject[T]); ok {
+			// 刚由 New 新建的对象既不在大小估算值中，也不需要校验。
+			return p.checkNil(f.x)
+		}
+		x := p.fromSyncPool(v)
+		if p.syncBytes != nil {
+			p.subSyncBytes(int64(p.cfg.measure(x)))
+		}
+		if p.cfg.validate != nil && !p.cfg.validate(x) {
+			// 校验失败时直接创建新对象，而不是继续从 sync.Pool 中取出，避免在失效的对象上反复重试。
+			p.discard(x, DiscardInvalid)
+			return p.create()
+		}
+		return x
+	}
+
+	for {
+		if x, ok := p.takeIdle(); ok {
+			return x
+		}
+		// 一边等待创建额度，一边检查是否有对象被放回，哪个先到就用哪个。
+		if p.limiter == nil || p.limiter.allow() {
+			return p.newObject()
+		}
+		p.limiter.pause()
+	}
+}
+
+// takeIdle 从存储中取出一个可以交给调用方的闲置对象，沿途丢弃过期或未通过校验的对象，
+// 没有这样的闲置对象时返回 false。它不处理有界池的额度，也不记录借出。
+func (p *Pool[T]) takeIdle() (T, bool) {
+	for {
+		x, ok := p.store.get()
+		if !ok {
+			return x, false
+		}
+		if p.stale(x) {
+			p.discard(x, DiscardExpired)
+			continue
+		}
+		if p.cfg.validate != nil && !p.cfg.validate(x) {
+			p.discard(x, DiscardInvalid)
+			continue
+		}
+		if p.cfg.zeroOnGet {
+			x = zeroObject(x)
+		}
+		return x, true
+	}
+}
+
+// fromSyncPool 把从内嵌的 sync.Pool 中取出的 v 转换为 T。
+func (p *Pool[T]) fromSyncPool(v any) T {
 	if v == nil {
+		if p.cfg.strictNil {
+			p.panicNil()
+		}
 		// 如果池返回 nil，安全地返回 T 类型的零值，
 		// 避免当 T 是值类型时发生 `nil.(T)` 的 panic。
 		var zero T
 		return zero
 	}
-	return v.(T)
+	return p.checkNil(v.(T))
 }
 
 // Put 将一个 T 类型的对象放回池中。
+// nil 对象（例如 nil 指针）不会被放入池中，而是以 DiscardNil 为原因被丢弃，
+// 避免之后的 Get 取回一个 nil 对象。
+//
+// 对于有界池，每次 Put 都会归还一个借出额度，即使对象因故被丢弃。
+// 放回已关闭的池的对象会以 DiscardClosed 为原因被丢弃。
 func (p *Pool[T]) Put(x T) {
+	if p.plain {
+		// 与 Get 一样手动展开最常见的情况：没有被分离的对象、没有启用统计时不调用任何方法。
+		if d := p.detached; d != nil && atomic.LoadInt32(&d.n) != 0 && p.wasDetached(x) {
+			return
+		}
+		if p.stats != nil {
+			p.stats.add(statPuts, 1)
+		}
+		if p.ref && !isNilRef(x) && atomic.LoadInt32(&p.closed) == 0 {
+			p.Pool.Put(x)
+			return
+		}
+		p.putPlain(x)
+		return
+	}
+	if p.cfg.recorder != nil {
+		p.putRecorded(x)
+		return
+	}
+	if p.labeled {
+		p.putLabeled(x)
+		return
+	}
+	p.putOne(x)
+}
+
+// putOne 实现 Put。
+func (p *Pool[T]) putOne(x T) {
+	if p.wasDetached(x) {
+		return
+	}
+	p.countPuts(1)
+	p.returned(x)
+	if p.async != nil && p.async.enqueue(x) {
+		return
+	}
+	p.finishPut(x)
+}
+
+// putPlain 处理 plain 的池的 Put 没有展开的情况：nil 对象、已关闭的池，以及 T 不是引用类型时的 nil 检查。
+// 与 putOne 对这类池的处理完全相同，但跳过了所有未启用的可选处理。
+func (p *Pool[T]) putPlain(x T) {
+	switch {
+	case p.isNilObject(x):
+		p.discard(x, DiscardNil)
+	case p.isClosed():
+		p.discard(x, DiscardClosed)
+	default:
+		p.Pool.Put(x)
+	}
+}
+
+// PutE 与 Put 相同，但报告对象是否被存入池中：存入时返回 nil，否则返回说明原因的 *PoolError，
+// 使运维代码可以记录对象被拒绝的具体原因。池已关闭时返回 ErrClosed；其他情况返回 KindRejected 类别的错误，
+// 它的 Reason 是对象被丢弃的原因，例如 DiscardNil、DiscardInvalid、DiscardOversized、
+// DiscardOverflow（闲置对象已满，包括超出 WithMaxIdle 的上限）等。被拒绝的对象与 Put 时一样已经被丢弃。
+//
+// 为了得到结果，即使启用了 WithAsyncPut，PutE 也会同步地存入对象。
+// sync.Pool 后端接收对象后仍可能在 GC 时丢弃它，这种情况不会被报告。
+func (p *Pool[T]) PutE(x T) error {
+	if p.wasDetached(x) {
+		return rejectError(DiscardDetached)
+	}
+	p.countPuts(1)
+	p.returned(x)
+	reason := p.put(x)
+	p.releaseSlot(x)
+	return rejectError(reason)
+}
+
+// TryPut 与 PutE 相同，但只报告对象是否被存入池中。
+func (p *Pool[T]) TryPut(x T) bool {
+	return p.PutE(x) == nil
+}
+
+// finishPut 存入 Put 放回的一个对象 x，然后归还有界池的一个额度。
+func (p *Pool[T]) finishPut(x T) {
+	p.put(x)
+	p.releaseSlot(x)
+}
+
+// Transfer 将从 p 借出的对象 x 交给另一个池 dst 保存，而不是放回 p，
+// 使生产者和消费者可以通过各自的池循环使用对象，而无需集中协调。
+//
+// 对 p 而言 x 就像被 Put 了一样：它不再算作借出，有界池的额度也会被归还。
+// x 按 dst 的规则（nil 检查、校验、自动重置等）存入 dst 或被 dst 丢弃，
+// 对 dst 而言它就像被预热的对象一样成为闲置对象，不计入 dst 的 Puts。
+func (p *Pool[T]) Transfer(dst *Pool[T], x T) {
+	p.countPuts(1)
+	p.returned(x)
+	// x 此后属于 dst，p 不再为它保留记录。
+	if p.ledger != nil {
+		p.ledger.forget(x)
+	}
+	if p.meta != nil {
+		p.meta.forget(x)
+	}
+	dst.put(x)
+	p.releaseSlot(x)
+}
+
+// discardReturned 像 Put 一样接收调用方归还的对象，但不存入池中，而是以 reason 为原因丢弃它。
+func (p *Pool[T]) discardReturned(x T, reason string) {
+	p.countPuts(1)
+	p.returned(x)
+	p.discard(x, reason)
+	p.releaseSlot(x)
+}
+
+// put 将对象存入存储，或按原因丢弃它，返回丢弃的原因，存入时返回空字符串。它不处理有界池的额度。
+func (p *Pool[T]) put(x T) (reason string) {
+	if p.isNilObject(x) {
+		p.discard(x, DiscardNil)
+		return DiscardNil
+	}
+	if p.isClosed() {
+		p.discard(x, DiscardClosed)
+		return DiscardClosed
+	}
+	if p.disabled() {
+		p.discard(x, DiscardDisabled)
+		return DiscardDisabled
+	}
+	if p.cfg.validateOnPut != nil && !p.cfg.validateOnPut(x) {
+		p.discard(x, DiscardInvalid)
+		return DiscardInvalid
+	}
+	if p.cfg.oversized != nil && p.cfg.oversized(x) {
+		p.discard(x, DiscardOversized)
+		return DiscardOversized
+	}
+	if p.expires() && p.expired(x) {
+		p.discard(x, DiscardExpired)
+		return DiscardExpired
+	}
+	if p.retires() && p.retired(x) {
+		p.discard(x, DiscardRetired)
+		return DiscardRetired
+	}
+	if p.resetMode != resetNone {
+		p.reset(x)
+	}
+	for _, fn := range p.cfg.resetFields {
+		fn(x)
+	}
+	if p.cfg.debug && p.cfg.resetVerify != nil && !p.cfg.resetVerify(x) {
+		panic("gpool: object of type " + typeOf[T]().String() + " was not fully cleaned by reset (WithResetVerify)")
+	}
+	if p.cfg.recycle != nil {
+		if x = p.cfg.recycle(x); p.isNilObject(x) {
+			p.discard(x, DiscardNil)
+			return DiscardNil
+		}
+	}
+	if p.cfg.retainGuard != nil && p.cfg.retainGuard(x) {
+		p.discard(x, DiscardRetains)
+		return DiscardRetains
+	}
+	if p.cfg.beforeStore != nil {
+		x = p.cfg.beforeStore(x)
+	}
+	if p.store != nil {
+		if p.cfg.dedup != nil && p.hasEqual(x) {
+			p.discard(x, DiscardDuplicate)
+			return DiscardDuplicate
+		}
+		if p.meta != nil {
+			// 在存入之前记录，使取出它的 goroutine 看到的总是放回时的时间。
+			p.meta.used(x)
+		}
+		if !p.store.put(x) {
+			p.discard(x, DiscardOverflow)
+			return DiscardOverflow
+		}
+		// 与 Close 并发时，对象可能在 Close 清空存储之后才被存入，这里再清理一次。
+		if p.isClosed() {
+			p.discardAll(p.store.drain(), DiscardClosed)
+			return DiscardClosed
+		}
+		return ""
+	}
+	if p.syncBytes != nil {
+		atomic.AddInt64(p.syncBytes, int64(p.cfg.measure(x)))
+	}
 	p.Pool.Put(x)
+	return ""
+}
+
+// hasEqual 报告存储中是否有按 WithDedup 的比较函数与 x 相等的闲置对象。
+func (p *Pool[T]) hasEqual(x T) bool {
+	found := false
+	p.store.each(func(y T) {
+		found = found || p.cfg.dedup(x, y)
+	})
+	return found
+}
+
+// reconcile 在 sync.Pool 因为没有闲置对象而调用 New 时校正池为 sync.Pool 后端维护的估算值。
+//
+// GC 会在不通知池的情况下清空 sync.Pool，之后估算值仍然认为池中保留着对象。
+// sync.Pool 只有在所有 P 的本地缓存和 victim 缓存都为空时才会调用 New，
+// 因此这时可以确定池中已经没有闲置对象，将估算值归零，避免 GC 之后统计永久偏高。
+func (p *Pool[T]) reconcile() {
+	if p.syncBytes != nil && atomic.LoadInt64(p.syncBytes) != 0 {
+		atomic.StoreInt64(p.syncBytes, 0)
+	}
+}
+
+// subSyncBytes 从 sync.Pool 后端的大小估算值中减去 n，但不会使其小于 0：
+// 取回的对象也可能是刚由 New 创建的。
+func (p *Pool[T]) subSyncBytes(n int64) {
+	for {
+		cur := atomic.LoadInt64(p.syncBytes)
+		next := cur - n
+		if next < 0 {
+			next = 0
+		}
+		if atomic.CompareAndSwapInt64(p.syncBytes, cur, next) {
+			return
+		}
+	}
+}
+
+// borrowed 在对象被借出给调用方时调用。
+func (p *Pool[T]) borrowed(x T) {
+	if p.ledger != nil && !p.isNilObject(x) {
+		p.ledger.issue(x)
+	}
+	if p.cfg.prepare != nil && !p.isNilObject(x) {
+		p.cfg.prepare(x)
+	}
+	p.tap(TapGet, x)
+	if p.churn != nil {
+		p.churn.borrowed(x)
+	}
+	if p.leaks != nil {
+		p.leaks.borrowed(x)
+	}
+	if p.meta != nil && !isNil(x) {
+		p.meta.borrowed(x)
+	}
+	if len(p.cfg.onGet) > 0 && !p.isNilObject(x) {
+		for _, fn := range p.cfg.onGet {
+			fn(x)
+		}
+	}
+}
+
+// returned 在调用方放回对象时调用。
+func (p *Pool[T]) returned(x T) {
+	if p.ledger != nil && !p.isNilObject(x) {
+		p.ledger.returned(x)
+	}
+	if len(p.cfg.onPut) > 0 && !p.isNilObject(x) {
+		for _, fn := range p.cfg.onPut {
+			fn(x)
+		}
+	}
+	p.tap(TapPut, x)
+	if p.churn != nil {
+		p.churn.returned(x)
+	}
+	if p.leaks != nil {
+		p.leaks.returned(x)
+	}
+	if p.cfg.finish != nil && !p.isNilObject(x) {
+		p.cfg.finish(x)
+	}
+}
+
+// tap 在启用时调用 WithTap 设置的回调。
+func (p *Pool[T]) tap(op string, x T) {
+	if p.cfg.tap != nil && atomic.LoadInt32(&p.tapOff) == 0 {
+		p.cfg.tap(op, x)
+	}
+}
+
+// SetTap 暂停或恢复调用 WithTap 设置的回调，便于只在需要诊断的时间段内观察对象。
+// 使用 WithTap 创建的池默认启用回调；未设置 WithTap 时 SetTap 不起作用。
+func (p *Pool[T]) SetTap(enabled bool) {
+	var off int32
+	if !enabled {
+		off = 1
+	}
+	atomic.StoreInt32(&p.tapOff, off)
+}
+
+// isClosed 报告池是否已被 Close。
+func (p *Pool[T]) isClosed() bool {
+	return atomic.LoadInt32(&p.closed) != 0
+}
+
+// Clear 丢弃池中所有闲置对象，池的配置保持不变，之后仍可正常使用。
+// 被丢弃的对象会以 DiscardCleared 为原因通知 WithOnDiscard 的回调，
+// 实现了 io.Closer 的对象会被关闭，Clear 返回遇到的第一个关闭错误。
+//
+// sync.Pool 后端中的闲置对象由 GC 管理，无法被枚举，Clear 对它不起作用。
+func (p *Pool[T]) Clear() error {
+	if p.store == nil {
+		return nil
+	}
+	return p.discardAll(p.store.drain(), DiscardCleared)
+}
+
+// Compact 重新分配闲置对象所在的底层数组，使它的容量与当前的闲置数量相符，
+// 把之前为大量闲置对象分配、现在大部分空着的数组交给 GC 回收。
+// 经历过一次突发的高峰之后，确定性后端等基于切片的后端的底层数组会一直保持高峰时的容量，Compact 用来释放这部分内存；
+// 也可以通过 WithAutoCompact 让后台维护 goroutine 定期执行。
+//
+// 固定容量的后端有意一次性分配全部容量，sync.Pool 后端的存储由运行时管理，Compact 对它们不起作用。
+func (p *Pool[T]) Compact() {
+	if c, ok := baseStore(p.store).(compacter); ok {
+		c.compact(true)
+	}
+}
+
+// Close 关闭池：丢弃所有闲置对象，之后放回的对象也都会以 DiscardClosed 为原因被丢弃。
+// 实现了 io.Closer 的对象会被关闭，Close 返回遇到的第一个关闭错误。
+//
+// 关闭后 Get 仍然可用，但由于不再有对象被存入池中，最终只会通过 newFunc 创建新对象；
+// 有界池不再限制借出数量，正在等待额度的 Get 会立即返回。
+// 重复调用 Close 是安全的，之后的调用直接返回 nil。
+// 与 Clear 一样，sync.Pool 后端中已有的闲置对象只能交由 GC 回收。
+func (p *Pool[T]) Close() error {
+	if !atomic.CompareAndSwapInt32(&p.closed, 0, 1) {
+		return nil
+	}
+	p.MarkWarm()
+	p.Stop()
+	if p.sem != nil {
+		p.sem.close()
+	}
+	if p.store == nil {
+		return nil
+	}
+	return p.discardAll(p.store.drain(), DiscardClosed)
+}
+
+// Shutdown 像 Close 一样关闭池，但不丢弃闲置对象，而是把它们逐个交给 handler，
+// 使仍然有用的资源（例如连接）可以移交给其他子系统，而不是被关闭。
+//
+// 交给 handler 的对象不再属于池：它们不会被关闭，也不会通知 WithOnDiscard 的回调。
+// handler 在不持有任何锁的情况下依次被调用，可以执行较慢的操作。
+// 之后放回的对象，包括与 Shutdown 并发、在取出闲置对象之后才被存入的对象，都会像 Close 之后一样以 DiscardClosed 为原因被丢弃。
+// 池已经关闭时 Shutdown 不做任何事；sync.Pool 后端的闲置对象无法被取出，handler 不会被调用。
+func (p *Pool[T]) Shutdown(handler func(x T)) {
+	if !atomic.CompareAndSwapInt32(&p.closed, 0, 1) {
+		return
+	}
+	p.MarkWarm()
+	p.Stop()
+	if p.sem != nil {
+		p.sem.close()
+	}
+	if p.store == nil {
+		return
+	}
+	for _, x := range p.store.drain() {
+		if p.meta != nil {
+			p.meta.forget(x)
+		}
+		if p.ledger != nil {
+			p.ledger.forget(x)
+		}
+		handler(x)
+	}
+}
+
+// GetAll 一次性从池中获取 n 个对象。
+//
+// 对于有界池，GetAll 会阻塞直到能够同时获得 n 个额度，然后一次性取出全部对象；
+// 它不会先取走一部分再等待其余部分，因此多个 GetAll 之间不会因部分获取而互相死锁。
+// 如果 n 超过有界池的上限，请求永远无法被满足，GetAll 会 panic。
+// 启用 WithAutoScale 时以它的 max 为准：n 超过当前上限但不超过 max 时，GetAll 会等待上限提高。
+// 与 Get 一样，池被关闭后 GetAll 不再等待额度。
+func (p *Pool[T]) GetAll(n int) []T {
+	if n <= 0 {
+		return nil
+	}
+	p.awaitWarm()
+	if p.sem != nil {
+		if err := p.sem.acquire(context.Background(), int64(n)); err == errExceedsMax {
+			panic(err)
+		}
+	}
+	return p.getN(n)
+}
+
+// TryGetAll 与 GetAll 类似，但在有界池没有足够的空闲额度时不会阻塞，
+// 而是一个对象也不取出并返回 ErrExhausted。池已关闭时返回 ErrClosed。
+func (p *Pool[T]) TryGetAll(n int) ([]T, error) {
+	if p.isClosed() {
+		return nil, ErrClosed
+	}
+	if n <= 0 {
+		return nil, nil
+	}
+	if p.sem != nil && !p.sem.tryAcquire(int64(n)) {
+		return nil, ErrExhausted
+	}
+	return p.getN(n), nil
+}
+
+// getN 取出 n 个对象，调用方必须已经获得了相应的额度。
+func (p *Pool[T]) getN(n int) []T {
+	p.countGets(n)
+	xs := make([]T, n)
+	for i := range xs {
+		xs[i] = p.get()
+		p.borrowed(xs[i])
+	}
+	return xs
+}
+
+// PutAll 将 xs 中的所有对象放回池中，通常与 GetAll 配合使用。
+func (p *Pool[T]) PutAll(xs []T) {
+	n, slots := 0, 0
+	for _, x := range xs {
+		if p.wasDetached(x) {
+			continue
+		}
+		p.returned(x)
+		p.put(x)
+		n++
+		if !p.releaseReserved(x) {
+			slots++
+		}
+	}
+	p.countPuts(n)
+	if p.sem != nil && slots > 0 {
+		p.sem.release(int64(slots))
+	}
+}
+
+// GetWithReturn 从池中获取一个对象，并返回一个将其放回池中的函数，适合配合 defer 使用：
+//
+//	obj, ret := p.GetWithReturn()
+//	defer ret()
+//
+// 返回的函数可以安全地多次调用，只有第一次调用会执行 Put。
+func (p *Pool[T]) GetWithReturn() (T, func()) {
+	x := p.Get()
+	var returned int32
+	return x, func() {
+		if atomic.CompareAndSwapInt32(&returned, 0, 1) {
+			p.Put(x)
+		}
+	}
+}
+
+// Do 从池中获取一个对象并调用 fn，fn 返回后将对象放回池中，返回 fn 的错误。
+// 它省去了手动配对 Get 和 Put 的麻烦，fn 不应在返回后继续持有该对象。
+//
+// fn 发生 panic 时，对象默认仍会被放回池中，然后 panic 继续向上传播；
+// 启用 WithDiscardOnPanic 后，对象会改为以 DiscardPanicked 为原因被丢弃，
+// 避免被修改到一半的对象回到池中。
+func (p *Pool[T]) Do(fn func(x T) error) error {
+	x := p.Get()
+	panicked := true
+	defer func() {
+		if panicked && p.cfg.discardOnPanic {
+			p.discardReturned(x, DiscardPanicked)
+			return
+		}
+		p.Put(x)
+	}()
+	err := fn(x)
+	panicked = false
+	return err
+}
+
+// Update 从池中获取一个对象并调用 fn 修改它，再根据 fn 的返回值决定对象的去向：
+// keep 为 true 时像 Put 一样把对象（经过重置）放回池中；为 false 时以 DiscardRolledBack 为原因丢弃它，
+// 使可能处于不一致状态的修改不会回到池中。
+//
+// fn 发生 panic 时，对象以 DiscardPanicked 为原因被丢弃（不论是否启用了 WithDiscardOnPanic），
+// 然后 panic 继续向上传播。无论哪种情况，对象都恰好被归还一次，fn 不应在返回后继续持有它。
+func (p *Pool[T]) Update(fn func(x T) (keep bool)) {
+	x := p.Get()
+	reason := DiscardPanicked
+	defer func() {
+		if reason != "" {
+			p.discardReturned(x, reason)
+			return
+		}
+		p.Put(x)
+	}()
+	if fn(x) {
+		reason = ""
+	} else {
+		reason = DiscardRolledBack
+	}
+}
+
+// ResetPool 将池恢复到刚被 New 创建时的状态：丢弃池中所有对象，将所有统计计数器清零，
+// 重新打开已关闭的池，并恢复最初传入的 newFunc 和 opts（即使内嵌 sync.Pool 的 New 字段已被修改）。
+//
+// 与只丢弃闲置对象的 Clear 不同，ResetPool 会还原池的全部状态和配置，
+// 主要用于在测试用例之间复用同一个池，而无需重新构造。
+// 被丢弃的闲置对象与 Clear 一样会被关闭并通知 WithOnDiscard 的回调。
+//
+// ResetPool 不是并发安全的：它面向单线程的测试准备阶段，
+// 调用期间不得有其他 goroutine 正在使用该池，也不应有尚未放回的对象。
+func (p *Pool[T]) ResetPool() {
+	p.Stop()
+	if p.store != nil {
+		p.discardAll(p.store.drain(), DiscardCleared)
+	}
+	p.init()
+}
+
+// Inspect 在不借出对象的情况下对池中的一个闲置对象调用 fn，适合轻量的健康检查，
+// 例如确认闲置的连接仍然可用。没有闲置对象可以查看时返回 false，此时 fn 不会被调用。
+//
+// 对象不会被取出，也不计入 Stats 的 Gets 或 Outstanding，不占用有界池的额度，也不会触发 WithTap 的回调。
+// fn 在持有存储的锁时被调用，期间它查看的对象仍然属于池：fn 必须把对象视为只读，
+// 不得修改它、保留对它的引用或访问这个池，并且应该尽快返回。
+//
+// 只有自己持有闲置对象的内置后端（确定性、分片和固定容量后端）支持 Inspect；
+// sync.Pool 后端的闲置对象无法在不取出的情况下查看，总是返回 false。
+func (p *Pool[T]) Inspect(fn func(T)) bool {
+	s, ok := p.store.(peeker[T])
+	return ok && s.peek(fn)
 }
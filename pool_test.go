@@ -2,11 +2,15 @@ package gpool
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"runtime"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 )
 
 // TestPool_Basic 测试 Get 和 Put 的基本功能。
@@ -145,3 +149,959 @@ func TestPool_Get_WithNilFromNew(t *testing.T) {
 		}
 	})
 }
+
+// TestPool_ResetPool 测试 ResetPool 会丢弃池中对象并恢复最初的 newFunc。
+func TestPool_ResetPool(t *testing.T) {
+	var newCounter int32
+	p := New(func() *bytes.Buffer {
+		atomic.AddInt32(&newCounter, 1)
+		return new(bytes.Buffer)
+	})
+
+	// 1. 放入一个对象后重置，池中的对象应被丢弃。
+	buf := p.Get()
+	p.Put(buf)
+	p.ResetPool()
+
+	if got := p.Get(); got == buf {
+		t.Fatal("ResetPool 之后不应该再取到重置前放入的对象")
+	}
+	if n := atomic.LoadInt32(&newCounter); n != 2 {
+		t.Fatalf("ResetPool 之后 Get() 应该调用 newFunc, 期望共调用 2 次, 实际 %d 次", n)
+	}
+
+	// 2. 覆盖内嵌 sync.Pool 的 New 后重置，应恢复最初的 newFunc。
+	p.Pool.New = func() any { return nil }
+	p.ResetPool()
+
+	if got := p.Get(); got == nil {
+		t.Fatal("ResetPool 之后应恢复最初的 newFunc, 但 Get() 返回了 nil")
+	}
+	if n := atomic.LoadInt32(&newCounter); n != 3 {
+		t.Fatalf("期望 newFunc 共调用 3 次, 实际 %d 次", n)
+	}
+}
+
+// TestPool_GetWithReturn 测试 GetWithReturn 返回的函数无论被调用多少次都只放回一次对象。
+func TestPool_GetWithReturn(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer]())
+
+	buf, ret := p.GetWithReturn()
+	if buf == nil {
+		t.Fatal("GetWithReturn() 应该返回一个对象")
+	}
+	for i := 0; i < 3; i++ {
+		ret()
+	}
+
+	if n := p.store.len(); n != 1 {
+		t.Fatalf("多次调用返回函数应该只放回一次对象, 期望池中有 1 个对象, 实际有 %d 个", n)
+	}
+	if got := p.Get(); got != buf {
+		t.Fatal("应该取回通过返回函数放回的对象")
+	}
+}
+
+// TestPool_GetAll 测试 GetAll 和 PutAll 作为一个整体获取和放回对象。
+func TestPool_GetAll(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer]())
+
+	bufs := p.GetAll(3)
+	if len(bufs) != 3 {
+		t.Fatalf("期望获取 3 个对象, 实际获取 %d 个", len(bufs))
+	}
+	p.PutAll(bufs)
+	if n := p.store.len(); n != 3 {
+		t.Fatalf("PutAll 之后池中应该有 3 个对象, 实际有 %d 个", n)
+	}
+}
+
+// TestPool_TryGetAll_AllOrNothing 测试有界池额度不足时 TryGetAll 一个对象也不取出。
+func TestPool_TryGetAll_AllOrNothing(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithMax[*bytes.Buffer](3))
+
+	held := p.GetAll(2)
+
+	if bufs, err := p.TryGetAll(2); !errors.Is(err, ErrExhausted) || bufs != nil {
+		t.Fatalf("额度不足时期望得到 ErrExhausted 且不返回对象, 得到 %v, %v", bufs, err)
+	}
+	// 失败的请求不应占用额度：剩余的 1 个额度仍然可用。
+	bufs, err := p.TryGetAll(1)
+	if err != nil || len(bufs) != 1 {
+		t.Fatalf("失败的 TryGetAll 不应该占用额度, 得到 %v, %v", bufs, err)
+	}
+
+	p.PutAll(held)
+	p.PutAll(bufs)
+	if bufs, err := p.TryGetAll(3); err != nil || len(bufs) != 3 {
+		t.Fatalf("全部放回后应该可以一次获取 3 个对象, 得到 %v, %v", bufs, err)
+	}
+}
+
+// TestPool_GetAll_WaitsForAll 测试有界池中 GetAll 会等到能够获得全部额度后才返回。
+func TestPool_GetAll_WaitsForAll(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithMax[*bytes.Buffer](2))
+
+	a, b := p.Get(), p.Get()
+
+	got := make(chan []*bytes.Buffer)
+	go func() {
+		got <- p.GetAll(2)
+	}()
+
+	p.Put(a)
+	select {
+	case <-got:
+		t.Fatal("只有 1 个额度可用时 GetAll(2) 不应该返回")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	p.Put(b)
+	select {
+	case bufs := <-got:
+		if len(bufs) != 2 {
+			t.Fatalf("期望获取 2 个对象, 实际获取 %d 个", len(bufs))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("全部额度可用后 GetAll(2) 应该返回")
+	}
+}
+
+// TestPool_GetAll_ExceedsMax 测试请求数量超过有界池上限时 GetAll 会 panic，而不是永远阻塞。
+func TestPool_GetAll_ExceedsMax(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithMax[*bytes.Buffer](1))
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("请求数量超过上限时 GetAll 应该 panic")
+		}
+	}()
+	p.GetAll(2)
+}
+
+// closerObject 是一个实现了 io.Closer 的测试对象。
+type closerObject struct {
+	closed int
+	err    error
+}
+
+func (c *closerObject) Close() error {
+	c.closed++
+	return c.err
+}
+
+// TestPool_Clear 测试 Clear 丢弃所有闲置对象并关闭实现了 io.Closer 的对象，之后池仍可正常使用。
+func TestPool_Clear(t *testing.T) {
+	p := New(func() *closerObject {
+		return &closerObject{}
+	}, WithDeterministic[*closerObject]())
+
+	a, b := p.Get(), p.Get()
+	p.Put(a)
+	p.Put(b)
+	if err := p.Clear(); err != nil {
+		t.Fatalf("Clear 返回了错误: %v", err)
+	}
+
+	if n := p.store.len(); n != 0 {
+		t.Fatalf("Clear 之后池中不应该有闲置对象, 实际有 %d 个", n)
+	}
+	if a.closed != 1 || b.closed != 1 {
+		t.Fatalf("被清空的对象应该各被关闭一次, 实际为 %d 和 %d 次", a.closed, b.closed)
+	}
+
+	c := p.Get()
+	p.Put(c)
+	if got := p.Get(); got != c {
+		t.Fatal("Clear 之后池应该可以继续正常复用对象")
+	}
+}
+
+// TestPool_Close 测试 Close 关闭闲置对象，并丢弃之后放回的对象。
+func TestPool_Close(t *testing.T) {
+	closeErr := errors.New("close failed")
+	p := New(func() *closerObject {
+		return &closerObject{}
+	}, WithDeterministic[*closerObject]())
+
+	idle, borrowed := p.Get(), p.Get()
+	idle.err = closeErr
+	p.Put(idle)
+
+	if err := p.Close(); !errors.Is(err, closeErr) {
+		t.Fatalf("Close 应该返回对象的关闭错误, 得到 %v", err)
+	}
+	if idle.closed != 1 {
+		t.Fatalf("闲置对象应该被关闭一次, 实际 %d 次", idle.closed)
+	}
+	if err := p.Close(); err != nil {
+		t.Fatalf("重复调用 Close 应该返回 nil, 得到 %v", err)
+	}
+
+	// 关闭之后放回的对象会被直接关闭，而不是存入池中。
+	p.Put(borrowed)
+	if borrowed.closed != 1 {
+		t.Fatalf("放回已关闭的池的对象应该被关闭一次, 实际 %d 次", borrowed.closed)
+	}
+	if n := p.store.len(); n != 0 {
+		t.Fatalf("已关闭的池不应该保留对象, 实际有 %d 个", n)
+	}
+	if got := p.Get(); got == nil || got == idle || got == borrowed {
+		t.Fatal("关闭后 Get() 应该通过 newFunc 创建新对象")
+	}
+}
+
+// TestPool_Shutdown 测试 Shutdown 把每个闲置对象交给 handler 而不关闭它们，之后放回的对象被丢弃。
+func TestPool_Shutdown(t *testing.T) {
+	p := New(func() *closerObject {
+		return &closerObject{}
+	}, WithDeterministic[*closerObject]())
+	idle := p.GetAll(3)
+	held := p.Get()
+	p.PutAll(idle)
+
+	var handed []*closerObject
+	p.Shutdown(func(x *closerObject) {
+		handed = append(handed, x)
+	})
+	if len(handed) != 3 {
+		t.Fatalf("期望 3 个闲置对象交给 handler, 得到 %d 个", len(handed))
+	}
+	for i, x := range handed {
+		if x != idle[i] || x.closed != 0 {
+			t.Errorf("第 %d 个对象应该原样交给 handler 且不被关闭", i)
+		}
+	}
+
+	p.Put(held)
+	if s := p.Stats(); s.Idle != 0 || s.DiscardsByReason[DiscardClosed] != 1 || held.closed != 1 {
+		t.Errorf("Shutdown 之后放回的对象应该以 %q 被丢弃并关闭: %+v", DiscardClosed, s)
+	}
+	p.Shutdown(func(*closerObject) { t.Error("已关闭的池不应该再调用 handler") })
+	if err := p.Close(); err != nil {
+		t.Errorf("Shutdown 之后 Close 应该直接返回, 得到 %v", err)
+	}
+}
+
+// TestPool_ResetPool_Reopens 测试 ResetPool 会让已关闭的池恢复可用。
+func TestPool_ResetPool_Reopens(t *testing.T) {
+	p := New(func() *closerObject {
+		return &closerObject{}
+	}, WithDeterministic[*closerObject]())
+	p.Close()
+	p.ResetPool()
+
+	obj := p.Get()
+	p.Put(obj)
+	if obj.closed != 0 || p.store.len() != 1 {
+		t.Fatal("ResetPool 之后池应该重新接受放回的对象")
+	}
+}
+
+// TestPool_StrictNil 测试严格模式下构造函数返回 nil 会使 Get panic，而默认模式下静默返回零值。
+func TestPool_StrictNil(t *testing.T) {
+	mustPanic := func(t *testing.T, fn func()) {
+		t.Helper()
+		defer func() {
+			r := recover()
+			if r == nil {
+				t.Fatal("严格模式下构造函数返回 nil 时 Get 应该 panic")
+			}
+			if msg, _ := r.(string); !strings.Contains(msg, "newFunc returned nil") {
+				t.Fatalf("panic 信息应该说明构造函数返回了 nil, 得到 %v", r)
+			}
+		}()
+		fn()
+	}
+
+	t.Run("PointerType", func(t *testing.T) {
+		for _, opts := range [][]Option[*bytes.Buffer]{
+			{WithStrictNil[*bytes.Buffer]()},
+			{WithStrictNil[*bytes.Buffer](), WithDeterministic[*bytes.Buffer]()},
+		} {
+			p := New(func() *bytes.Buffer {
+				return nil
+			}, opts...)
+			mustPanic(t, func() { p.Get() })
+		}
+	})
+
+	t.Run("ValueType", func(t *testing.T) {
+		type ValueObject struct {
+			X int
+		}
+		p := New(func() ValueObject {
+			return ValueObject{X: 1}
+		}, WithStrictNil[ValueObject]())
+		p.Pool.New = func() any { return nil }
+		mustPanic(t, func() { p.Get() })
+	})
+
+	t.Run("Default", func(t *testing.T) {
+		p := New(func() *bytes.Buffer {
+			return nil
+		}, WithDeterministic[*bytes.Buffer]())
+		if v := p.Get(); v != nil {
+			t.Fatalf("默认模式下应该静默返回 nil, 得到 %v", v)
+		}
+	})
+}
+
+// TestPool_NewPointer 测试 NewPointer 创建的池 Get/Put 不产生内存分配，且放回 nil 指针是安全的。
+func TestPool_NewPointer(t *testing.T) {
+	p := NewPointer(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	})
+
+	p.Put(p.Get())
+	allocs := testing.AllocsPerRun(100, func() {
+		p.Put(p.Get())
+	})
+	if allocs != 0 {
+		t.Errorf("期望 Get/Put 不产生内存分配, 实际每次 %v 次", allocs)
+	}
+
+	p.Put(nil)
+	if s := p.Stats(); s.DiscardsByReason[DiscardNil] != 1 {
+		t.Fatalf("期望 nil 指针以 %q 被丢弃, 得到 %+v", DiscardNil, s)
+	}
+	if buf := p.Get(); buf == nil {
+		t.Fatal("放回 nil 后 Get 不应返回 nil")
+	}
+}
+
+// TestPool_InterfaceTypedNil 测试 T 为接口类型时，构造函数返回的包装了 nil 指针的接口值被规范化为 nil 接口。
+func TestPool_InterfaceTypedNil(t *testing.T) {
+	for _, opts := range [][]Option[fmt.Stringer]{
+		nil,
+		{WithDeterministic[fmt.Stringer]()},
+	} {
+		p := New(func() fmt.Stringer {
+			var b *bytes.Buffer
+			return b // 非 nil 的接口值，其中包装了 nil 指针
+		}, opts...)
+
+		if x := p.Get(); x != nil {
+			t.Fatalf("期望 Get 返回 nil 接口, 得到 %#v", x)
+		}
+	}
+
+	// 用户覆盖的 sync.Pool.New 返回的 nil 指针同样被规范化。
+	p := New(func() fmt.Stringer {
+		return new(bytes.Buffer)
+	})
+	p.Pool.New = func() any {
+		var b *strings.Builder
+		return fmt.Stringer(b)
+	}
+	if x := p.Get(); x != nil {
+		t.Fatalf("期望 Get 返回 nil 接口, 得到 %#v", x)
+	}
+
+	// 非 nil 的对象保持不变。
+	p = New(func() fmt.Stringer {
+		return new(bytes.Buffer)
+	})
+	if x := p.Get(); x == nil {
+		t.Fatal("非 nil 的对象不应被规范化")
+	}
+}
+
+// TestPool_Tap 测试 WithTap 按顺序观察到所有借出和放回操作，并可以通过 SetTap 暂停。
+func TestPool_Tap(t *testing.T) {
+	type event struct {
+		op string
+		x  *bytes.Buffer
+	}
+	var events []event
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer](), WithTap(func(op string, x *bytes.Buffer) {
+		events = append(events, event{op, x})
+	}))
+
+	a := p.Get()
+	b := p.Get()
+	p.Put(a)
+	xs := p.GetAll(1)
+	p.PutAll(xs)
+	p.SetTap(false)
+	p.Put(p.Get())
+	p.SetTap(true)
+	p.Put(b)
+
+	want := []event{
+		{TapGet, a},
+		{TapGet, b},
+		{TapPut, a},
+		{TapGet, a},
+		{TapPut, a},
+		{TapPut, b},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("期望观察到 %d 次操作, 得到 %d 次: %v", len(want), len(events), events)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("第 %d 次操作期望 %v, 得到 %v", i, want[i], events[i])
+		}
+	}
+}
+
+// TestPool_OnGetOnPut 测试 WithOnGet 和 WithOnPut 的回调按设置的顺序在借出和放回时被调用，
+// WithOnPut 在对象被重置之前运行。
+func TestPool_OnGetOnPut(t *testing.T) {
+	var calls []string
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer](), WithAutoReset[*bytes.Buffer](),
+		WithOnGet(func(b *bytes.Buffer) { calls = append(calls, "get1") }),
+		WithOnGet(func(b *bytes.Buffer) { calls = append(calls, "get2") }),
+		WithOnPut(func(b *bytes.Buffer) { calls = append(calls, "put:"+b.String()) }),
+	)
+	if c := p.Config(); c.OnGet != 2 || c.OnPut != 1 {
+		t.Fatalf("期望 2 个 OnGet 回调和 1 个 OnPut 回调, 得到 %+v", c)
+	}
+
+	b := p.Get()
+	b.WriteString("data")
+	p.Put(b)
+	p.Put(nil)
+
+	want := []string{"get1", "get2", "put:data"}
+	if fmt.Sprint(calls) != fmt.Sprint(want) {
+		t.Fatalf("期望回调依次为 %v, 得到 %v", want, calls)
+	}
+}
+
+// TestPool_Do 测试 Do 在回调返回后放回对象并返回回调的错误。
+func TestPool_Do(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer]())
+
+	errDone := errors.New("done")
+	var used *bytes.Buffer
+	err := p.Do(func(b *bytes.Buffer) error {
+		used = b
+		return errDone
+	})
+	if err != errDone {
+		t.Fatalf("期望返回回调的错误, 得到 %v", err)
+	}
+	if s := p.Stats(); s.Idle != 1 || s.Outstanding != 0 {
+		t.Fatalf("期望对象被放回池中, 得到 %+v", s)
+	}
+	if p.Get() != used {
+		t.Error("期望取回 Do 使用过的对象")
+	}
+}
+
+// TestPool_DiscardOnPanic 测试回调 panic 时，启用 WithDiscardOnPanic 的池丢弃并关闭对象，且 panic 继续传播。
+func TestPool_DiscardOnPanic(t *testing.T) {
+	doPanic := func(p *Pool[*closerObject]) (x *closerObject, r any) {
+		defer func() { r = recover() }()
+		p.Do(func(o *closerObject) error {
+			x = o
+			panic("boom")
+		})
+		return x, nil
+	}
+
+	p := New(func() *closerObject {
+		return &closerObject{}
+	}, WithDeterministic[*closerObject](), WithDiscardOnPanic[*closerObject]())
+	x, r := doPanic(p)
+	if r != "boom" {
+		t.Fatalf("panic 应该继续传播, 得到 %v", r)
+	}
+	s := p.Stats()
+	if s.Idle != 0 || s.Outstanding != 0 || s.DiscardsByReason[DiscardPanicked] != 1 {
+		t.Fatalf("期望对象以 %q 被丢弃, 得到 %+v", DiscardPanicked, s)
+	}
+	if x.closed != 1 {
+		t.Errorf("被丢弃的对象应该被关闭 1 次, 实际 %d 次", x.closed)
+	}
+
+	// 默认情况下对象仍会被放回池中。
+	p = New(func() *closerObject {
+		return &closerObject{}
+	}, WithDeterministic[*closerObject]())
+	if _, r := doPanic(p); r != "boom" {
+		t.Fatalf("panic 应该继续传播, 得到 %v", r)
+	}
+	if s := p.Stats(); s.Idle != 1 || s.Discards != 0 {
+		t.Fatalf("默认情况下期望对象被放回池中, 得到 %+v", s)
+	}
+}
+
+// TestPool_Update 测试 Update 在回调返回 true 时放回对象，返回 false 或 panic 时丢弃对象，对象都只被归还一次。
+func TestPool_Update(t *testing.T) {
+	p := New(func() *closerObject {
+		return &closerObject{}
+	}, WithDeterministic[*closerObject]())
+
+	var kept *closerObject
+	p.Update(func(o *closerObject) bool {
+		kept = o
+		return true
+	})
+	if s := p.Stats(); s.Idle != 1 || s.Outstanding != 0 || s.Discards != 0 {
+		t.Fatalf("keep 为 true 时期望对象被放回池中, 得到 %+v", s)
+	}
+
+	var rolledBack *closerObject
+	p.Update(func(o *closerObject) bool {
+		rolledBack = o
+		return false
+	})
+	if rolledBack != kept {
+		t.Fatal("期望复用之前放回的对象")
+	}
+	if s := p.Stats(); s.Idle != 0 || s.Outstanding != 0 || s.DiscardsByReason[DiscardRolledBack] != 1 {
+		t.Fatalf("keep 为 false 时期望对象以 %q 被丢弃, 得到 %+v", DiscardRolledBack, s)
+	}
+	if rolledBack.closed != 1 {
+		t.Errorf("被丢弃的对象应该被关闭 1 次, 实际 %d 次", rolledBack.closed)
+	}
+
+	var panicked *closerObject
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("panic 应该继续传播, 得到 %v", r)
+			}
+		}()
+		p.Update(func(o *closerObject) bool {
+			panicked = o
+			panic("boom")
+		})
+	}()
+	s := p.Stats()
+	if s.Idle != 0 || s.Outstanding != 0 || s.Puts != 3 || s.DiscardsByReason[DiscardPanicked] != 1 {
+		t.Fatalf("panic 时期望对象以 %q 被丢弃且只被归还一次, 得到 %+v", DiscardPanicked, s)
+	}
+	if panicked.closed != 1 {
+		t.Errorf("被丢弃的对象应该被关闭 1 次, 实际 %d 次", panicked.closed)
+	}
+}
+
+// TestPool_EnabledFunc 测试开关关闭时 Get 总是新建对象、Put 丢弃对象，重新打开后恢复复用。
+func TestPool_EnabledFunc(t *testing.T) {
+	var enabled atomic.Bool
+	enabled.Store(true)
+	p := New(func() *closerObject {
+		return &closerObject{}
+	}, WithDeterministic[*closerObject](), WithEnabledFunc[*closerObject](enabled.Load))
+
+	kept := p.Get()
+	p.Put(kept)
+
+	enabled.Store(false)
+	x := p.Get()
+	if x == kept {
+		t.Fatal("关闭池化时 Get 不应该复用闲置对象")
+	}
+	p.Put(x)
+	if s := p.Stats(); s.Idle != 1 || s.Misses != 2 || s.DiscardsByReason[DiscardDisabled] != 1 || x.closed != 1 {
+		t.Fatalf("关闭池化时放回的对象应该以 %q 被丢弃并关闭: %+v", DiscardDisabled, s)
+	}
+
+	enabled.Store(true)
+	if got := p.Get(); got != kept {
+		t.Fatal("重新打开后应该复用之前闲置的对象")
+	}
+	p.Put(kept)
+	if s := p.Stats(); s.Idle != 1 || s.Misses != 2 {
+		t.Errorf("重新打开后期望恢复正常的池化: %+v", s)
+	}
+}
+
+// TestPool_AllocCap 测试创建的对象达到 WithAllocCap 的上限后，需要新对象的 Get 会 panic，而复用不受影响。
+func TestPool_AllocCap(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer](), WithAllocCap[*bytes.Buffer](2))
+
+	a, b := p.Get(), p.Get()
+	p.Put(a)
+	for i := 0; i < 10; i++ {
+		p.Put(p.Get()) // 复用不计入上限
+	}
+
+	func() {
+		defer func() {
+			err, _ := recover().(error)
+			if !errors.Is(err, ErrNewFailed) || !errors.Is(err, ErrAllocCap) {
+				t.Fatalf("期望以 ErrAllocCap panic, 得到 %v", err)
+			}
+		}()
+		p.Get() // 复用 a
+		p.Get() // 第 3 次创建
+	}()
+
+	if s := p.Stats(); s.Misses != 2 {
+		t.Fatalf("期望只有 2 次未命中, 得到 %+v", s)
+	}
+	p.Put(b)
+	if p.Get() != b {
+		t.Fatal("达到上限后仍应复用闲置对象")
+	}
+}
+
+// TestPool_Detach 测试 Detach 减少借出数量并归还有界池的额度，之后误放回的对象被丢弃而不是存入池中。
+func TestPool_Detach(t *testing.T) {
+	var discarded []string
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithMax[*bytes.Buffer](1), WithDeterministic[*bytes.Buffer](),
+		WithOnDiscard(func(_ *bytes.Buffer, reason string) {
+			discarded = append(discarded, reason)
+		}))
+
+	x := p.Get()
+	p.Detach(x)
+	s := p.Stats()
+	if s.Outstanding != 0 || s.Detached != 1 {
+		t.Errorf("Detach 后 Outstanding 应该为 0, Detached 应该为 1, 得到 %d 和 %d", s.Outstanding, s.Detached)
+	}
+
+	// 额度已被归还，下一个 Get 不会阻塞。
+	y := p.Get()
+
+	p.Put(x)
+	if len(discarded) != 1 || discarded[0] != DiscardDetached {
+		t.Errorf("放回被分离的对象应该以 DiscardDetached 丢弃, 得到 %v", discarded)
+	}
+	s = p.Stats()
+	if s.Idle != 0 || s.Puts != 0 || s.Outstanding != 1 {
+		t.Errorf("被分离的对象不应存入池中或计入 Puts: %+v", s)
+	}
+
+	p.Put(y)
+	if s := p.Stats(); s.Idle != 1 || s.Outstanding != 0 {
+		t.Errorf("正常放回的对象应该被存入池中: %+v", s)
+	}
+}
+
+// TestPool_Detach_Value 测试值类型的池不会把与被分离的值相等的另一个值当作被分离的对象丢弃，有界池的额度也不会丢失。
+func TestPool_Detach_Value(t *testing.T) {
+	p := NewValue(func() int { return 0 }, WithMax[int](2))
+
+	x, y := p.Get(), p.Get()
+	p.Detach(x)
+	p.Put(y)
+	if s := p.Stats(); s.Puts != 1 || s.Outstanding != 0 || s.DiscardsByReason[DiscardDetached] != 0 {
+		t.Fatalf("与被分离的值相等的值应该正常放回: %+v", s)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for i := 0; i < 2; i++ {
+		if _, err := p.GetContext(ctx); err != nil {
+			t.Fatalf("额度应该全部可用, 第 %d 次 GetContext 失败: %v", i+1, err)
+		}
+	}
+}
+
+// TestPool_Inspect 测试 Inspect 查看闲置对象而不会取出它或把它计为借出。
+func TestPool_Inspect(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer]())
+
+	if p.Inspect(func(*bytes.Buffer) { t.Error("没有闲置对象时不应调用回调") }) {
+		t.Error("没有闲置对象时 Inspect 应该返回 false")
+	}
+
+	p.Put(bytes.NewBufferString("healthy"))
+	before := p.Stats()
+	var seen string
+	if !p.Inspect(func(b *bytes.Buffer) { seen = b.String() }) {
+		t.Fatal("有闲置对象时 Inspect 应该返回 true")
+	}
+	if seen != "healthy" {
+		t.Errorf("回调应该看到闲置对象的内容, 得到 %q", seen)
+	}
+	if after := p.Stats(); after.Idle != before.Idle || after.Gets != before.Gets || after.Outstanding != before.Outstanding {
+		t.Errorf("Inspect 不应改变池的统计: 之前 %+v, 之后 %+v", before, after)
+	}
+
+	syncPool := New(func() int { return 0 })
+	syncPool.Put(1)
+	if syncPool.Inspect(func(int) {}) {
+		t.Error("sync.Pool 后端不支持 Inspect")
+	}
+}
+
+// TestPool_GetPreferred 测试 prev 仍然闲置时 GetPreferred 返回它，否则退回到其他闲置对象或新建对象。
+func TestPool_GetPreferred(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer]())
+
+	a, b, c := new(bytes.Buffer), new(bytes.Buffer), new(bytes.Buffer)
+	p.PutAll([]*bytes.Buffer{a, b, c})
+
+	if got := p.GetPreferred(a); got != a {
+		t.Error("prev 仍然闲置时应该返回 prev")
+	}
+	// 其余对象保持原来的顺序。
+	if got := p.Get(); got != c {
+		t.Error("取出 prev 后其余对象的顺序不应改变")
+	}
+	if got := p.GetPreferred(a); got != b {
+		t.Error("prev 不在池中时应该返回其他闲置对象")
+	}
+	if got := p.GetPreferred(a); got == a || got == b || got == c {
+		t.Error("池为空时应该创建新对象")
+	}
+	if s := p.Stats(); s.Gets != 4 || s.Misses != 1 || s.Idle != 0 {
+		t.Errorf("GetPreferred 应该像 Get 一样计数: %+v", s)
+	}
+}
+
+// TestPool_GetPreferred_Validator 测试取回的 prev 未通过校验时被丢弃，GetPreferred 退回到其他对象。
+func TestPool_GetPreferred_Validator(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithSharded[*bytes.Buffer](2), WithValidator(func(b *bytes.Buffer) bool {
+		return b.Len() == 0
+	}))
+
+	stale := bytes.NewBufferString("stale")
+	fresh := new(bytes.Buffer)
+	p.PutAll([]*bytes.Buffer{fresh, stale})
+	if got := p.GetPreferred(stale); got != fresh {
+		t.Error("prev 未通过校验时应该返回其他闲置对象")
+	}
+	if s := p.Stats(); s.DiscardsByReason[DiscardInvalid] != 1 {
+		t.Errorf("未通过校验的 prev 应该被丢弃: %+v", s.DiscardsByReason)
+	}
+}
+
+// TestPool_Plain 测试只有没有启用任何影响 Get 和 Put 的可选功能的池才会走最短路径，
+// 并且最短路径仍然维护统计、丢弃 nil 对象和被分离的对象。
+func TestPool_Plain(t *testing.T) {
+	type B = *bytes.Buffer
+	newBuffer := func() B { return new(bytes.Buffer) }
+	for _, opts := range [][]Option[B]{
+		nil,
+		{WithStats[B](false)},
+		{WithOnDiscard(func(B, string) {})},
+	} {
+		if p := New(newBuffer, opts...); !p.plain {
+			t.Errorf("期望 %d 个与热点路径无关的选项不影响最短路径", len(opts))
+		}
+	}
+	for name, opt := range map[string]Option[B]{
+		"WithDeterministic": WithDeterministic[B](),
+		"WithMax":           WithMax[B](1),
+		"WithTap":           WithTap(func(string, B) {}),
+		"WithOnGet":         WithOnGet(func(B) {}),
+		"WithOnPut":         WithOnPut(func(B) {}),
+		"WithValidator":     WithValidator(func(B) bool { return true }),
+		"WithValidateOnPut": WithValidateOnPut(func(B) bool { return true }),
+		"WithAutoReset":     WithAutoReset[B](),
+		"WithResetFields":   WithResetFields(func(*bytes.Buffer) {}),
+		"WithRecycle":       WithRecycle(func(b B) B { return b }),
+		"WithEnabledFunc":   WithEnabledFunc[B](func() bool { return true }),
+		"WithDebug":         WithDebug[B](),
+		"WithProfileLabels": WithProfileLabels[B]("plain"),
+		"WithMeasure":       WithMeasure(func(b B) int { return b.Cap() }),
+		"WithWarmupBarrier": WithWarmupBarrier[B](0),
+		"WithAsyncPut":      WithAsyncPut[B](1),
+		"WithChurnDetector": WithChurnDetector[B](time.Millisecond),
+	} {
+		p := New(newBuffer, opt)
+		if p.plain {
+			t.Errorf("启用 %s 后不应该走最短路径", name)
+		}
+		p.Close()
+	}
+
+	p := New(newBuffer)
+	x := p.Get()
+	p.Put(x)
+	p.Put(nil)
+	y := p.Get()
+	p.Detach(y)
+	p.Put(y)
+	s := p.Stats()
+	if s.Gets != 2 || s.Puts != 2 || s.DiscardsByReason[DiscardNil] != 1 || s.DiscardsByReason[DiscardDetached] != 1 {
+		t.Fatalf("最短路径应该照常统计并丢弃 nil 和被分离的对象, 得到 %+v", s)
+	}
+}
+
+// BenchmarkPool_Plain 对比未启用可选功能的池与直接使用 sync.Pool 的 Get/Put 开销，
+// 两者应当只相差统计计数的开销；启用一个可选功能（Validator）的池作为参照。
+// Overhead 子基准在同一轮中比较它们的耗时，明显变慢时报告失败。
+func BenchmarkPool_Plain(b *testing.B) {
+	newBuffer := func() *bytes.Buffer { return new(bytes.Buffer) }
+	b.Run("SyncPool", func(b *testing.B) {
+		p := sync.Pool{New: func() any { return newBuffer() }}
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				p.Put(p.Get().(*bytes.Buffer))
+			}
+		})
+	})
+	b.Run("BasePool", func(b *testing.B) {
+		p := newBasePool(newBuffer)
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				p.Put(p.Get())
+			}
+		})
+	})
+	for _, bc := range []struct {
+		name string
+		opts []Option[*bytes.Buffer]
+	}{
+		{"Plain", []Option[*bytes.Buffer]{WithStats[*bytes.Buffer](false)}},
+		{"PlainStats", nil},
+		{"Validator", []Option[*bytes.Buffer]{WithValidator(func(*bytes.Buffer) bool { return true })}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			p := New(newBuffer, bc.opts...)
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					p.Put(p.Get())
+				}
+			})
+		})
+	}
+	b.Run("Overhead", benchmarkPlainOverhead)
+}
+
+// benchmarkPlainOverhead 检查没有任何选项的池的 Get 和 Put 不比只包装了 sync.Pool 的池明显更慢：
+// 禁用统计时两者的耗时应该基本相同，默认启用的统计只增加每次 Get 和 Put 各一次原子加法。
+// 各种情况轮流测量多轮并各取最快的一次，使机器负载的变化对它们的影响大致相同。
+// 它比较的是纳秒级的耗时，只在运行基准测试时检查，不属于默认的测试。
+func benchmarkPlainOverhead(b *testing.B) {
+	if raceEnabled {
+		b.Skip("耗时比较需要不带竞态检测的构建")
+	}
+	newBuffer := func() *bytes.Buffer { return new(bytes.Buffer) }
+	cycle := func(p interface {
+		Get() *bytes.Buffer
+		Put(*bytes.Buffer)
+	}) func(int) {
+		return func(n int) {
+			for i := 0; i < n; i++ {
+				p.Put(p.Get())
+			}
+		}
+	}
+	var counter int64
+	loops := []func(int){
+		cycle(newBasePool(newBuffer)),
+		cycle(New(newBuffer, WithStats[*bytes.Buffer](false))),
+		cycle(New(newBuffer)),
+		func(n int) {
+			for i := 0; i < n; i++ {
+				atomic.AddInt64(&counter, 1)
+			}
+		},
+	}
+	const rounds = 30
+	n := b.N/rounds + 1
+	best := make([]float64, len(loops))
+	for round := 0; round < rounds; round++ {
+		for i, loop := range loops {
+			start := time.Now()
+			loop(n)
+			if ns := float64(time.Since(start).Nanoseconds()) / float64(n); round == 0 || ns < best[i] {
+				best[i] = ns
+			}
+		}
+	}
+	base, plain, stats, atomicAdd := best[0], best[1], best[2], best[3]
+	b.ReportMetric(base, "base-ns/op")
+	b.ReportMetric(plain, "plain-ns/op")
+	b.ReportMetric(stats, "stats-ns/op")
+
+	// 次数太少时耗时主要是测量误差，不做比较。
+	if n < 10000 {
+		return
+	}
+	// 允许少量的字段检查和测量误差。
+	if limit := base*1.25 + 3; plain > limit {
+		b.Errorf("禁用统计的池每次 Get/Put 耗时 %.1fns, 超过了 sync.Pool 包装的 %.1fns 允许的上限 %.1fns", plain, base, limit)
+	}
+	if limit := (plain+2*atomicAdd)*1.25 + 3; stats > limit {
+		b.Errorf("默认的池每次 Get/Put 耗时 %.1fns, 超过了允许的上限 %.1fns", stats, limit)
+	}
+}
+
+// basePool 是只包装了 sync.Pool、不做任何额外处理的池，即没有任何选项时 Pool 的性能基准。
+type basePool[T any] struct {
+	sync.Pool
+}
+
+func newBasePool[T any](newFunc func() T) *basePool[T] {
+	return &basePool[T]{Pool: sync.Pool{New: func() any { return newFunc() }}}
+}
+
+func (w *basePool[T]) Get() T {
+	v := w.Pool.Get()
+	if v == nil {
+		var zero T
+		return zero
+	}
+	return v.(T)
+}
+
+func (w *basePool[T]) Put(x T) {
+	w.Pool.Put(x)
+}
+
+// TestPool_TryGet 测试 TryGet 只返回已有的闲置对象，从不调用 newFunc，并遵守有界池的额度。
+func TestPool_TryGet(t *testing.T) {
+	var created int32
+	p := New(func() *bytes.Buffer {
+		atomic.AddInt32(&created, 1)
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer](), WithMax[*bytes.Buffer](2))
+
+	if _, ok := p.TryGet(); ok || atomic.LoadInt32(&created) != 0 {
+		t.Fatal("没有闲置对象时 TryGet 应该返回 false 且不创建对象")
+	}
+	p.WarmUp(3)
+	a, ok := p.TryGet()
+	if !ok {
+		t.Fatal("期望 TryGet 取出闲置的对象")
+	}
+	p.Put(a)
+	if x, ok := p.TryGet(); !ok || x != a {
+		t.Fatal("期望 TryGet 取出最近放回的对象")
+	}
+
+	// 额度用完时即使还有闲置对象也取不出来。
+	if _, ok := p.TryGet(); !ok {
+		t.Fatal("期望取出第二个闲置对象")
+	}
+	if _, ok := p.TryGet(); ok {
+		t.Fatal("额度用完时 TryGet 应该返回 false")
+	}
+	if s := p.Stats(); s.Gets != 3 || s.Hits != 3 || s.Idle != 1 || atomic.LoadInt32(&created) != 3 {
+		t.Fatalf("统计信息不正确: %+v", s)
+	}
+
+	if _, ok := New(func() *bytes.Buffer { return new(bytes.Buffer) }).TryGet(); ok {
+		t.Fatal("sync.Pool 后端上 TryGet 应该总是返回 false")
+	}
+}
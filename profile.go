@@ -0,0 +1,25 @@
+package gpool
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+// profileLabelKey 是 WithProfileLabels 设置的 pprof 标签的键。
+const profileLabelKey = "gpool"
+
+// getLabeled 在带有池的 pprof 标签的区域内执行 Get。
+func (p *Pool[T]) getLabeled() T {
+	var x T
+	pprof.Do(context.Background(), p.labels, func(context.Context) {
+		x = p.getOne()
+	})
+	return x
+}
+
+// putLabeled 在带有池的 pprof 标签的区域内执行 Put。
+func (p *Pool[T]) putLabeled(x T) {
+	pprof.Do(context.Background(), p.labels, func(context.Context) {
+		p.putOne(x)
+	})
+}
@@ -0,0 +1,45 @@
+package gpool
+
+import (
+	"bytes"
+	"runtime/pprof"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPool_ProfileLabels 测试启用 pprof 标签后池仍正常工作，且等待额度的 Get 在 goroutine profile 中带有池的标签。
+func TestPool_ProfileLabels(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer](), WithMax[*bytes.Buffer](1), WithProfileLabels[*bytes.Buffer]("buffers"))
+
+	held := p.Get()
+	got := make(chan *bytes.Buffer)
+	go func() {
+		got <- p.Get() // 阻塞在有界池的额度上
+	}()
+
+	want := `"gpool":"buffers"`
+	var profile bytes.Buffer
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		profile.Reset()
+		if err := pprof.Lookup("goroutine").WriteTo(&profile, 1); err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(profile.String(), want) {
+			break
+		}
+	}
+	if !strings.Contains(profile.String(), want) {
+		t.Errorf("goroutine profile 中应包含标签 %s", want)
+	}
+
+	p.Put(held)
+	if x := <-got; x != held {
+		t.Fatal("期望取回被放回的对象")
+	}
+	if s := p.Stats(); s.Gets != 2 || s.Puts != 1 {
+		t.Fatalf("启用标签后统计应照常记录, 得到 %+v", s)
+	}
+}
@@ -0,0 +1,108 @@
+// Package prometheus 把 gpool 池的统计信息导出为 Prometheus 指标。
+//
+// 它是一个独立的模块，只有需要 Prometheus 指标的程序才会依赖 client_golang，gpool 本身保持没有第三方依赖。
+package prometheus
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/muzhy/gpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// StatsSource 是可以提供统计信息的池，*gpool.Pool、gpool.KeyedLRU、gpool.KeyedSized 等都实现了它。
+type StatsSource interface {
+	Stats() gpool.Stats
+}
+
+// Collector 是导出多个池的统计信息的 prometheus.Collector，每个池的指标都带有值为池名称的 pool 标签。
+// 每次被采集时它都会读取各个池当前的 Stats，因此不需要额外的后台 goroutine。
+//
+// 导出的指标（以 namespace 为前缀）：
+//
+//	<namespace>_gets_total       从池中获取的对象总数
+//	<namespace>_hits_total       复用闲置对象的次数
+//	<namespace>_misses_total     调用 newFunc 创建新对象的次数
+//	<namespace>_puts_total       放回池中的对象总数
+//	<namespace>_discards_total   被池丢弃的对象总数，reason 标签为丢弃原因（gpool.Discard* 常量）
+//	<namespace>_in_use           当前借出的对象数量
+//	<namespace>_idle             当前闲置在池中的对象数量
+//
+// 所有方法都是并发安全的，可以在 Collector 注册之后继续添加或移除池。
+type Collector struct {
+	gets, hits, misses, puts, discards, inUse, idle *prometheus.Desc
+
+	mu    sync.Mutex
+	pools map[string]StatsSource
+}
+
+// NewCollector 创建一个指标名以 namespace 为前缀的 Collector，namespace 为空时使用 "gpool"。
+func NewCollector(namespace string) *Collector {
+	if namespace == "" {
+		namespace = "gpool"
+	}
+	desc := func(name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "", name), help, append([]string{"pool"}, labels...), nil)
+	}
+	return &Collector{
+		gets:     desc("gets_total", "Total number of objects taken from the pool."),
+		hits:     desc("hits_total", "Total number of Gets served by an idle object."),
+		misses:   desc("misses_total", "Total number of objects created by the pool's newFunc."),
+		puts:     desc("puts_total", "Total number of objects returned to the pool."),
+		discards: desc("discards_total", "Total number of objects discarded by the pool.", "reason"),
+		inUse:    desc("in_use", "Number of objects currently borrowed from the pool."),
+		idle:     desc("idle", "Number of objects currently idle in the pool."),
+		pools:    make(map[string]StatsSource),
+	}
+}
+
+// Add 以名称 name 导出池 p 的指标。同名的池已经存在时，它会被 p 替换。
+func (c *Collector) Add(name string, p StatsSource) {
+	c.mu.Lock()
+	c.pools[name] = p
+	c.mu.Unlock()
+}
+
+// Remove 停止导出名称为 name 的池的指标。
+func (c *Collector) Remove(name string) {
+	c.mu.Lock()
+	delete(c.pools, name)
+	c.mu.Unlock()
+}
+
+// Describe 实现 prometheus.Collector。
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{c.gets, c.hits, c.misses, c.puts, c.discards, c.inUse, c.idle} {
+		ch <- d
+	}
+}
+
+// Collect 实现 prometheus.Collector。
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	names := make([]string, 0, len(c.pools))
+	for name := range c.pools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pools := make([]StatsSource, len(names))
+	for i, name := range names {
+		pools[i] = c.pools[name]
+	}
+	c.mu.Unlock()
+
+	// 在锁外读取统计信息，避免采集阻塞 Add 和 Remove。
+	for i, p := range pools {
+		name, s := names[i], p.Stats()
+		ch <- prometheus.MustNewConstMetric(c.gets, prometheus.CounterValue, float64(s.Gets), name)
+		ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(s.Hits), name)
+		ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(s.Misses), name)
+		ch <- prometheus.MustNewConstMetric(c.puts, prometheus.CounterValue, float64(s.Puts), name)
+		for reason, n := range s.DiscardsByReason {
+			ch <- prometheus.MustNewConstMetric(c.discards, prometheus.CounterValue, float64(n), name, reason)
+		}
+		ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(s.Outstanding), name)
+		ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(s.Idle), name)
+	}
+}
@@ -0,0 +1,77 @@
+package prometheus
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/muzhy/gpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// TestCollector 测试 Collector 以 pool 标签导出每个池的统计信息，被移除的池不再被导出。
+func TestCollector(t *testing.T) {
+	newBuffer := func() *bytes.Buffer { return new(bytes.Buffer) }
+	a := gpool.New(newBuffer, gpool.WithDeterministic[*bytes.Buffer]())
+	b := gpool.New(newBuffer, gpool.WithDeterministic[*bytes.Buffer](), gpool.WithMaxIdle[*bytes.Buffer](1))
+
+	a.Put(a.Get())
+	a.Get()
+	x, y := b.Get(), b.Get()
+	b.Put(x)
+	b.Put(y) // 超出 WithMaxIdle 的上限而被丢弃
+
+	c := NewCollector("")
+	c.Add("a", a)
+	c.Add("b", b)
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(c)
+
+	want := `
+# HELP gpool_discards_total Total number of objects discarded by the pool.
+# TYPE gpool_discards_total counter
+gpool_discards_total{pool="b",reason="overflow"} 1
+# HELP gpool_gets_total Total number of objects taken from the pool.
+# TYPE gpool_gets_total counter
+gpool_gets_total{pool="a"} 2
+gpool_gets_total{pool="b"} 2
+# HELP gpool_hits_total Total number of Gets served by an idle object.
+# TYPE gpool_hits_total counter
+gpool_hits_total{pool="a"} 1
+gpool_hits_total{pool="b"} 0
+# HELP gpool_idle Number of objects currently idle in the pool.
+# TYPE gpool_idle gauge
+gpool_idle{pool="a"} 0
+gpool_idle{pool="b"} 1
+# HELP gpool_in_use Number of objects currently borrowed from the pool.
+# TYPE gpool_in_use gauge
+gpool_in_use{pool="a"} 1
+gpool_in_use{pool="b"} 0
+# HELP gpool_misses_total Total number of objects created by the pool's newFunc.
+# TYPE gpool_misses_total counter
+gpool_misses_total{pool="a"} 1
+gpool_misses_total{pool="b"} 2
+# HELP gpool_puts_total Total number of objects returned to the pool.
+# TYPE gpool_puts_total counter
+gpool_puts_total{pool="a"} 1
+gpool_puts_total{pool="b"} 2
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want)); err != nil {
+		t.Fatal(err)
+	}
+
+	c.Remove("b")
+	if n := testutil.CollectAndCount(c, "gpool_gets_total"); n != 1 {
+		t.Fatalf("移除 b 之后期望只导出 1 个池的指标, 得到 %d 个", n)
+	}
+}
+
+// TestCollector_Namespace 测试指标名使用指定的 namespace 作为前缀。
+func TestCollector_Namespace(t *testing.T) {
+	c := NewCollector("myapp")
+	c.Add("buffers", gpool.New(func() *bytes.Buffer { return new(bytes.Buffer) }))
+	if n := testutil.CollectAndCount(c, "myapp_idle"); n != 1 {
+		t.Fatalf("期望导出 1 个 myapp_idle 指标, 得到 %d 个", n)
+	}
+}
@@ -0,0 +1,6 @@
+//go:build race
+
+package gpool
+
+// raceEnabled 表示测试是否在竞态检测模式下运行，此时的耗时不能反映真实的性能。
+const raceEnabled = true
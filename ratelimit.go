@@ -0,0 +1,56 @@
+package gpool
+
+import (
+	"sync"
+	"time"
+)
+
+// rateMaxPoll 是等待创建额度期间检查是否有对象被放回的最长间隔。
+const rateMaxPoll = time.Millisecond
+
+// rateLimiter 将创建新对象的速率限制在每个间隔一次，不允许突发。
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time // 下一次允许创建的时间
+}
+
+func newRateLimiter(rps int) *rateLimiter {
+	return &rateLimiter{interval: time.Second / time.Duration(rps)}
+}
+
+// wait 预约下一次创建的时间并阻塞到那时。
+func (l *rateLimiter) wait() {
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+	time.Sleep(time.Until(at))
+}
+
+// allow 报告现在是否允许创建，允许时消耗这次额度。
+func (l *rateLimiter) allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if now.Before(l.next) {
+		return false
+	}
+	l.next = now.Add(l.interval)
+	return true
+}
+
+// pause 等待到下一次允许创建的时间，但最多等待 rateMaxPoll，以便及时发现被放回的对象。
+func (l *rateLimiter) pause() {
+	l.mu.Lock()
+	d := time.Until(l.next)
+	l.mu.Unlock()
+	if d > rateMaxPoll {
+		d = rateMaxPoll
+	}
+	time.Sleep(d)
+}
@@ -0,0 +1,78 @@
+package gpool
+
+import (
+	"bytes"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// burstGet 并发地从 p 中获取 n 个对象且不放回，返回耗费的时间。
+func burstGet(p *Pool[*bytes.Buffer], n int) time.Duration {
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.Get()
+		}()
+	}
+	wg.Wait()
+	return time.Since(start)
+}
+
+// TestPool_NewRateLimit 测试突发请求下 newFunc 的调用速率不超过限制，且没有对象放回时也不会死锁。
+func TestPool_NewRateLimit(t *testing.T) {
+	const rps, n = 200, 10
+	for _, tc := range []struct {
+		name string
+		opts []Option[*bytes.Buffer]
+	}{
+		{"SyncPool", nil},
+		{"Deterministic", []Option[*bytes.Buffer]{WithDeterministic[*bytes.Buffer]()}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls int64
+			opts := append(tc.opts, WithNewRateLimit[*bytes.Buffer](rps))
+			p := New(func() *bytes.Buffer {
+				atomic.AddInt64(&calls, 1)
+				return new(bytes.Buffer)
+			}, opts...)
+
+			elapsed := burstGet(p, n)
+			if calls != n {
+				t.Fatalf("期望 newFunc 被调用 %d 次, 实际 %d 次", n, calls)
+			}
+			// 第一次创建立即进行，之后每次间隔 1/rps 秒。
+			if want := (n - 1) * time.Second / rps; elapsed < want {
+				t.Fatalf("创建 %d 个对象耗时 %v, 超过了每秒 %d 次的限制", n, elapsed, rps)
+			}
+		})
+	}
+}
+
+// TestPool_NewRateLimit_Reuse 测试等待创建额度的 Get 会直接复用期间被放回的对象。
+func TestPool_NewRateLimit_Reuse(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer](), WithNewRateLimit[*bytes.Buffer](1))
+
+	first := p.Get()
+	got := make(chan *bytes.Buffer)
+	go func() {
+		got <- p.Get()
+	}()
+	time.Sleep(10 * time.Millisecond)
+	p.Put(first)
+
+	select {
+	case x := <-got:
+		if x != first {
+			t.Fatal("期望复用被放回的对象")
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("等待创建额度的 Get 没有复用被放回的对象")
+	}
+}
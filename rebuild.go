@@ -0,0 +1,39 @@
+package gpool
+
+// RebuildIdle 替换池的构造函数，并用它重建所有闲置对象，适合配置重载时所有对象都需要按新参数重新创建的场景。
+//
+// 对于自己持有闲置对象的后端，RebuildIdle 在持有存储的锁时丢弃全部闲置对象，并用 newFunc 创建同样数量的新对象代替它们，
+// 因此其他 goroutine 不会看到新旧对象同时闲置在池中；被替换的对象在释放锁之后以 DiscardRebuilt 为原因被丢弃
+// （实现了 io.Closer 的对象会被关闭）。重建期间 Get 会等待存储的锁，newFunc 应该足够快。
+// 重建创建的对象不计入 Stats 的 Misses，同样受 WithAllocCap 限制，创建失败时闲置对象会少于原来的数量。
+//
+// 之后所有需要新建对象的 Get 都使用 newFunc，包括通过 NewE 创建的池。
+// 仍被借出的对象不受影响，它们放回时照常存入池中；需要区分时可以配合 WithValidateOnPut 使用。
+// sync.Pool 后端的闲置对象无法枚举，RebuildIdle 只替换构造函数，已有的闲置对象要等 GC 回收。
+// ResetPool 会恢复最初传给 New 的构造函数。
+func (p *Pool[T]) RebuildIdle(newFunc func() T) {
+	p.factory.Store(&factory[T]{fn: newFunc})
+	s, ok := p.store.(swapper[T])
+	if !ok || p.isClosed() {
+		return
+	}
+	old := s.swap(func(n int) []T {
+		xs := make([]T, 0, n)
+		for i := 0; i < n; i++ {
+			x, err := p.tryConstruct()
+			if err != nil {
+				break
+			}
+			if p.cfg.beforeStore != nil {
+				x = p.cfg.beforeStore(x)
+			}
+			xs = append(xs, x)
+		}
+		return xs
+	})
+	p.discardAll(old, DiscardRebuilt)
+	// 与 Close 并发时，新对象可能在 Close 清空存储之后才被存入，这里再清理一次。
+	if p.isClosed() {
+		p.discardAll(p.store.drain(), DiscardClosed)
+	}
+}
@@ -0,0 +1,72 @@
+package gpool
+
+import "testing"
+
+// versioned 是带版本号的测试对象，版本号标识它由哪个构造函数创建。
+type versioned struct {
+	version int
+	closed  bool
+}
+
+func (v *versioned) Close() error {
+	v.closed = true
+	return nil
+}
+
+// TestPool_RebuildIdle 测试所有闲置对象都被新构造函数创建的对象替换并被关闭，之后的 Get 使用新构造函数。
+func TestPool_RebuildIdle(t *testing.T) {
+	for name, opt := range map[string]Option[*versioned]{
+		"deterministic": WithDeterministic[*versioned](),
+		"sharded":       WithSharded[*versioned](3),
+		"max-idle":      WithMaxIdle[*versioned](8),
+	} {
+		t.Run(name, func(t *testing.T) {
+			var discarded []string
+			p := New(func() *versioned {
+				return &versioned{version: 1}
+			}, opt, WithOnDiscard(func(_ *versioned, reason string) {
+				discarded = append(discarded, reason)
+			}))
+
+			old := []*versioned{p.Get(), p.Get(), p.Get()}
+			p.PutAll(old)
+			held := p.Get() // 借出的对象不受影响
+			missesBefore := p.Stats().Misses
+
+			p.RebuildIdle(func() *versioned { return &versioned{version: 2} })
+
+			if s := p.Stats(); s.Idle != 2 || s.Misses != missesBefore {
+				t.Errorf("应该重建同样数量的闲置对象且不计入 Misses: %+v", s)
+			}
+			closed := 0
+			for _, v := range old {
+				if v.closed {
+					closed++
+				}
+			}
+			if closed != 2 || held.closed || len(discarded) != 2 || discarded[0] != DiscardRebuilt {
+				t.Errorf("被替换的闲置对象应该以 DiscardRebuilt 被丢弃并关闭, 关闭 %d 个, 丢弃 %v", closed, discarded)
+			}
+			for i := 0; i < 3; i++ {
+				if v := p.Get(); v.version != 2 {
+					t.Errorf("第 %d 次 Get 应该返回新构造函数创建的对象, 得到版本 %d", i, v.version)
+				}
+			}
+			if s := p.Stats(); s.Misses != missesBefore+1 {
+				t.Errorf("池空后应该用新构造函数创建对象: %+v", s)
+			}
+		})
+	}
+}
+
+// TestPool_RebuildIdle_ResetPool 测试 ResetPool 恢复最初的构造函数。
+func TestPool_RebuildIdle_ResetPool(t *testing.T) {
+	p := New(func() *versioned {
+		return &versioned{version: 1}
+	}, WithDeterministic[*versioned]())
+	p.RebuildIdle(func() *versioned { return &versioned{version: 2} })
+	p.ResetPool()
+	if v := p.Get(); v.version != 1 {
+		t.Errorf("ResetPool 后应该使用最初的构造函数, 得到版本 %d", v.version)
+	}
+}
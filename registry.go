@@ -0,0 +1,97 @@
+package gpool
+
+import (
+	"reflect"
+	"sync"
+)
+
+// registry 是按类型索引的全局池注册表。
+var registry struct {
+	mu        sync.Mutex
+	factories map[reflect.Type]any // 值为 func() *Pool[T]
+	pools     sync.Map             // reflect.Type -> *Pool[T]
+}
+
+// typeOf 返回类型 T 本身的 reflect.Type，对接口类型同样有效。
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// Register 为类型 T 的全局池注册构造函数和选项，必须在第一次调用 Global[T] 之前调用。
+//
+// 与 database/sql.Register 类似，对同一类型重复注册，
+// 或在全局池已经创建之后再注册，都会导致 panic。
+func Register[T any](newFunc func() T, opts ...Option[T]) {
+	t := typeOf[T]()
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if _, ok := registry.pools.Load(t); ok {
+		panic("gpool: Register called after Global for type " + t.String())
+	}
+	if _, dup := registry.factories[t]; dup {
+		panic("gpool: Register called twice for type " + t.String())
+	}
+	if registry.factories == nil {
+		registry.factories = make(map[reflect.Type]any)
+	}
+	registry.factories[t] = func() *Pool[T] {
+		return New(newFunc, opts...)
+	}
+}
+
+// Global 返回类型 T 的全局单例池，并在第一次调用时创建它。
+//
+// 如果之前通过 Register 为 T 注册过构造函数，就使用它；否则使用默认构造函数：
+// 对指针类型 *E 返回一个新分配的 E 零值，对其他类型返回 T 的零值。
+// Global 是并发安全的，并发的首次调用只会创建一个池。
+func Global[T any]() *Pool[T] {
+	t := typeOf[T]()
+	if p, ok := registry.pools.Load(t); ok {
+		return p.(*Pool[T])
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if p, ok := registry.pools.Load(t); ok {
+		return p.(*Pool[T])
+	}
+	var p *Pool[T]
+	if f, ok := registry.factories[t]; ok {
+		p = f.(func() *Pool[T])()
+	} else {
+		p = New(defaultNew[T](t))
+	}
+	registry.pools.Store(t, p)
+	return p
+}
+
+// defaultNew 返回未注册类型的默认构造函数。
+func defaultNew[T any](t reflect.Type) func() T {
+	if t.Kind() == reflect.Pointer {
+		elem := t.Elem()
+		return func() T {
+			return reflect.New(elem).Interface().(T)
+		}
+	}
+	return func() T {
+		var zero T
+		return zero
+	}
+}
+
+// RegistryStats 返回所有已经通过 Global 创建的全局池的统计信息，以池保存的类型名（例如 "*bytes.Buffer"）为键，
+// 使一个进程中的所有全局池可以通过同一个入口观测，而不必逐个接入。
+// 不同包中同名的类型会共用一个键，它们的统计信息会被合并。
+//
+// RegistryStats 可以与 Register 和 Global 并发调用；结果中是否包含正在被创建的池取决于调用的先后。
+func RegistryStats() map[string]Stats {
+	stats := make(map[string]Stats)
+	registry.pools.Range(func(k, v any) bool {
+		name := k.(reflect.Type).String()
+		s := stats[name]
+		s.add(v.(Managed).Stats())
+		stats[name] = s
+		return true
+	})
+	return stats
+}
@@ -0,0 +1,154 @@
+package gpool
+
+import (
+	"bytes"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// unregister 从全局注册表中移除类型 T，使测试可以重复运行。
+func unregister[T any]() {
+	t := typeOf[T]()
+	registry.mu.Lock()
+	delete(registry.factories, t)
+	registry.pools.Delete(t)
+	registry.mu.Unlock()
+}
+
+// TestGlobal_Registered 测试 Global 在多次调用中返回同一个池，并使用通过 Register 注册的构造函数。
+func TestGlobal_Registered(t *testing.T) {
+	t.Cleanup(unregister[*bytes.Buffer])
+
+	var newCounter int32
+	Register(func() *bytes.Buffer {
+		atomic.AddInt32(&newCounter, 1)
+		return bytes.NewBufferString("registered")
+	})
+
+	p1 := Global[*bytes.Buffer]()
+	p2 := Global[*bytes.Buffer]()
+	if p1 != p2 {
+		t.Fatal("Global 应该在多次调用中返回同一个池")
+	}
+
+	buf := p1.Get()
+	if buf.String() != "registered" {
+		t.Fatalf("Global 应该使用注册的构造函数, 期望 'registered', 得到 '%s'", buf.String())
+	}
+	if atomic.LoadInt32(&newCounter) != 1 {
+		t.Fatalf("注册的构造函数应该被调用一次, 但实际调用了 %d 次", atomic.LoadInt32(&newCounter))
+	}
+}
+
+// TestGlobal_Default 测试未注册的类型使用默认构造函数。
+func TestGlobal_Default(t *testing.T) {
+	type defaultObject struct {
+		X int
+	}
+
+	if obj := Global[*defaultObject]().Get(); obj == nil || obj.X != 0 {
+		t.Fatalf("指针类型的默认构造函数应该返回新分配的零值, 得到 %+v", obj)
+	}
+	if v := Global[defaultObject]().Get(); v.X != 0 {
+		t.Fatalf("值类型的默认构造函数应该返回零值, 得到 %+v", v)
+	}
+}
+
+// TestGlobal_ConcurrentFirstUse 测试并发的首次调用只会创建一个池。
+func TestGlobal_ConcurrentFirstUse(t *testing.T) {
+	type concurrentObject struct{}
+
+	const numGoroutines = 16
+	pools := make([]*Pool[*concurrentObject], numGoroutines)
+	var wg sync.WaitGroup
+	wg.Add(numGoroutines)
+	for i := 0; i < numGoroutines; i++ {
+		go func(i int) {
+			defer wg.Done()
+			pools[i] = Global[*concurrentObject]()
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 1; i < numGoroutines; i++ {
+		if pools[i] != pools[0] {
+			t.Fatal("并发的首次调用应该返回同一个池")
+		}
+	}
+}
+
+// TestRegister_Panics 测试重复注册以及在 Global 之后注册都会 panic。
+func TestRegister_Panics(t *testing.T) {
+	type lateObject struct{}
+	type duplicateObject struct{}
+	t.Cleanup(unregister[*lateObject])
+	t.Cleanup(unregister[*duplicateObject])
+
+	mustPanic := func(name string, fn func()) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s 应该 panic", name)
+			}
+		}()
+		fn()
+	}
+
+	Global[*lateObject]()
+	mustPanic("在 Global 之后调用 Register", func() {
+		Register(func() *lateObject { return &lateObject{} })
+	})
+
+	Register(func() *duplicateObject { return &duplicateObject{} })
+	mustPanic("重复调用 Register", func() {
+		Register(func() *duplicateObject { return &duplicateObject{} })
+	})
+}
+
+// TestRegistryStats 测试 RegistryStats 按类型名返回每个全局池的统计信息，并且可以与池的创建并发调用。
+func TestRegistryStats(t *testing.T) {
+	type statsA struct{}
+	type statsB struct{}
+	t.Cleanup(unregister[*statsA])
+	t.Cleanup(unregister[*statsB])
+
+	// 使用确定性后端，避免竞态检测模式下 sync.Pool 随机丢弃放回的对象，使 Hits 不确定。
+	Register(func() *statsA { return &statsA{} }, WithDeterministic[*statsA]())
+	a := Global[*statsA]()
+	a.Put(a.Get())
+	a.Get()
+	b := Global[*statsB]()
+	b.Get()
+
+	stats := RegistryStats()
+	sa, sb := stats["*gpool.statsA"], stats["*gpool.statsB"]
+	if sa.Gets != 2 || sa.Hits != 1 || sa.Puts != 1 || sa.Outstanding != 1 {
+		t.Errorf("*gpool.statsA 的统计信息不正确: %+v", sa)
+	}
+	if sb.Gets != 1 || sb.Misses != 1 || sb.Puts != 0 {
+		t.Errorf("*gpool.statsB 的统计信息不正确: %+v", sb)
+	}
+
+	type concurrentStats struct{}
+	t.Cleanup(unregister[*concurrentStats])
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			p := Global[*concurrentStats]()
+			p.Put(p.Get())
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			RegistryStats()
+		}
+	}()
+	wg.Wait()
+	if s := RegistryStats()["*gpool.concurrentStats"]; s.Gets != 100 || s.Puts != 100 {
+		t.Errorf("*gpool.concurrentStats 的统计信息不正确: %+v", s)
+	}
+}
@@ -0,0 +1,88 @@
+package gpool
+
+import "reflect"
+
+// Resetter 是可以将自身恢复到干净状态的对象，例如 *bytes.Buffer。
+type Resetter interface {
+	Reset()
+}
+
+// DirtyResetter 是能够报告自身自上次重置以来是否被修改过的 Resetter。
+// 启用自动重置时，Put 只会重置 Dirty 返回 true 的对象，从而跳过只读借用的重置开销。
+type DirtyResetter interface {
+	Resetter
+	Dirty() bool
+}
+
+var (
+	resetterType      = reflect.TypeOf((*Resetter)(nil)).Elem()
+	dirtyResetterType = reflect.TypeOf((*DirtyResetter)(nil)).Elem()
+)
+
+// resetMode 表示 Put 时如何自动重置对象。
+type resetMode int
+
+const (
+	resetNone    resetMode = iota // 不自动重置
+	resetAlways                   // T 实现了 Resetter，总是重置
+	resetIfDirty                  // T 实现了 DirtyResetter，只在 Dirty 时重置
+)
+
+// detectResetMode 根据类型 T 实现的接口确定自动重置的方式。
+// 检测只在创建池时进行一次，避免在每次 Put 时做类型断言之外的开销。
+func detectResetMode[T any]() resetMode {
+	t := typeOf[T]()
+	switch {
+	case t.Implements(dirtyResetterType):
+		return resetIfDirty
+	case t.Implements(resetterType):
+		return resetAlways
+	}
+	return resetNone
+}
+
+// reset 按 p.resetMode 自动重置对象。
+func (p *Pool[T]) reset(x T) {
+	switch p.resetMode {
+	case resetAlways:
+		any(x).(Resetter).Reset()
+	case resetIfDirty:
+		if d := any(x).(DirtyResetter); d.Dirty() {
+			d.Reset()
+		}
+	}
+}
+
+// NewResettable 创建一个在 Put 时自动调用对象的 Reset 的 Pool，由类型约束在编译期保证 T 实现了 Resetter，
+// 使调用方不会因为忘记清理而把脏数据泄漏给下一个使用者。它等价于使用 WithAutoReset 的 New：
+// 如果 T 还实现了 DirtyResetter，只有 Dirty 返回 true 的对象才会被重置。
+func NewResettable[T Resetter](newFunc func() T, opts ...Option[T]) *Pool[T] {
+	return New(newFunc, append([]Option[T]{WithAutoReset[T]()}, opts...)...)
+}
+
+// Recyclable 是具有完整借用生命周期的对象：Prepare 在对象被借出时调用，使它进入可用状态；
+// Finish 在对象被放回时调用，清理使用期间留下的状态。
+type Recyclable interface {
+	Prepare()
+	Finish()
+}
+
+// NewRecyclable 创建一个自动管理 Recyclable 对象生命周期的 Pool，由类型约束在编译期保证 T 实现了 Recyclable。
+//
+// 每次借出对象（Get、GetAll、Chan 等）时，池都会在完成校验等所有检查后、把对象交给调用方之前调用 Prepare，
+// 因此调用方拿到的总是已经准备好的对象；每次对象被放回（Put、PutAll、Transfer 等）时，
+// 池会在存储或丢弃它之前调用 Finish。每个 Get/Put 周期中两者各被调用一次，预热创建的对象不会触发它们。
+// nil 对象不会触发 Prepare 或 Finish。
+//
+// Finish 在 Resetter 的自动重置、WithRecycle 等 Put 时的处理之前运行。
+func NewRecyclable[T Recyclable](newFunc func() T, opts ...Option[T]) *Pool[T] {
+	return New(newFunc, append([]Option[T]{withRecyclable[T]()}, opts...)...)
+}
+
+// withRecyclable 让池在借出和放回对象时调用 Recyclable 的 Prepare 和 Finish。
+func withRecyclable[T Recyclable]() Option[T] {
+	return func(c *config[T]) {
+		c.prepare = func(x T) { x.Prepare() }
+		c.finish = func(x T) { x.Finish() }
+	}
+}
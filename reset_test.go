@@ -0,0 +1,318 @@
+package gpool
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// dirtyObject 是一个实现了 DirtyResetter 的测试对象。
+type dirtyObject struct {
+	data   []byte
+	dirty  bool
+	resets int
+}
+
+func (o *dirtyObject) Write(s string) {
+	o.data = append(o.data, s...)
+	o.dirty = true
+}
+
+func (o *dirtyObject) Dirty() bool { return o.dirty }
+
+func (o *dirtyObject) Reset() {
+	o.data = o.data[:0]
+	o.dirty = false
+	o.resets++
+}
+
+// TestPool_AutoReset 测试启用 WithAutoReset 后，实现了 Resetter 的对象在 Put 时被自动重置。
+func TestPool_AutoReset(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer](), WithAutoReset[*bytes.Buffer]())
+
+	buf := p.Get()
+	buf.WriteString("dirty")
+	p.Put(buf)
+
+	if got := p.Get(); got.Len() != 0 {
+		t.Fatalf("启用自动重置后, 放回的对象应该是干净的, 得到 %q", got.String())
+	}
+}
+
+// TestNewResettable 测试 NewResettable 创建的池在 Put 时自动重置对象，opts 仍然生效。
+func TestNewResettable(t *testing.T) {
+	p := NewResettable(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer]())
+	if c := p.Config(); !c.AutoReset || c.Backend != BackendDeterministic {
+		t.Fatalf("期望启用自动重置并使用确定性后端, 得到 %+v", c)
+	}
+
+	buf := p.Get()
+	buf.WriteString("dirty")
+	p.Put(buf)
+	if got := p.Get(); got != buf || got.Len() != 0 {
+		t.Fatalf("放回的对象应该被自动重置, 得到 %q", got.String())
+	}
+}
+
+// TestPool_AutoReset_Disabled 测试默认情况下 Put 不会重置对象。
+func TestPool_AutoReset_Disabled(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer]())
+
+	buf := p.Get()
+	buf.WriteString("dirty")
+	p.Put(buf)
+
+	if got := p.Get(); got.String() != "dirty" {
+		t.Fatalf("未启用自动重置时, 对象应该保持原样, 得到 %q", got.String())
+	}
+}
+
+// TestPool_AutoReset_Dirty 测试实现了 DirtyResetter 的对象只在被修改过时才会被重置。
+func TestPool_AutoReset_Dirty(t *testing.T) {
+	p := New(func() *dirtyObject {
+		return &dirtyObject{}
+	}, WithDeterministic[*dirtyObject](), WithAutoReset[*dirtyObject]())
+
+	// 只读借用：未被修改的对象不应该被重置。
+	obj := p.Get()
+	p.Put(obj)
+	if obj.resets != 0 {
+		t.Fatalf("未被修改的对象不应该被重置, 实际重置了 %d 次", obj.resets)
+	}
+
+	// 修改过的对象应该被重置。
+	obj = p.Get()
+	obj.Write("modified")
+	p.Put(obj)
+	if obj.resets != 1 || len(obj.data) != 0 {
+		t.Fatalf("被修改过的对象应该被重置一次, 实际重置了 %d 次, data=%q", obj.resets, obj.data)
+	}
+}
+
+// TestDetectResetMode 测试重置方式的检测。
+func TestDetectResetMode(t *testing.T) {
+	if m := detectResetMode[*dirtyObject](); m != resetIfDirty {
+		t.Errorf("*dirtyObject 应该只在 Dirty 时重置, 得到 %v", m)
+	}
+	if m := detectResetMode[*bytes.Buffer](); m != resetAlways {
+		t.Errorf("*bytes.Buffer 应该总是重置, 得到 %v", m)
+	}
+	if m := detectResetMode[int](); m != resetNone {
+		t.Errorf("int 不应该被重置, 得到 %v", m)
+	}
+}
+
+// TestPool_Recycle 测试 WithRecycle 在 Put 时用新对象替换旧对象，并把旧对象的缓冲区转移过去。
+func TestPool_Recycle(t *testing.T) {
+	type message struct {
+		id  int
+		buf []byte
+	}
+	nextID := 0
+	p := New(func() *message {
+		nextID++
+		return &message{id: nextID}
+	}, WithDeterministic[*message](), WithRecycle(func(old *message) *message {
+		if cap(old.buf) == 0 {
+			return nil
+		}
+		return &message{id: old.id + 100, buf: old.buf[:0]}
+	}))
+
+	m := p.Get()
+	m.buf = append(make([]byte, 0, 64), "hello"...)
+	p.Put(m)
+
+	got := p.Get()
+	if got == m || got.id != m.id+100 {
+		t.Fatalf("期望取回由回收函数创建的新对象, 得到 %+v", got)
+	}
+	if len(got.buf) != 0 || cap(got.buf) != 64 || &got.buf[:1][0] != &m.buf[0] {
+		t.Fatalf("期望旧对象的缓冲区被清空后转移到新对象, 得到 len=%d cap=%d", len(got.buf), cap(got.buf))
+	}
+
+	// 回收函数返回 nil 时不存入任何对象。
+	p.Put(&message{})
+	if s := p.Stats(); s.Idle != 0 || s.DiscardsByReason[DiscardNil] != 1 {
+		t.Fatalf("期望回收函数返回 nil 的对象以 %q 被丢弃, 得到 %+v", DiscardNil, s)
+	}
+}
+
+// TestPool_Transfer 测试 Transfer 的对象按目标池的规则重置后可以从目标池取回。
+func TestPool_Transfer(t *testing.T) {
+	newObject := func() *dirtyObject { return &dirtyObject{} }
+	src := New(newObject, WithDeterministic[*dirtyObject](), WithMax[*dirtyObject](1))
+	dst := New(newObject, WithDeterministic[*dirtyObject](), WithAutoReset[*dirtyObject]())
+
+	x := src.Get()
+	x.Write("payload")
+	src.Transfer(dst, x)
+
+	if s := src.Stats(); s.Outstanding != 0 || s.Idle != 0 {
+		t.Fatalf("Transfer 后对象不应再算作从源池借出, 得到 %+v", s)
+	}
+	if _, err := src.TryGetAll(1); err != nil {
+		t.Fatalf("Transfer 应归还源池的额度, 得到 %v", err)
+	}
+	if s := dst.Stats(); s.Idle != 1 || s.Puts != 0 {
+		t.Fatalf("期望对象成为目标池的闲置对象, 得到 %+v", s)
+	}
+
+	got := dst.Get()
+	if got != x {
+		t.Fatal("期望从目标池取回 Transfer 的对象")
+	}
+	if got.resets != 1 || len(got.data) != 0 {
+		t.Fatalf("期望对象按目标池的规则被重置, resets=%d data=%q", got.resets, got.data)
+	}
+}
+
+// TestPool_Transfer_Metadata 测试 Transfer 后源池不再保留对象的元数据，元数据改由目标池记录。
+func TestPool_Transfer_Metadata(t *testing.T) {
+	newObject := func() *dirtyObject { return &dirtyObject{} }
+	src := New(newObject, WithMetadata[*dirtyObject]())
+	dst := New(newObject, WithMetadata[*dirtyObject]())
+	entries := func(p *Pool[*dirtyObject]) int {
+		p.meta.mu.Lock()
+		defer p.meta.mu.Unlock()
+		return len(p.meta.m)
+	}
+
+	for i := 0; i < 3; i++ {
+		src.Transfer(dst, src.Get())
+	}
+	if n := entries(src); n != 0 {
+		t.Fatalf("Transfer 后源池不应该保留元数据, 还剩 %d 条", n)
+	}
+	if n := entries(dst); n != 3 || dst.Meta(dst.Get()) == nil {
+		t.Fatalf("目标池应该为收到的对象记录元数据, 得到 %d 条", n)
+	}
+}
+
+// TestPool_ResetFields 测试 WithResetFields 只清理声明的字段，其他字段保留复用的值。
+func TestPool_ResetFields(t *testing.T) {
+	type record struct {
+		ID      int
+		Tags    []string
+		Payload *bytes.Buffer
+		scratch []byte // 有意保留，在多次使用之间复用
+	}
+	p := New(func() *record {
+		return &record{scratch: make([]byte, 0, 256)}
+	}, WithDeterministic[*record](), WithResetFields(
+		func(r *record) { r.ID = 0 },
+		func(r *record) { r.Tags = r.Tags[:0] },
+	), WithResetFields(
+		func(r *record) { r.Payload = nil },
+	))
+
+	r := p.Get()
+	r.ID = 42
+	r.Tags = append(r.Tags, "a", "b")
+	r.Payload = new(bytes.Buffer)
+	r.scratch = append(r.scratch, "kept"...)
+	p.Put(r)
+
+	got := p.Get()
+	if got.ID != 0 || len(got.Tags) != 0 || cap(got.Tags) < 2 || got.Payload != nil {
+		t.Fatalf("期望声明的字段被清理, 得到 %+v", got)
+	}
+	if string(got.scratch) != "kept" || cap(got.scratch) != 256 {
+		t.Fatalf("未声明的字段应该保留, 得到 %q (cap %d)", got.scratch, cap(got.scratch))
+	}
+}
+
+// TestPool_ResetVerify 测试调试模式下不完整的重置会被 WithResetVerify 发现。
+func TestPool_ResetVerify(t *testing.T) {
+	type message struct {
+		header []byte
+		body   []byte
+	}
+	// 重置遗漏了 body。
+	incomplete := WithResetFields(func(m *message) { m.header = m.header[:0] })
+	clean := WithResetVerify(func(m *message) bool { return len(m.header) == 0 && len(m.body) == 0 })
+	newMessage := func() *message { return &message{} }
+
+	p := New(newMessage, incomplete, clean, WithDebug[*message]())
+	m := p.Get()
+	m.header = append(m.header, "h"...)
+	m.body = append(m.body, "b"...)
+	func() {
+		defer func() {
+			if msg, _ := recover().(string); !strings.Contains(msg, "not fully cleaned") {
+				t.Fatalf("期望重置检查失败时 panic, 得到 %q", msg)
+			}
+		}()
+		p.Put(m)
+	}()
+
+	// 未启用调试模式时不做检查。
+	p = New(newMessage, incomplete, clean)
+	m = p.Get()
+	m.body = append(m.body, "b"...)
+	p.Put(m)
+}
+
+// lifecycle 是记录 Prepare 和 Finish 调用顺序的 Recyclable 测试对象。
+type lifecycle struct {
+	events []string
+	ready  bool
+}
+
+func (l *lifecycle) Prepare() {
+	l.events = append(l.events, "prepare")
+	l.ready = true
+}
+
+func (l *lifecycle) Finish() {
+	l.events = append(l.events, "finish")
+	l.ready = false
+}
+
+// TestNewRecyclable 测试每个 Get/Put 周期中 Prepare 和 Finish 按顺序各调用一次，且 Prepare 在校验之后运行。
+func TestNewRecyclable(t *testing.T) {
+	var validated []bool
+	p := NewRecyclable(func() *lifecycle {
+		return new(lifecycle)
+	}, WithDeterministic[*lifecycle](), WithValidator(func(l *lifecycle) bool {
+		validated = append(validated, l.ready)
+		return true
+	}))
+
+	l := p.Get()
+	if !l.ready {
+		t.Fatal("Get 返回的对象应该已经被 Prepare")
+	}
+	p.Put(l)
+
+	if got := p.Get(); got != l {
+		t.Fatal("期望取回之前放回的对象")
+	}
+	p.Put(l)
+	p.Put(nil)
+
+	want := []string{"prepare", "finish", "prepare", "finish"}
+	if strings.Join(l.events, ",") != strings.Join(want, ",") {
+		t.Errorf("期望生命周期 %v, 得到 %v", want, l.events)
+	}
+	if len(validated) != 1 || validated[0] {
+		t.Errorf("校验应该在 Prepare 之前对未准备的对象运行, 得到 %v", validated)
+	}
+
+	// 预热的对象直到被借出时才触发 Prepare。
+	p.Clear()
+	p.WarmUp(1)
+	if !p.Inspect(func(w *lifecycle) {
+		if len(w.events) != 0 {
+			t.Errorf("预热不应触发 Prepare 或 Finish, 得到 %v", w.events)
+		}
+	}) {
+		t.Error("预热后池中应该有闲置对象")
+	}
+}
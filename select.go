@@ -0,0 +1,22 @@
+package gpool
+
+// SelectGet 从多个池中获取一个对象，返回该对象以及它所属的池在 pools 中的下标。
+//
+// SelectGet 按顺序查找第一个有闲置对象可以立即取出的池；有界池只有在还有空闲额度时才会被考虑。
+// 如果所有池都没有现成的闲置对象，就从 pools[0] 获取（必要时创建新对象），
+// 因此调用方应将创建成本最低的池放在最前面。
+// 得到的对象应放回下标对应的池中。
+//
+// sync.Pool 后端无法在不创建新对象的情况下判断是否有闲置对象，因此在第一轮查找中会被跳过。
+// pools 为空时 SelectGet 会 panic。
+func SelectGet[T any](pools ...*Pool[T]) (T, int) {
+	if len(pools) == 0 {
+		panic("gpool: SelectGet called with no pools")
+	}
+	for i, p := range pools {
+		if x, ok := p.TryGet(); ok {
+			return x, i
+		}
+	}
+	return pools[0].Get(), 0
+}
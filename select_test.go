@@ -0,0 +1,60 @@
+package gpool
+
+import "testing"
+
+// tierObject 记录自己由哪一级的池创建。
+type tierObject struct {
+	tier string
+}
+
+func newTierPool(tier string, opts ...Option[*tierObject]) *Pool[*tierObject] {
+	opts = append([]Option[*tierObject]{WithDeterministic[*tierObject]()}, opts...)
+	return New(func() *tierObject {
+		return &tierObject{tier: tier}
+	}, opts...)
+}
+
+// TestSelectGet_PrefersIdle 测试 SelectGet 优先从有闲置对象的池中取对象。
+func TestSelectGet_PrefersIdle(t *testing.T) {
+	a, b := newTierPool("a"), newTierPool("b")
+	idle := b.Get()
+	b.Put(idle)
+
+	obj, i := SelectGet(a, b)
+	if i != 1 || obj != idle {
+		t.Fatalf("应该从有闲置对象的池 b 中取出对象, 得到下标 %d, 对象 %+v", i, obj)
+	}
+	if s := a.Stats(); s.Gets != 0 || s.Misses != 0 {
+		t.Fatalf("池 a 没有闲置对象, 不应该被使用, 得到 %+v", s)
+	}
+}
+
+// TestSelectGet_FallsBackToFirst 测试所有池都为空时 SelectGet 从第一个池创建新对象。
+func TestSelectGet_FallsBackToFirst(t *testing.T) {
+	a, b := newTierPool("a"), newTierPool("b")
+
+	obj, i := SelectGet(a, b)
+	if i != 0 || obj.tier != "a" {
+		t.Fatalf("所有池都为空时应该从第一个池创建对象, 得到下标 %d, 对象 %+v", i, obj)
+	}
+	if s := b.Stats(); s.Gets != 0 {
+		t.Fatalf("池 b 不应该被使用, 得到 %+v", s)
+	}
+}
+
+// TestSelectGet_SkipsExhaustedBounded 测试没有空闲额度的有界池会被跳过，即使它有闲置对象。
+func TestSelectGet_SkipsExhaustedBounded(t *testing.T) {
+	a := newTierPool("a", WithMax[*tierObject](2))
+	b := newTierPool("b")
+	// 借出 2 个对象并放回 1 个，再把上限调低到 1：a 有闲置对象，但没有空闲额度。
+	held := a.GetAll(2)
+	a.Put(held[0])
+	a.SetMax(1)
+
+	idle := b.Get()
+	b.Put(idle)
+
+	if _, i := SelectGet(a, b); i != 1 {
+		t.Fatalf("没有空闲额度的有界池应该被跳过, 得到下标 %d", i)
+	}
+}
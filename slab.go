@@ -0,0 +1,97 @@
+package gpool
+
+import "math/bits"
+
+const (
+	// slabMinShift 是 SlabPool 最小级别的容量的对数，即最小级别为 64 字节。
+	slabMinShift = 6
+	// scratchMaxCap 是 GetScratch 复用的缓冲区的最大容量。
+	scratchMaxCap = 1 << 20
+)
+
+// SlabPool 是一个按容量分级的 []byte 池，适合复用大小各异的临时缓冲区。
+// 每个级别保存容量在 [2^i, 2^(i+1)) 范围内的切片，因此 Get 总能从对应的级别取到容量足够的切片，
+// 而不会把一个小切片交给需要大缓冲区的调用方。
+//
+// 所有方法都是并发安全的。
+type SlabPool struct {
+	maxCap  int
+	classes []*Pool[*[]byte] // classes[i] 保存容量不小于 2^(i+slabMinShift) 的切片
+	// headers 保存 Get 取出切片后空出的 *[]byte，供 Put 包装放回的切片，使一次 Get/Put 不需要分配新的指针。
+	headers *Pool[*[]byte]
+}
+
+// NewSlabPool 创建一个最多复用容量为 maxCap 字节的切片的 SlabPool。
+// 容量超过 maxCap 的切片在 Put 时会被丢弃，避免个别超大的缓冲区长期占用内存。
+func NewSlabPool(maxCap int) *SlabPool {
+	s := &SlabPool{maxCap: maxCap, headers: New(func() *[]byte { return new([]byte) })}
+	for shift := slabMinShift; 1<<shift <= maxCap; shift++ {
+		size := 1 << shift
+		s.classes = append(s.classes, New(func() *[]byte {
+			b := make([]byte, 0, size)
+			return &b
+		}))
+	}
+	return s
+}
+
+// Get 返回一个长度为 0、容量不小于 minCap 的切片。
+// minCap 超过 maxCap 时直接分配新的切片，它在 Put 时也不会被复用。
+func (s *SlabPool) Get(minCap int) []byte {
+	i := slabClass(minCap, true)
+	if i >= len(s.classes) {
+		return make([]byte, 0, minCap)
+	}
+	h := s.classes[i].Get()
+	b := (*h)[:0]
+	*h = nil
+	s.headers.Put(h)
+	return b
+}
+
+// Put 将切片 b 放回池中，之后它可能被 Get 返回给需要容量不超过 cap(b) 的调用方。
+// 容量超过 maxCap 或小于最小级别的切片会被丢弃。
+func (s *SlabPool) Put(b []byte) {
+	if cap(b) > s.maxCap || cap(b) < 1<<slabMinShift {
+		return
+	}
+	h := s.headers.Get()
+	*h = b[:0]
+	s.classes[slabClass(cap(b), false)].Put(h)
+}
+
+// slabClass 返回容量 n 所属的级别：up 为 true 时向上取整，得到容量不小于 n 的最小级别；
+// 否则向下取整，得到容量不超过 n 的最大级别。
+func slabClass(n int, up bool) int {
+	if n <= 1<<slabMinShift {
+		return 0
+	}
+	shift := bits.Len(uint(n)) - 1
+	if up && n&(n-1) != 0 {
+		shift++
+	}
+	return shift - slabMinShift
+}
+
+// scratch 是 GetScratch 使用的共享 SlabPool。
+var scratch = NewSlabPool(scratchMaxCap)
+
+// GetScratch 从共享的 SlabPool 中取出一个长度为 0、容量不小于 minCap 的临时缓冲区，
+// 并返回一个将它归还的函数，是“借一个临时缓冲区，用完还回去”的便捷写法：
+//
+//	buf, done := gpool.GetScratch(4096)
+//	defer done()
+//	buf = append(buf, data...)
+//
+// 归还的是最初取出的缓冲区；容量超过 1 MiB 的缓冲区不会被复用。
+// 返回的函数可以安全地多次调用，只有第一次调用会归还缓冲区，之后不应再使用该缓冲区。
+func GetScratch(minCap int) ([]byte, func()) {
+	b := scratch.Get(minCap)
+	returned := false
+	return b, func() {
+		if !returned {
+			returned = true
+			scratch.Put(b)
+		}
+	}
+}
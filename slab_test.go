@@ -0,0 +1,83 @@
+package gpool
+
+import "testing"
+
+// TestSlabPool 测试 SlabPool 按级别复用切片，且只返回容量足够的切片。
+func TestSlabPool(t *testing.T) {
+	s := NewSlabPool(1 << 12)
+
+	b := s.Get(100)
+	if len(b) != 0 || cap(b) < 100 {
+		t.Fatalf("期望长度为 0、容量不小于 100 的切片, 得到 len=%d cap=%d", len(b), cap(b))
+	}
+	b = append(b, "data"...)
+	s.Put(b)
+
+	// 容量为 128 的切片不会被交给需要 200 字节的调用方。
+	if got := s.Get(200); cap(got) < 200 {
+		t.Fatalf("期望容量不小于 200, 得到 %d", cap(got))
+	}
+	// 超过 maxCap 的请求直接分配，且不会被复用。
+	big := s.Get(1 << 13)
+	if cap(big) < 1<<13 {
+		t.Fatalf("期望容量不小于 %d, 得到 %d", 1<<13, cap(big))
+	}
+	s.Put(big)
+}
+
+// TestSlabPool_NoAllocs 测试一次 Get/Put 在稳定状态下不需要分配内存。
+func TestSlabPool_NoAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("竞态检测模式下 sync.Pool 会随机丢弃放入的对象")
+	}
+	s := NewSlabPool(1 << 12)
+	s.Put(s.Get(100))
+	if n := testing.AllocsPerRun(100, func() {
+		s.Put(s.Get(100))
+	}); n != 0 {
+		t.Errorf("一次 Get/Put 期望不分配内存, 得到 %v 次分配", n)
+	}
+}
+
+// TestGetScratch 测试 GetScratch 返回容量足够的空缓冲区，并在多次调用之间复用。
+func TestGetScratch(t *testing.T) {
+	const n = 3000
+	var first *byte
+	for i := 0; i < 3; i++ {
+		buf, done := GetScratch(n)
+		if len(buf) != 0 || cap(buf) < n {
+			t.Fatalf("期望长度为 0、容量不小于 %d 的缓冲区, 得到 len=%d cap=%d", n, len(buf), cap(buf))
+		}
+		p := &buf[:1][0]
+		if first == nil {
+			first = p
+		} else if p != first {
+			// sync.Pool 可能丢弃放入的对象（例如在 -race 模式下）。
+			t.Skip("sync.Pool 丢弃了放入的缓冲区")
+		}
+		buf = append(buf, "scratch"...)
+		done()
+		done()
+	}
+}
+
+// TestSlabClass 测试容量到级别的换算。
+func TestSlabClass(t *testing.T) {
+	for _, tc := range []struct {
+		n    int
+		up   bool
+		want int
+	}{
+		{0, true, 0},
+		{64, true, 0},
+		{65, true, 1},
+		{128, true, 1},
+		{128, false, 1},
+		{255, false, 1},
+		{256, false, 2},
+	} {
+		if got := slabClass(tc.n, tc.up); got != tc.want {
+			t.Errorf("slabClass(%d, %v) = %d, 期望 %d", tc.n, tc.up, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package gpool
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// profileVersion 是 SaveProfile 写出的预热配置的格式版本。
+const profileVersion = 1
+
+// poolProfile 是 SaveProfile 写出的预热配置，只包含估算池规模的提示，不包含对象本身。
+type poolProfile struct {
+	Version int `json:"version"`
+	// Count 是建议预热的对象数量。
+	Count int `json:"count"`
+	// AvgSize 是闲置对象的平均大小（字节），未设置 WithMeasure 时为 0。
+	AvgSize int `json:"avgSize"`
+}
+
+// errProfileVersion 表示预热配置的格式版本不受支持。
+var errProfileVersion = errors.New("gpool: unsupported profile version")
+
+// SaveProfile 把池的规模提示以 JSON 格式写入 w，供进程下次启动时通过 LoadProfile 读取，
+// 使频繁重启的进程（例如命令行工具）一启动就能预热到以往需要的规模。写出的只是提示，不包含对象本身。
+//
+// 建议预热的数量取借出数量的历史最高值（见 Peak）与当前闲置数量中较大的一个；
+// 平均大小是用 WithMeasure 测得的闲置对象的平均大小，未设置 WithMeasure 或没有闲置对象时为 0。
+// 通常在关闭池之前调用。
+func (p *Pool[T]) SaveProfile(w io.Writer) error {
+	prof := poolProfile{Version: profileVersion, Count: int(p.Peak())}
+	idle := 0
+	if p.store != nil {
+		idle = p.store.len()
+	}
+	if idle > prof.Count {
+		prof.Count = idle
+	}
+	if idle > 0 {
+		prof.AvgSize = int(p.EstimatedBytes() / int64(idle))
+	}
+	return json.NewEncoder(w).Encode(prof)
+}
+
+// LoadProfile 从 r 读取 SaveProfile 写出的规模提示，返回建议预热的对象数量和对象的平均大小（字节）。
+// 它不会修改池，调用方可以据此预热，例如：
+//
+//	if count, _, err := p.LoadProfile(f); err == nil {
+//		p.WarmUp(count)
+//	}
+//
+// 平均大小可以用来决定 newFunc 预分配的容量。r 中的内容无法解析或格式版本不受支持时返回错误。
+func (p *Pool[T]) LoadProfile(r io.Reader) (count, avgSize int, err error) {
+	var prof poolProfile
+	if err := json.NewDecoder(r).Decode(&prof); err != nil {
+		return 0, 0, err
+	}
+	if prof.Version != profileVersion {
+		return 0, 0, errProfileVersion
+	}
+	if prof.Count < 0 {
+		prof.Count = 0
+	}
+	if prof.AvgSize < 0 {
+		prof.AvgSize = 0
+	}
+	return prof.Count, prof.AvgSize, nil
+}
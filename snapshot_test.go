@@ -0,0 +1,51 @@
+package gpool
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestPool_Profile 测试保存的规模提示可以被读回，并用于预热重启后的池。
+func TestPool_Profile(t *testing.T) {
+	newPool := func() *Pool[*bytes.Buffer] {
+		return New(func() *bytes.Buffer {
+			return bytes.NewBuffer(make([]byte, 0, 256))
+		}, WithDeterministic[*bytes.Buffer](), WithMeasure(func(b *bytes.Buffer) int {
+			return b.Cap()
+		}))
+	}
+
+	p := newPool()
+	p.PutAll(p.GetAll(5)) // 借出数量最高为 5
+	p.Get()
+	var saved bytes.Buffer
+	if err := p.SaveProfile(&saved); err != nil {
+		t.Fatalf("SaveProfile 返回了错误: %v", err)
+	}
+	p.Close()
+
+	// 模拟进程重启。
+	q := newPool()
+	count, avgSize, err := q.LoadProfile(&saved)
+	if err != nil {
+		t.Fatalf("LoadProfile 返回了错误: %v", err)
+	}
+	if count != 5 || avgSize != 256 {
+		t.Errorf("期望规模提示为 5 个对象、平均 256 字节, 得到 %d 和 %d", count, avgSize)
+	}
+	q.WarmUp(count)
+	if s := q.Stats(); s.Idle != 5 || s.Misses != 0 {
+		t.Errorf("按规模提示预热后应该有 5 个闲置对象: %+v", s)
+	}
+}
+
+// TestPool_LoadProfile_Invalid 测试无法解析或版本不受支持的内容返回错误。
+func TestPool_LoadProfile_Invalid(t *testing.T) {
+	p := New(func() int { return 0 })
+	for _, in := range []string{"not json", `{"version":99,"count":3}`} {
+		if count, _, err := p.LoadProfile(strings.NewReader(in)); err == nil || count != 0 {
+			t.Errorf("输入 %q 应该返回错误, 得到 %d, %v", in, count, err)
+		}
+	}
+}
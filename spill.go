@@ -0,0 +1,123 @@
+package gpool
+
+import (
+	"sync"
+	"unsafe"
+)
+
+// WithSpill 让池中容量不小于 threshold 字节的闲置缓冲区改由 dir 目录下的临时文件
+// 通过内存映射（mmap）承载，而不是占用堆内存。dir 为空时使用 os.TempDir()。
+//
+// 这类缓冲区在 Put 时被复制到映射内存中，原来的堆内存交由 GC 回收；
+// 之后 Get 返回的就是映射内存本身，内容与长度保持不变，页面在访问时由内核按需调入。
+// 映射建立后临时文件会立即被删除，磁盘空间在映射被解除时回收：
+// 缓冲区被丢弃、Clear 或 Close 时都会解除映射。
+//
+// 映射内存不受 GC 管理，因此从池中取出的缓冲区必须通过 Put 归还，
+// 且在被丢弃后不能再使用。放回的可以是映射内存的一部分（例如从中间开始的子切片），
+// 它的内容会被移到映射的开头；但不能用 append 等操作使缓冲区增长到超出它的容量之后再放回：
+// 底层数组被重新分配后池无法再找到原来的映射，它占用的地址空间和磁盘空间直到进程退出才会被释放。由于 sync.Pool 会静默丢弃对象，
+// WithSpill 要求池自己持有闲置对象，未指定其他后端时会改用确定性后端。
+//
+// 只在支持 mmap 的类 Unix 系统上生效，其他系统上该选项不起作用。
+// 创建映射失败时缓冲区会照常保存在堆内存中。
+func WithSpill(threshold int, dir string) Option[[]byte] {
+	return newSpiller(threshold, dir).option()
+}
+
+// spiller 管理溢出到临时文件的缓冲区的内存映射。
+type spiller struct {
+	threshold int
+	dir       string
+
+	mu   sync.Mutex
+	maps map[*byte][]byte // 以映射首字节的地址为键，值为完整的映射区域
+}
+
+func newSpiller(threshold int, dir string) *spiller {
+	return &spiller{
+		threshold: threshold,
+		dir:       dir,
+		maps:      make(map[*byte][]byte),
+	}
+}
+
+// option 返回将 s 接入池的 Option。
+func (s *spiller) option() Option[[]byte] {
+	return func(c *config[[]byte]) {
+		if !spillSupported {
+			return
+		}
+		c.beforeStore = s.spill
+		c.release = s.release
+		c.needStore = true
+	}
+}
+
+// spillKey 返回缓冲区底层数组首字节的地址。
+func spillKey(buf []byte) *byte {
+	return &buf[:cap(buf)][0]
+}
+
+// spill 在缓冲区足够大时将其内容复制到新建的映射内存中，并返回指向映射内存的缓冲区。
+func (s *spiller) spill(buf []byte) []byte {
+	if cap(buf) == 0 {
+		return buf
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// 先查找映射，再比较阈值：映射的子切片的容量可能小于阈值。
+	if m, ok := s.find(buf); ok {
+		if &m[0] == spillKey(buf) {
+			// 已经是映射内存，无需再次溢出。
+			return buf
+		}
+		// 从映射中间开始的子切片：把内容移到映射的开头，使之后仍然能以首字节找到这个映射。
+		return m[:copy(m, buf)]
+	}
+	if cap(buf) < s.threshold {
+		return buf
+	}
+	m, err := mmapTemp(s.dir, cap(buf))
+	if err != nil {
+		return buf
+	}
+	s.maps[&m[0]] = m
+	return m[:copy(m, buf)]
+}
+
+// release 解除缓冲区的内存映射，对普通的堆内存缓冲区不做任何事。
+func (s *spiller) release(buf []byte) {
+	if cap(buf) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if m, ok := s.find(buf); ok {
+		delete(s.maps, &m[0])
+		_ = munmap(m)
+	}
+}
+
+// find 返回包含 buf 的映射区域，buf 不在任何映射中时返回 false，调用方必须持有 s.mu。
+// buf 可能只是映射的一部分，因此在首字节找不到时按地址范围查找。
+func (s *spiller) find(buf []byte) ([]byte, bool) {
+	key := spillKey(buf)
+	if m, ok := s.maps[key]; ok {
+		return m, true
+	}
+	addr := uintptr(unsafe.Pointer(key))
+	for first, m := range s.maps {
+		if start := uintptr(unsafe.Pointer(first)); start <= addr && addr < start+uintptr(len(m)) {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
+// mapped 返回当前仍然存在的映射数量。
+func (s *spiller) mapped() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.maps)
+}
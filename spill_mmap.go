@@ -0,0 +1,30 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package gpool
+
+import (
+	"os"
+	"syscall"
+)
+
+const spillSupported = true
+
+// mmapTemp 在 dir 下创建一个大小为 size 的临时文件并将其映射到内存中。
+// 映射建立后文件会被立即删除，磁盘空间在映射被解除时由内核回收。
+func mmapTemp(dir string, size int) ([]byte, error) {
+	f, err := os.CreateTemp(dir, "gpool-spill-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if err := f.Truncate(int64(size)); err != nil {
+		return nil, err
+	}
+	return syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+}
+
+func munmap(b []byte) error {
+	return syscall.Munmap(b)
+}
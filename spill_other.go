@@ -0,0 +1,15 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package gpool
+
+import "errors"
+
+const spillSupported = false
+
+func mmapTemp(dir string, size int) ([]byte, error) {
+	return nil, errors.New("gpool: spill is not supported on this platform")
+}
+
+func munmap(b []byte) error {
+	return nil
+}
@@ -0,0 +1,125 @@
+package gpool
+
+import (
+	"os"
+	"testing"
+)
+
+// newSpillPool 创建一个溢出阈值为 4096 字节的 []byte 池，临时文件放在测试的临时目录中。
+func newSpillPool(t *testing.T) (*Pool[[]byte], *spiller, string) {
+	t.Helper()
+	if !spillSupported {
+		t.Skip("当前平台不支持溢出到磁盘")
+	}
+	dir := t.TempDir()
+	s := newSpiller(4096, dir)
+	p := New(func() []byte {
+		return make([]byte, 0, 8192)
+	}, s.option())
+	return p, s, dir
+}
+
+// TestPool_Spill_RoundTrip 测试大缓冲区溢出到映射内存后，内容在 Put/Get 之间保持不变。
+func TestPool_Spill_RoundTrip(t *testing.T) {
+	p, s, dir := newSpillPool(t)
+
+	buf := append(p.Get(), "hello, spill"...)
+	p.Put(buf)
+	if n := s.mapped(); n != 1 {
+		t.Fatalf("超过阈值的缓冲区应该被溢出, 期望 1 个映射, 实际 %d 个", n)
+	}
+	// 映射建立后临时文件应该已被删除。
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("映射建立后临时文件应该被立即删除, 但目录中还有 %d 个文件", len(entries))
+	}
+
+	got := p.Get()
+	if string(got) != "hello, spill" || cap(got) != 8192 {
+		t.Fatalf("溢出的缓冲区应该保持内容和容量, 得到 %q, cap=%d", got, cap(got))
+	}
+	if &got[:1][0] == &buf[:1][0] {
+		t.Fatal("溢出后取回的应该是映射内存, 而不是原来的堆内存")
+	}
+
+	// 映射内存可以正常写入，再次放回时不会重复溢出。
+	got = append(got[:0], "reused"...)
+	p.Put(got)
+	if n := s.mapped(); n != 1 {
+		t.Fatalf("已溢出的缓冲区再次放回时不应该重复映射, 实际有 %d 个映射", n)
+	}
+	if again := p.Get(); string(again) != "reused" || &again[:1][0] != &got[:1][0] {
+		t.Fatalf("应该复用同一块映射内存, 得到 %q", again)
+	}
+}
+
+// TestPool_Spill_SubSlice 测试放回映射内存中间开始的子切片时，池仍然找到原来的映射：
+// 内容被移到映射的开头，不会建立新的映射，之后丢弃时映射也会被解除。
+func TestPool_Spill_SubSlice(t *testing.T) {
+	p, s, _ := newSpillPool(t)
+
+	p.Put(append(p.Get(), "header:payload"...))
+	mapped := p.Get()
+	p.Put(mapped[len("header:"):])
+	if n := s.mapped(); n != 1 {
+		t.Fatalf("放回子切片不应该建立新的映射, 实际有 %d 个映射", n)
+	}
+	got := p.Get()
+	if string(got) != "payload" || &got[:1][0] != &mapped[:1][0] || cap(got) != 8192 {
+		t.Fatalf("应该从映射的开头取回子切片的内容, 得到 %q, cap=%d", got, cap(got))
+	}
+
+	// 丢弃从中间开始的一段子切片时，整个映射被解除。
+	p.Put(got[2:4])
+	if err := p.Clear(); err != nil {
+		t.Fatalf("Clear 返回了错误: %v", err)
+	}
+	if n := s.mapped(); n != 0 {
+		t.Fatalf("Clear 后期望所有映射都被解除, 实际还有 %d 个", n)
+	}
+}
+
+// TestPool_Spill_SmallBuffer 测试小于阈值的缓冲区保存在堆内存中。
+func TestPool_Spill_SmallBuffer(t *testing.T) {
+	p, s, _ := newSpillPool(t)
+
+	small := make([]byte, 0, 1024)
+	p.Put(small)
+	if n := s.mapped(); n != 0 {
+		t.Fatalf("小于阈值的缓冲区不应该被溢出, 实际有 %d 个映射", n)
+	}
+	if got := p.Get(); cap(got) != 1024 {
+		t.Fatalf("应该取回原来的小缓冲区, 得到 cap=%d", cap(got))
+	}
+}
+
+// TestPool_Spill_ClearAndClose 测试 Clear 和 Close 会解除闲置缓冲区的映射。
+func TestPool_Spill_ClearAndClose(t *testing.T) {
+	p, s, _ := newSpillPool(t)
+
+	a, b := p.Get(), p.Get()
+	p.Put(a)
+	p.Put(b)
+	if n := s.mapped(); n != 2 {
+		t.Fatalf("期望 2 个映射, 实际 %d 个", n)
+	}
+	if err := p.Clear(); err != nil {
+		t.Fatalf("Clear 返回了错误: %v", err)
+	}
+	if n := s.mapped(); n != 0 {
+		t.Fatalf("Clear 之后所有映射都应该被解除, 实际还有 %d 个", n)
+	}
+
+	a, b = p.Get(), p.Get()
+	p.Put(a)
+	if err := p.Close(); err != nil {
+		t.Fatalf("Close 返回了错误: %v", err)
+	}
+	if n := s.mapped(); n != 0 {
+		t.Fatalf("Close 之后闲置缓冲区的映射都应该被解除, 实际还有 %d 个", n)
+	}
+	// 放回已关闭的池的缓冲区会被直接丢弃，不会再建立映射。
+	p.Put(b)
+	if n := s.mapped(); n != 0 {
+		t.Fatalf("放回已关闭的池的缓冲区不应该被溢出, 实际有 %d 个映射", n)
+	}
+}
@@ -0,0 +1,54 @@
+package gpool
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// staged 是通过 StagePut 暂存、尚未决定去向的对象。
+type staged[T any] struct {
+	p    *Pool[T]
+	x    T
+	done int32 // 是否已经 commit 或 abort，使用原子操作访问
+}
+
+// StagePut 暂存一个准备放回池中的对象，由调用方根据后续操作的结果决定它的去向：
+// commit 将对象放回池中（与 Put 完全相同，包括自动重置），
+// abort 则不放回，而是以 DiscardAborted 为原因丢弃它（实现了 io.Closer 的对象会被关闭）。
+// 两种情况下对象都不再算作借出，有界池的额度也会被归还。
+//
+//	commit, abort := p.StagePut(conn)
+//	if err := finish(); err != nil {
+//		abort()
+//		return err
+//	}
+//	commit()
+//
+// commit 和 abort 必须且只能调用其中一个，之后的调用都会被忽略。
+// 启用 WithDebug 后，如果暂存的对象既没有 commit 也没有 abort，池会在它被 GC 回收时通过 Logger 报告。
+func (p *Pool[T]) StagePut(x T) (commit func(), abort func()) {
+	s := &staged[T]{p: p, x: x}
+	if p.cfg.debug {
+		runtime.SetFinalizer(s, func(s *staged[T]) {
+			if atomic.LoadInt32(&s.done) == 0 {
+				s.p.logger().Printf("gpool: object of type %s staged by StagePut was neither committed nor aborted", typeOf[T]())
+			}
+		})
+	}
+	commit = func() {
+		if s.resolve() {
+			p.Put(s.x)
+		}
+	}
+	abort = func() {
+		if s.resolve() {
+			p.discardReturned(s.x, DiscardAborted)
+		}
+	}
+	return commit, abort
+}
+
+// resolve 将暂存的对象标记为已决定去向，只有第一次调用返回 true。
+func (s *staged[T]) resolve() bool {
+	return atomic.CompareAndSwapInt32(&s.done, 0, 1)
+}
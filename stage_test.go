@@ -0,0 +1,77 @@
+package gpool
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPool_StagePut 测试 commit 将对象放回池中，abort 以 DiscardAborted 丢弃并关闭对象。
+func TestPool_StagePut(t *testing.T) {
+	t.Run("Commit", func(t *testing.T) {
+		p := New(func() *closerObject {
+			return &closerObject{}
+		}, WithDeterministic[*closerObject](), WithMax[*closerObject](1))
+
+		x := p.Get()
+		commit, abort := p.StagePut(x)
+		commit()
+		abort() // 已经 commit，之后的调用被忽略
+
+		if s := p.Stats(); s.Idle != 1 || s.Outstanding != 0 || s.Discards != 0 {
+			t.Fatalf("期望对象被放回池中, 得到 %+v", s)
+		}
+		if x.closed != 0 {
+			t.Error("commit 的对象不应被关闭")
+		}
+		if p.Get() != x {
+			t.Error("期望取回 commit 的对象")
+		}
+	})
+
+	t.Run("Abort", func(t *testing.T) {
+		p := New(func() *closerObject {
+			return &closerObject{}
+		}, WithDeterministic[*closerObject](), WithMax[*closerObject](1))
+
+		x := p.Get()
+		commit, abort := p.StagePut(x)
+		abort()
+		commit() // 已经 abort，之后的调用被忽略
+
+		s := p.Stats()
+		if s.Idle != 0 || s.Outstanding != 0 || s.DiscardsByReason[DiscardAborted] != 1 {
+			t.Fatalf("期望对象以 %q 被丢弃, 得到 %+v", DiscardAborted, s)
+		}
+		if x.closed != 1 {
+			t.Errorf("abort 的对象应该被关闭 1 次, 实际 %d 次", x.closed)
+		}
+		// 有界池的额度已被归还，Get 不会阻塞。
+		if _, err := p.TryGetAll(1); err != nil {
+			t.Fatalf("abort 后应归还额度, 得到 %v", err)
+		}
+	})
+}
+
+// TestPool_StagePut_Unresolved 测试调试模式下既没有 commit 也没有 abort 的暂存对象会被报告。
+func TestPool_StagePut_Unresolved(t *testing.T) {
+	log := new(recordLogger)
+	p := New(func() *closerObject {
+		return &closerObject{}
+	}, WithDebug[*closerObject](), WithLogger[*closerObject](log))
+
+	func() {
+		p.StagePut(p.Get())
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(log.Lines()) == 0 && time.Now().Before(deadline) {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+	lines := log.Lines()
+	if len(lines) != 1 || !strings.Contains(lines[0], "neither committed nor aborted") {
+		t.Fatalf("期望报告未决定去向的暂存对象, 得到 %q", lines)
+	}
+}
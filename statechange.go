@@ -0,0 +1,118 @@
+package gpool
+
+import "sync/atomic"
+
+// WithStateChange 设置一个在池变“冷”或变“热”时调用的回调：闲置对象被取空时以 warm 为 false 调用 fn，
+// 之后重新有对象闲置时以 warm 为 true 调用 fn，可以让上游系统及时对池的压力作出反应，例如触发扩容。
+// 新建的池是冷的，第一个对象被存入时会触发一次 warm 为 true 的回调。
+//
+// 每次状态变化只会通知一次；闲置数量在零以上的波动不会触发回调。fn 在池不持有任何锁时被调用，
+// 可以安全地回调池的方法。并发使用时，状态可能在 fn 运行期间再次变化，fn 收到的是变化发生那一刻的状态。
+// 由于 sync.Pool 的闲置对象由 GC 管理，未指定其他后端时会改用确定性后端；弱引用后端中被 GC 回收的对象不会触发回调。
+func WithStateChange[T any](fn func(warm bool)) Option[T] {
+	return func(c *config[T]) {
+		c.stateChange = fn
+		c.needStore = true
+	}
+}
+
+// watched 统计它包装的存储中闲置对象的数量，在数量变为零或从零变为正数时调用 notify。
+type watched[T any] struct {
+	inner  store[T]
+	notify func(warm bool)
+	n      int64 // 闲置对象数量，使用原子操作访问
+	warm   int32 // 最近一次通知的状态，1 表示热，使用原子操作访问
+}
+
+func newWatched[T any](inner store[T], notify func(warm bool)) *watched[T] {
+	return &watched[T]{inner: inner, notify: notify}
+}
+
+// add 把闲置数量增加 delta，并在状态变化时通知。它总是在 inner 的操作返回之后调用，此时不持有 inner 的锁。
+func (w *watched[T]) add(delta int64) {
+	if delta == 0 {
+		return
+	}
+	w.changed(atomic.AddInt64(&w.n, delta) > 0)
+}
+
+// changed 在 warm 与最近一次通知的状态不同时通知一次。
+func (w *watched[T]) changed(warm bool) {
+	var from, to int32 = 1, 0
+	if warm {
+		from, to = 0, 1
+	}
+	if atomic.CompareAndSwapInt32(&w.warm, from, to) {
+		w.notify(warm)
+	}
+}
+
+func (w *watched[T]) get() (T, bool) {
+	x, ok := w.inner.get()
+	if ok {
+		w.add(-1)
+	}
+	return x, ok
+}
+
+func (w *watched[T]) put(x T) bool {
+	if !w.inner.put(x) {
+		return false
+	}
+	w.add(1)
+	return true
+}
+
+func (w *watched[T]) len() int {
+	return w.inner.len()
+}
+
+func (w *watched[T]) drain() []T {
+	items := w.inner.drain()
+	atomic.StoreInt64(&w.n, 0)
+	w.changed(false)
+	return items
+}
+
+func (w *watched[T]) each(fn func(T)) {
+	w.inner.each(fn)
+}
+
+func (w *watched[T]) filter(drop func(T) bool) []T {
+	items := w.inner.filter(drop)
+	w.add(-int64(len(items)))
+	return items
+}
+
+func (w *watched[T]) peek(fn func(T)) bool {
+	s, ok := w.inner.(peeker[T])
+	return ok && s.peek(fn)
+}
+
+func (w *watched[T]) take(x T) bool {
+	s, ok := w.inner.(taker[T])
+	if !ok || !s.take(x) {
+		return false
+	}
+	w.add(-1)
+	return true
+}
+
+func (w *watched[T]) swap(build func(n int) []T) []T {
+	s, ok := w.inner.(swapper[T])
+	if !ok {
+		return nil
+	}
+	var built int
+	old := s.swap(func(n int) []T {
+		xs := build(n)
+		built = len(xs)
+		return xs
+	})
+	w.add(int64(built - len(old)))
+	return old
+}
+
+func (w *watched[T]) unwrap() store[T] {
+	return w.inner
+}
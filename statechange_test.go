@@ -0,0 +1,44 @@
+package gpool
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// TestPool_StateChange 测试闲置数量降到零和从零回升时各通知一次，零以上的波动不会触发回调。
+func TestPool_StateChange(t *testing.T) {
+	var p *Pool[*bytes.Buffer]
+	var changes []bool
+	p = New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithMaxIdle[*bytes.Buffer](8), WithStateChange[*bytes.Buffer](func(warm bool) {
+		changes = append(changes, warm)
+		p.Stats() // 回调中可以安全地使用池
+	}))
+
+	p.WarmUp(2)
+	a := p.Get()
+	p.Put(a)
+	if want := []bool{true}; !reflect.DeepEqual(changes, want) {
+		t.Fatalf("变热后闲置数量的波动不应该再通知, 期望 %v, 得到 %v", want, changes)
+	}
+
+	xs := p.GetAll(2)
+	if want := []bool{true, false}; !reflect.DeepEqual(changes, want) {
+		t.Fatalf("闲置对象被取空时应该通知变冷, 期望 %v, 得到 %v", want, changes)
+	}
+	p.Get() // 池已经是冷的，新建对象不会再次通知
+	p.PutAll(xs)
+	if want := []bool{true, false, true}; !reflect.DeepEqual(changes, want) {
+		t.Fatalf("重新有对象闲置时应该通知变热, 期望 %v, 得到 %v", want, changes)
+	}
+
+	p.Clear()
+	if want := []bool{true, false, true, false}; !reflect.DeepEqual(changes, want) {
+		t.Fatalf("Clear 之后应该通知变冷, 期望 %v, 得到 %v", want, changes)
+	}
+	if c := p.Config(); !c.StateChange || c.MaxIdle != 8 || c.Backend != BackendDeterministic {
+		t.Errorf("Config 不符合预期: %+v", c)
+	}
+}
@@ -0,0 +1,321 @@
+package gpool
+
+import (
+	"math/bits"
+	"math/rand"
+	"reflect"
+	"runtime"
+	"sync/atomic"
+)
+
+// Stats 是池的统计信息快照。
+type Stats struct {
+	// Gets 是从池中获取的对象总数。
+	Gets int64
+	// Hits 是复用闲置对象的次数，即 Gets - Misses。
+	Hits int64
+	// Misses 是调用 newFunc 创建新对象的次数。
+	Misses int64
+	// Puts 是放回池中的对象总数，包括随后被丢弃的对象。
+	Puts int64
+	// Discards 是被池丢弃的对象总数。
+	Discards int64
+	// DiscardsByReason 按丢弃原因（Discard* 常量）统计被丢弃的对象数量，只包含非零项。
+	DiscardsByReason map[string]int64
+	// Detached 是通过 Detach 分离、不再归还池的对象总数。
+	Detached int64
+	// Outstanding 是当前借出（已 Get 但尚未 Put 或 Detach）的对象数量，即 Gets - Puts - Detached。
+	// 如果放回了不是从该池取出的对象，它可能为负数。
+	Outstanding int64
+	// Idle 是当前闲置在池中的对象数量。
+	// sync.Pool 后端中的对象由 GC 管理，无法统计，始终为 0。
+	Idle int64
+}
+
+// 以下常量是 counters 中各个操作计数器的下标。
+const (
+	statGets = iota
+	statMisses
+	statPuts
+	statDetached
+	numStats
+)
+
+// peakSampleEvery 表示启用 WithShardedStats 时，每个分片每多少次 Get 重新计算一次借出数量的最高值。
+const peakSampleEvery = 16
+
+// counters 保存池的统计计数器，所有字段都通过原子操作访问。
+// 它总是单独分配，以保证 64 位字段在 32 位平台上也满足原子操作的对齐要求。
+//
+// 默认情况下操作计数器保存在 shared 中；启用 WithShardedStats 后改为分散在 shards 中，
+// 每次操作只更新随机选出的一个分片，读取时再把所有分片加起来。
+type counters struct {
+	shared   [numStats]int64
+	shards   []statShard // 为 nil 时使用 shared
+	mask     uint32      // len(shards) - 1，分片数总是 2 的幂
+	peak     int64       // 借出数量的历史最高值
+	built    int64       // 设置了 WithMeasure 时，构造函数创建的对象数量
+	builtSz  int64       // 设置了 WithMeasure 时，构造函数创建的对象的大小之和
+	discards [len(discardReasons)]int64
+}
+
+// statShard 是 counters 的一个分片，填充到独立的缓存行以避免伪共享。
+type statShard struct {
+	n [numStats]int64
+	_ [64 - numStats*8]byte
+}
+
+// newCounters 创建一组统计计数器，sharded 为 true 时把操作计数器分散到与 GOMAXPROCS 相当数量的分片中。
+func newCounters(sharded bool) *counters {
+	c := new(counters)
+	if sharded {
+		n := 1 << bits.Len(uint(runtime.GOMAXPROCS(0)-1))
+		c.shards = make([]statShard, n)
+		c.mask = uint32(n - 1)
+	}
+	return c
+}
+
+// add 把计数器 i 增加 n，并返回所在分片（未分片时为整个计数器）的新值。
+func (c *counters) add(i int, n int64) int64 {
+	if c.shards == nil {
+		return atomic.AddInt64(&c.shared[i], n)
+	}
+	// 随机选择分片：rand 的全局函数不需要加锁，开销远低于一次 Get 或 Put。
+	return atomic.AddInt64(&c.shards[rand.Uint32()&c.mask].n[i], n)
+}
+
+// load 返回计数器 i 的当前值。
+func (c *counters) load(i int) int64 {
+	if c.shards == nil {
+		return atomic.LoadInt64(&c.shared[i])
+	}
+	var n int64
+	for j := range c.shards {
+		n += atomic.LoadInt64(&c.shards[j].n[i])
+	}
+	return n
+}
+
+// outstanding 返回当前借出的对象数量。
+func (c *counters) outstanding() int64 {
+	return c.load(statGets) - c.load(statPuts) - c.load(statDetached)
+}
+
+// updatePeak 在 out 超过记录的最高值时更新它。
+func (c *counters) updatePeak(out int64) {
+	for {
+		peak := atomic.LoadInt64(&c.peak)
+		if out <= peak || atomic.CompareAndSwapInt64(&c.peak, peak, out) {
+			return
+		}
+	}
+}
+
+// addDiscard 为丢弃原因 reason 计数。
+func (c *counters) addDiscard(reason string) {
+	for i, r := range discardReasons {
+		if r == reason {
+			atomic.AddInt64(&c.discards[i], 1)
+			return
+		}
+	}
+}
+
+// countGets 记录 n 次 Get，并更新借出数量的最高值。
+func (p *Pool[T]) countGets(n int) {
+	c := p.stats
+	if c == nil {
+		return
+	}
+	gets := c.add(statGets, int64(n))
+	if c.shards == nil {
+		c.updatePeak(gets - c.load(statPuts) - c.load(statDetached))
+		return
+	}
+	// 把所有分片加起来的开销与分片数成正比，因此每个分片只每隔 peakSampleEvery 次 Get 计算一次。
+	if gets%peakSampleEvery < int64(n) {
+		c.updatePeak(c.outstanding())
+	}
+}
+
+// countPuts 记录 n 次 Put。
+func (p *Pool[T]) countPuts(n int) {
+	if p.stats != nil {
+		p.stats.add(statPuts, int64(n))
+	}
+}
+
+// Stats 返回池当前的统计信息快照。
+// 各计数器分别以原子方式读取，在并发使用时它们之间可能存在微小的不一致。
+// 通过 WithStats(false) 禁用统计的池总是返回零值。
+func (p *Pool[T]) Stats() Stats {
+	c := p.stats
+	if c == nil {
+		return Stats{}
+	}
+	s := Stats{
+		Gets:     c.load(statGets),
+		Misses:   c.load(statMisses),
+		Puts:     c.load(statPuts),
+		Detached: c.load(statDetached),
+	}
+	s.Hits = s.Gets - s.Misses
+	s.Outstanding = s.Gets - s.Puts - s.Detached
+	for i, r := range discardReasons {
+		if n := atomic.LoadInt64(&c.discards[i]); n != 0 {
+			if s.DiscardsByReason == nil {
+				s.DiscardsByReason = make(map[string]int64)
+			}
+			s.DiscardsByReason[r] = n
+			s.Discards += n
+		}
+	}
+	if p.store != nil {
+		s.Idle = int64(p.store.len())
+	}
+	return s
+}
+
+// Peak 返回自池创建（或上次 ResetPeak）以来观察到的借出数量的最高值，
+// 可以作为有界池 WithMax 上限的参考。
+// 最高值只在 Get 时更新；与 Put 并发时，记录的值可能略低于真实的瞬时最高值。
+// 启用 WithShardedStats 时最高值是采样得到的，可能低于真实值，但不会低于当前的借出数量。
+// 通过 WithStats(false) 禁用统计的池总是返回 0。
+func (p *Pool[T]) Peak() int64 {
+	c := p.stats
+	if c == nil {
+		return 0
+	}
+	if c.shards != nil {
+		c.updatePeak(c.outstanding())
+	}
+	return atomic.LoadInt64(&c.peak)
+}
+
+// ResetPeak 将借出数量的最高值重置为当前的借出数量，以便重新观察一个时间段内的峰值。
+func (p *Pool[T]) ResetPeak() {
+	c := p.stats
+	if c == nil {
+		return
+	}
+	atomic.StoreInt64(&c.peak, c.outstanding())
+}
+
+// EstimatedBytes 返回池中闲置对象占用字节数的估算值，即用 WithMeasure 设置的函数测得的大小之和，
+// 可以用来把进程的内存占用归因到各个池。未设置 WithMeasure 时返回 0。
+//
+// 对于自己持有闲置对象的后端，结果是当前所有闲置对象测量值的精确总和。
+// sync.Pool 后端的闲置对象无法枚举，结果是在 Put 和 Get 时维护的估算值：
+// GC 清空 sync.Pool 后它可能暂时偏高，直到下一次 Get 发现池中已没有闲置对象时被校正为 0。
+func (p *Pool[T]) EstimatedBytes() int64 {
+	if p.cfg.measure == nil {
+		return 0
+	}
+	if p.store == nil {
+		return atomic.LoadInt64(p.syncBytes)
+	}
+	var n int64
+	p.store.each(func(x T) {
+		n += int64(p.cfg.measure(x))
+	})
+	return n
+}
+
+// Savings 估算池化节省下来的内存分配：allocsAvoided 是复用闲置对象的次数（Stats 的 Hits），
+// 即不使用池时需要额外调用 newFunc 的次数；bytesAvoided 是这些调用本应分配的字节数，
+// 等于 allocsAvoided 乘以单个新对象的大小，可以把抽象的命中率换算成报告中具体的节省量。
+//
+// 设置了 WithMeasure 时，单个新对象的大小是用它测得的、构造函数创建的所有对象的平均大小；
+// 否则使用类型的浅层大小：指针类型取它指向的类型的大小，例如 *bytes.Buffer 取 bytes.Buffer 结构体本身的大小，
+// 不包括它引用的底层数组。对象在使用中长大的部分（例如扩容后的缓冲区）不计算在内，因此结果是一个保守的下限。
+// 通过 WithStats(false) 禁用统计的池总是返回 0。
+func (p *Pool[T]) Savings() (allocsAvoided, bytesAvoided int64) {
+	c := p.stats
+	if c == nil {
+		return 0, 0
+	}
+	allocsAvoided = c.load(statGets) - c.load(statMisses)
+	if allocsAvoided <= 0 {
+		return 0, 0
+	}
+	if p.cfg.measure == nil {
+		t := typeOf[T]()
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		return allocsAvoided, allocsAvoided * int64(t.Size())
+	}
+	if n := atomic.LoadInt64(&c.built); n > 0 {
+		bytesAvoided = allocsAvoided * atomic.LoadInt64(&c.builtSz) / n
+	}
+	return allocsAvoided, bytesAvoided
+}
+
+// countBuilt 在设置了 WithMeasure 时记录构造函数创建的对象 x 的大小，供 Savings 计算新对象的平均大小。
+func (p *Pool[T]) countBuilt(x T) {
+	if c := p.stats; c != nil && p.cfg.measure != nil {
+		atomic.AddInt64(&c.builtSz, int64(p.cfg.measure(x)))
+		atomic.AddInt64(&c.built, 1)
+	}
+}
+
+// Delta 返回从快照 prev 到当前统计信息之间的增量，便于监控面板计算每个采样周期内的速率，
+// prev 通常是上一次调用 Stats 的结果。
+//
+// Gets、Hits、Misses、Puts、Detached、Discards 和 DiscardsByReason 是单调递增的计数器，结果是两次快照之差；
+// Outstanding 和 Idle 是瞬时值，结果直接取当前值。
+// 计数器是 int64，实际上不会溢出回绕；如果某个计数器比 prev 中的值小（例如期间调用了 ResetPool），
+// 就认为它从 0 重新开始计数，结果取当前值，而不会得到负的增量。
+func (p *Pool[T]) Delta(prev Stats) Stats {
+	return p.Stats().delta(prev)
+}
+
+// delta 返回从 prev 到 s 的增量，规则见 Delta。
+func (s Stats) delta(prev Stats) Stats {
+	d := Stats{
+		Gets:        counterDelta(s.Gets, prev.Gets),
+		Hits:        counterDelta(s.Hits, prev.Hits),
+		Misses:      counterDelta(s.Misses, prev.Misses),
+		Puts:        counterDelta(s.Puts, prev.Puts),
+		Detached:    counterDelta(s.Detached, prev.Detached),
+		Outstanding: s.Outstanding,
+		Idle:        s.Idle,
+	}
+	for r, n := range s.DiscardsByReason {
+		if n = counterDelta(n, prev.DiscardsByReason[r]); n != 0 {
+			if d.DiscardsByReason == nil {
+				d.DiscardsByReason = make(map[string]int64)
+			}
+			d.DiscardsByReason[r] = n
+			d.Discards += n
+		}
+	}
+	return d
+}
+
+// counterDelta 返回单调计数器从 prev 到 cur 的增量，计数器被重置时返回 cur。
+func counterDelta(cur, prev int64) int64 {
+	if cur < prev {
+		return cur
+	}
+	return cur - prev
+}
+
+// add 将 o 累加到 s 上。
+func (s *Stats) add(o Stats) {
+	s.Gets += o.Gets
+	s.Hits += o.Hits
+	s.Misses += o.Misses
+	s.Puts += o.Puts
+	s.Detached += o.Detached
+	s.Discards += o.Discards
+	s.Outstanding += o.Outstanding
+	s.Idle += o.Idle
+	for r, n := range o.DiscardsByReason {
+		if s.DiscardsByReason == nil {
+			s.DiscardsByReason = make(map[string]int64)
+		}
+		s.DiscardsByReason[r] += n
+	}
+}
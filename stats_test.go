@@ -0,0 +1,374 @@
+package gpool
+
+import (
+	"bytes"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+// TestPool_Stats 测试统计信息正确记录命中、未命中、放回、丢弃和借出数量。
+func TestPool_Stats(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer]())
+
+	a, b := p.Get(), p.Get() // 2 次未命中
+	p.Put(a)
+	p.Get() // 1 次命中
+	p.Put(nil)
+
+	s := p.Stats()
+	if s.Gets != 3 || s.Misses != 2 || s.Hits != 1 {
+		t.Errorf("期望 Gets=3 Misses=2 Hits=1, 得到 %+v", s)
+	}
+	if s.Puts != 2 || s.Outstanding != 1 {
+		t.Errorf("期望 Puts=2 Outstanding=1, 得到 %+v", s)
+	}
+	if s.Discards != 1 || s.DiscardsByReason[DiscardNil] != 1 {
+		t.Errorf("期望以 %q 丢弃 1 个对象, 得到 %+v", DiscardNil, s)
+	}
+
+	p.Put(b)
+	if s := p.Stats(); s.Idle != 1 || s.Outstanding != 0 {
+		t.Errorf("期望 Idle=1 Outstanding=0, 得到 %+v", s)
+	}
+}
+
+// TestPool_Stats_SyncPool 测试 sync.Pool 后端通过 New 记录未命中。
+func TestPool_Stats_SyncPool(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	})
+	p.Get()
+
+	if s := p.Stats(); s.Gets != 1 || s.Misses != 1 || s.Idle != 0 {
+		t.Errorf("期望 Gets=1 Misses=1 Idle=0, 得到 %+v", s)
+	}
+}
+
+// TestPool_ResetPool_Stats 测试 ResetPool 会将统计信息和存储全部重置。
+func TestPool_ResetPool_Stats(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer](), WithMax[*bytes.Buffer](2))
+
+	a, _ := p.Get(), p.Get()
+	p.Put(a)
+	p.Put(nil)
+	p.ResetPool()
+
+	s := p.Stats()
+	if s.Gets != 0 || s.Misses != 0 || s.Puts != 0 || s.Outstanding != 0 || s.Idle != 0 {
+		t.Fatalf("ResetPool 之后统计信息应该全部清零, 得到 %+v", s)
+	}
+	// 清理闲置对象本身属于重置前的操作，不应出现在重置后的统计中。
+	if s.Discards != 0 || s.DiscardsByReason != nil {
+		t.Fatalf("ResetPool 之后丢弃计数应该清零, 得到 %+v", s)
+	}
+	// 借出额度也应该被重置：重置前未放回的对象不再占用额度。
+	if _, err := p.TryGetAll(2); err != nil {
+		t.Fatalf("ResetPool 之后应该可以借出全部额度, 得到 %v", err)
+	}
+}
+
+// TestPool_WithStatsDisabled 测试禁用统计后 Stats 返回零值。
+func TestPool_WithStatsDisabled(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer](), WithStats[*bytes.Buffer](false))
+
+	buf := p.Get()
+	p.Put(buf)
+	p.Put(nil)
+
+	if s := p.Stats(); s.Gets != 0 || s.Puts != 0 || s.Misses != 0 || s.Discards != 0 || s.Idle != 0 {
+		t.Fatalf("禁用统计后 Stats 应该返回零值, 得到 %+v", s)
+	}
+}
+
+// BenchmarkPool_Stats 对比启用和禁用统计时 Get/Put 热点路径的开销。
+func BenchmarkPool_Stats(b *testing.B) {
+	for _, bc := range []struct {
+		name    string
+		enabled bool
+	}{
+		{"Enabled", true},
+		{"Disabled", false},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			p := New(func() *bytes.Buffer {
+				return new(bytes.Buffer)
+			}, WithStats[*bytes.Buffer](bc.enabled))
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					p.Put(p.Get())
+				}
+			})
+		})
+	}
+}
+
+// TestPool_ShardedStats 测试并发使用时，各分片计数器加起来与实际的操作次数完全一致。
+func TestPool_ShardedStats(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(8)) // 保证计数器有多个分片
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer](), WithShardedStats[*bytes.Buffer]())
+	if len(p.stats.shards) != 8 {
+		t.Fatalf("期望 8 个分片, 得到 %d", len(p.stats.shards))
+	}
+
+	const workers, rounds = 8, 1000
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < rounds; j++ {
+				p.Put(p.Get())
+			}
+			p.Detach(p.Get())
+			p.Get() // 保持借出
+		}()
+	}
+	wg.Wait()
+
+	s := p.Stats()
+	if s.Gets != workers*(rounds+2) || s.Puts != workers*rounds || s.Detached != workers || s.Outstanding != workers {
+		t.Fatalf("分片计数器之和与实际操作次数不符: %+v", s)
+	}
+	if s.Hits+s.Misses != s.Gets || s.Misses != s.Idle+2*workers {
+		t.Fatalf("命中和未命中次数不符: %+v", s)
+	}
+	if peak := p.Peak(); peak < workers || peak > 2*workers {
+		t.Errorf("Peak 应该在 %d 和 %d 之间, 得到 %d", workers, 2*workers, peak)
+	}
+	if !p.Config().ShardedStats {
+		t.Error("Config 应该报告启用了分片计数器")
+	}
+}
+
+// BenchmarkPool_ShardedStats 对比共享的原子计数器和分片计数器在并发 Get/Put 下的开销，
+// 差异在 GOMAXPROCS 较大时才明显，例如使用 go test -bench ShardedStats -cpu 1,8,32 运行。
+func BenchmarkPool_ShardedStats(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		opts []Option[*bytes.Buffer]
+	}{
+		{"Shared", nil},
+		{"Sharded", []Option[*bytes.Buffer]{WithShardedStats[*bytes.Buffer]()}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			p := New(func() *bytes.Buffer {
+				return new(bytes.Buffer)
+			}, bc.opts...)
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					p.Put(p.Get())
+				}
+			})
+		})
+	}
+}
+
+// TestPool_Peak 测试并发借出时 Peak 记录借出数量的最高值，ResetPeak 将其重置为当前借出数量。
+func TestPool_Peak(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer]())
+
+	const n = 16
+	var got, done sync.WaitGroup
+	release := make(chan struct{})
+	got.Add(n)
+	done.Add(n)
+	for i := 0; i < n; i++ {
+		go func() {
+			defer done.Done()
+			x := p.Get()
+			got.Done()
+			<-release
+			p.Put(x)
+		}()
+	}
+	got.Wait()
+	close(release)
+	done.Wait()
+
+	if peak := p.Peak(); peak != n {
+		t.Fatalf("期望 Peak 为 %d, 得到 %d", n, peak)
+	}
+
+	x := p.Get()
+	p.ResetPeak()
+	if peak := p.Peak(); peak != 1 {
+		t.Fatalf("ResetPeak 后期望 Peak 为当前借出数量 1, 得到 %d", peak)
+	}
+	p.Put(x)
+	p.GetAll(3)
+	if peak := p.Peak(); peak != 3 {
+		t.Fatalf("期望 Peak 为 3, 得到 %d", peak)
+	}
+}
+
+// TestPool_EstimatedBytes 测试确定性后端的 EstimatedBytes 等于闲置对象大小之和。
+func TestPool_EstimatedBytes(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer](), WithMeasure(func(b *bytes.Buffer) int {
+		return b.Cap()
+	}))
+
+	var want int64
+	bufs := p.GetAll(3)
+	for i, b := range bufs {
+		b.Grow(64 << i)
+		want += int64(b.Cap())
+	}
+	p.PutAll(bufs)
+	if got := p.EstimatedBytes(); got != want {
+		t.Fatalf("期望 EstimatedBytes 为 %d, 得到 %d", want, got)
+	}
+
+	b := p.Get()
+	want -= int64(b.Cap())
+	if got := p.EstimatedBytes(); got != want {
+		t.Fatalf("取出一个对象后期望 EstimatedBytes 为 %d, 得到 %d", want, got)
+	}
+
+	p.Clear()
+	if got := p.EstimatedBytes(); got != 0 {
+		t.Fatalf("Clear 后期望 EstimatedBytes 为 0, 得到 %d", got)
+	}
+}
+
+// TestPool_Savings 测试节省的分配次数等于命中次数，节省的字节数按新对象的平均大小或类型的大小计算。
+func TestPool_Savings(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return bytes.NewBuffer(make([]byte, 0, 100))
+	}, WithDeterministic[*bytes.Buffer](), WithMeasure(func(b *bytes.Buffer) int {
+		return b.Cap()
+	}))
+	for i := 0; i < 4; i++ {
+		b := p.Get()
+		b.Grow(1000) // 使用中长大的部分不计入节省量
+		p.Put(b)
+	}
+	if allocs, n := p.Savings(); allocs != 3 || n != 300 {
+		t.Errorf("期望节省 3 次分配、300 字节, 得到 %d 和 %d", allocs, n)
+	}
+
+	// 未设置 WithMeasure 时使用指针指向的类型的大小。
+	q := New(func() *[4]int64 { return new([4]int64) }, WithDeterministic[*[4]int64]())
+	q.Put(q.Get())
+	q.Put(q.Get())
+	q.Get()
+	if allocs, n := q.Savings(); allocs != 2 || n != 64 {
+		t.Errorf("期望节省 2 次分配、64 字节, 得到 %d 和 %d", allocs, n)
+	}
+
+	if allocs, n := New(func() int { return 0 }, WithStats[int](false)).Savings(); allocs != 0 || n != 0 {
+		t.Errorf("禁用统计的池应该返回 0, 得到 %d 和 %d", allocs, n)
+	}
+}
+
+// TestPool_EstimatedBytes_SyncPool 测试 sync.Pool 后端在 Put 和 Get 时维护估算值，且不会为负。
+func TestPool_EstimatedBytes_SyncPool(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithMeasure(func(b *bytes.Buffer) int {
+		return b.Cap()
+	}))
+
+	b := new(bytes.Buffer)
+	b.Grow(128)
+	p.Put(b)
+	if got := p.EstimatedBytes(); got != int64(b.Cap()) {
+		t.Fatalf("期望 EstimatedBytes 为 %d, 得到 %d", b.Cap(), got)
+	}
+
+	// sync.Pool 可能丢弃放入的对象（例如在 -race 模式下），此时取回的是新对象。
+	if p.Get() != b {
+		t.Skip("sync.Pool 丢弃了放入的对象")
+	}
+	p.Get() // 新创建的对象不会使估算值变为负数
+	if got := p.EstimatedBytes(); got != 0 {
+		t.Fatalf("取出所有对象后期望 EstimatedBytes 为 0, 得到 %d", got)
+	}
+}
+
+// TestPool_EstimatedBytes_GC 测试 GC 清空 sync.Pool 后，估算值在下一次 Get 时被校正，而不会永久偏高。
+func TestPool_EstimatedBytes_GC(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithMeasure(func(b *bytes.Buffer) int {
+		return b.Cap()
+	}))
+
+	for i := 0; i < 4; i++ {
+		b := new(bytes.Buffer)
+		b.Grow(256)
+		p.Put(b)
+	}
+	if got := p.EstimatedBytes(); got == 0 {
+		t.Fatal("放入对象后估算值不应为 0")
+	}
+
+	// 两次 GC 会清空 sync.Pool 的主缓存和 victim 缓存。
+	runtime.GC()
+	runtime.GC()
+	if b := p.Get(); b.Cap() != 0 {
+		t.Skip("GC 之后 sync.Pool 仍保留了对象")
+	}
+	if got := p.EstimatedBytes(); got != 0 {
+		t.Fatalf("GC 清空 sync.Pool 后期望估算值被校正为 0, 得到 %d", got)
+	}
+
+	// 校正之后估算值继续正常工作。
+	b := new(bytes.Buffer)
+	b.Grow(64)
+	p.Put(b)
+	if got := p.EstimatedBytes(); got != int64(b.Cap()) {
+		t.Fatalf("期望估算值为 %d, 得到 %d", b.Cap(), got)
+	}
+}
+
+// TestPool_Delta 测试 Delta 返回两次快照之间计数器的增量和当前的瞬时值。
+func TestPool_Delta(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer](), WithValidateOnPut(func(b *bytes.Buffer) bool {
+		return b.Cap() <= 8
+	}))
+
+	p.Put(p.Get())
+	prev := p.Stats()
+
+	held := p.Get() // 命中
+	p.Get()         // 未命中，一直借出
+	big := bytes.NewBuffer(make([]byte, 0, 64))
+	p.Put(big) // 未通过校验被丢弃
+	p.Put(held)
+
+	d := p.Delta(prev)
+	if d.Gets != 2 || d.Hits != 1 || d.Misses != 1 || d.Puts != 2 {
+		t.Errorf("计数器增量不正确: %+v", d)
+	}
+	if d.Discards != 1 || d.DiscardsByReason[DiscardInvalid] != 1 {
+		t.Errorf("丢弃增量不正确: %+v", d)
+	}
+	if cur := p.Stats(); d.Outstanding != cur.Outstanding || d.Idle != cur.Idle {
+		t.Errorf("Outstanding 和 Idle 应该是当前值 %d 和 %d, 得到 %d 和 %d", cur.Outstanding, cur.Idle, d.Outstanding, d.Idle)
+	}
+
+	// ResetPool 会清零计数器，之后的增量应该从 0 开始计算而不是负数。
+	prev = p.Stats()
+	p.ResetPool()
+	p.Put(p.Get())
+	if d := p.Delta(prev); d.Gets != 1 || d.Puts != 1 || d.Discards != 0 {
+		t.Errorf("计数器被重置后增量应该取当前值: %+v", d)
+	}
+}
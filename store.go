@@ -0,0 +1,452 @@
+package gpool
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// backend 表示池使用的存储后端。
+type backend int
+
+const (
+	// backendSyncPool 使用内嵌的 sync.Pool，这是默认后端。
+	backendSyncPool backend = iota
+	// backendDeterministic 使用互斥锁保护的 LIFO 栈，对象不会被 GC 回收。
+	backendDeterministic
+	// backendSharded 使用多个分片栈，减少高并发下的锁竞争。
+	backendSharded
+	// backendFixed 使用容量固定的栈，存满后拒绝继续存入对象。
+	backendFixed
+)
+
+// store 是 sync.Pool 之外的存储后端需要实现的接口。
+//
+// 实现必须保证 put 与随后取出同一对象的 get 之间存在 happens-before 关系（例如都在同一把互斥锁内访问对象），
+// Pool 对调用方的可见性保证依赖于此。内置的后端都通过互斥锁实现这一点。
+type store[T any] interface {
+	// get 取出一个闲置对象，没有闲置对象时返回 false。
+	get() (T, bool)
+	// put 存入一个对象，存储已满时返回 false。
+	put(x T) bool
+	// len 返回当前闲置对象的数量。
+	len() int
+	// drain 取出并返回所有闲置对象。
+	drain() []T
+	// each 对每个闲置对象调用 fn。调用期间可能持有存储的锁，fn 不得访问存储。
+	each(fn func(T))
+	// filter 取出并返回 drop 报告为 true 的闲置对象，其余对象留在原处并保持原来的顺序。
+	// 调用期间可能持有存储的锁，drop 不得访问存储。
+	filter(drop func(T) bool) []T
+}
+
+// peeker 是可以在不取出对象的情况下查看闲置对象的存储，Inspect 依赖它。
+type peeker[T any] interface {
+	// peek 在持有存储的锁时对一个闲置对象调用 fn，没有闲置对象时返回 false。
+	peek(fn func(T)) bool
+}
+
+// taker 是可以取出指定对象的存储，GetPreferred 依赖它。
+type taker[T any] interface {
+	// take 取出一个闲置的对象 x，x 不在存储中时返回 false。
+	take(x T) bool
+}
+
+// swapper 是可以原子地替换全部闲置对象的存储，RebuildIdle 依赖它。
+type swapper[T any] interface {
+	// swap 在持有存储的锁时用 build(n) 返回的对象替换当前的 n 个闲置对象，并返回被替换的对象。
+	// build 返回的对象可以少于 n 个。
+	swap(build func(n int) []T) []T
+}
+
+// compacter 是可以收缩底层数组的存储，Compact 依赖它。
+type compacter interface {
+	// compact 重新分配底层数组，使它的容量与闲置对象的数量相符。
+	// force 为 false 时只在数组明显过大（见 compactMinCap）时才重新分配。
+	compact(force bool)
+}
+
+// compactMinCap 是后台维护自动收缩的底层数组的最小容量：只有容量不小于它、
+// 并且闲置对象不足容量的四分之一时才会收缩，避免反复重新分配较小的数组。
+const compactMinCap = 64
+
+// shouldCompact 报告容量为 c、使用了 n 个槽位的底层数组是否应该被收缩。
+func shouldCompact(n, c int, force bool) bool {
+	if c == n {
+		return false
+	}
+	return force || (c >= compactMinCap && n < c/4)
+}
+
+// newStore 根据配置创建存储后端，默认的 sync.Pool 后端返回 nil。
+func newStore[T any](c *config[T]) store[T] {
+	s := newBaseStore(c)
+	if s == nil {
+		return nil
+	}
+	if c.affinity {
+		s = newAffine(s)
+	}
+	if max, ok := idleLimit(c); ok {
+		s = newCapped(s, max, c.weak)
+	}
+	if c.stateChange != nil {
+		s = newWatched(s, c.stateChange)
+	}
+	return s
+}
+
+// idleLimit 返回需要在最外层限制的闲置对象数量上限，不需要限制时返回 false。
+// 除了 WithMaxIdle 的上限之外，同时启用 WithGoroutineAffinity 和 WithCapacity 时，
+// 亲和缓存中的对象不在固定容量的栈中，也要像 WithMaxIdle 一样计入容量，使池保留的对象不超过 WithCapacity 的值。
+func idleLimit[T any](c *config[T]) (int, bool) {
+	max, ok := c.maxIdle, c.maxIdle > 0
+	if c.affinity && c.backend == backendFixed && c.customStore == nil && (!ok || c.capacity < max) {
+		max, ok = c.capacity, true
+		if max < 0 {
+			max = 0
+		}
+	}
+	return max, ok
+}
+
+// newBaseStore 根据配置的后端创建存储。
+func newBaseStore[T any](c *config[T]) store[T] {
+	if c.customStore != nil {
+		return c.customStore()
+	}
+	switch c.backend {
+	case backendDeterministic:
+		return &stack[T]{}
+	case backendSharded:
+		return newSharded[T](c.shards, c.cacheWarmth, c.shardFunc)
+	case backendFixed:
+		return newFixed[T](c.capacity)
+	}
+	if c.needStore {
+		return &stack[T]{}
+	}
+	return nil
+}
+
+// wrapper 是包装了另一个存储的存储，例如 WithGoroutineAffinity、WithMaxIdle 和 WithStateChange 使用的存储。
+type wrapper[T any] interface {
+	unwrap() store[T]
+}
+
+// baseStore 返回 s 最内层包装的后端存储，s 没有被包装时返回 s 本身。
+func baseStore[T any](s store[T]) store[T] {
+	for {
+		w, ok := s.(wrapper[T])
+		if !ok {
+			return s
+		}
+		s = w.unwrap()
+	}
+}
+
+// stack 是一个互斥锁保护的 LIFO 栈，最近放入的对象最先被取出。
+type stack[T any] struct {
+	mu    sync.Mutex
+	items []T
+}
+
+func (s *stack[T]) get() (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pop()
+}
+
+// pop 弹出栈顶对象，调用方必须持有 s.mu。
+func (s *stack[T]) pop() (T, bool) {
+	var zero T
+	n := len(s.items)
+	if n == 0 {
+		return zero, false
+	}
+	x := s.items[n-1]
+	// 清空槽位，避免底层数组继续引用已取出的对象。
+	s.items[n-1] = zero
+	s.items = s.items[:n-1]
+	return x, true
+}
+
+func (s *stack[T]) put(x T) bool {
+	s.mu.Lock()
+	s.items = append(s.items, x)
+	s.mu.Unlock()
+	return true
+}
+
+func (s *stack[T]) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.items)
+}
+
+func (s *stack[T]) drain() []T {
+	s.mu.Lock()
+	items := s.items
+	s.items = nil
+	s.mu.Unlock()
+	return items
+}
+
+// take 从栈顶开始查找并取出对象 x，其余对象保持原来的顺序。
+func (s *stack[T]) take(x T) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.items) - 1; i >= 0; i-- {
+		if any(s.items[i]) == any(x) {
+			n := len(s.items) - 1
+			copy(s.items[i:], s.items[i+1:])
+			var zero T
+			s.items[n] = zero
+			s.items = s.items[:n]
+			return true
+		}
+	}
+	return false
+}
+
+func (s *stack[T]) swap(build func(n int) []T) []T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old := s.items
+	s.items = build(len(old))
+	return old
+}
+
+// peek 对栈顶的对象，即下一次 get 会取出的对象调用 fn。
+func (s *stack[T]) peek(fn func(T)) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.items) == 0 {
+		return false
+	}
+	fn(s.items[len(s.items)-1])
+	return true
+}
+
+func (s *stack[T]) compact(force bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !shouldCompact(len(s.items), cap(s.items), force) {
+		return
+	}
+	if len(s.items) == 0 {
+		s.items = nil
+		return
+	}
+	items := make([]T, len(s.items))
+	copy(items, s.items)
+	s.items = items
+}
+
+func (s *stack[T]) each(fn func(T)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, x := range s.items {
+		fn(x)
+	}
+}
+
+func (s *stack[T]) filter(drop func(T) bool) []T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var dropped []T
+	kept := s.items[:0]
+	for _, x := range s.items {
+		if drop(x) {
+			dropped = append(dropped, x)
+		} else {
+			kept = append(kept, x)
+		}
+	}
+	// 清空空出的槽位，避免底层数组继续引用已取出的对象。
+	var zero T
+	for i := len(kept); i < len(s.items); i++ {
+		s.items[i] = zero
+	}
+	s.items = kept
+	return dropped
+}
+
+// fixed 是一个容量固定的 LIFO 栈，存满后拒绝继续存入对象。
+type fixed[T any] struct {
+	stack[T]
+	capacity int
+}
+
+func newFixed[T any](capacity int) *fixed[T] {
+	if capacity < 0 {
+		capacity = 0
+	}
+	return &fixed[T]{capacity: capacity}
+}
+
+// compact 不做任何事：固定容量的栈有意一次性分配全部容量。
+func (f *fixed[T]) compact(bool) {}
+
+func (f *fixed[T]) put(x T) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.items) >= f.capacity {
+		return false
+	}
+	if f.items == nil {
+		// 一次性分配全部容量，之后的存入不再扩容。
+		f.items = make([]T, 0, f.capacity)
+	}
+	f.items = append(f.items, x)
+	return true
+}
+
+// shard 是 sharded 中的一个分片，填充到独立的缓存行以避免伪共享。
+type shard[T any] struct {
+	stack[T]
+	_ [64]byte
+}
+
+// sharded 将对象分散在多个分片栈中。
+//
+// Put 总是放入当前 P 对应的本地分片。默认情况下 Get 从轮转选出的分片开始查找，
+// 使各分片被均匀消耗；启用 cacheWarmth 后 Get 优先从本地分片取出最近放入的对象，
+// 本地分片为空时才去其他分片查找。
+//
+// 设置了 shardFunc 时，本地分片改由 shardFunc 决定，Put 和 Get 都从这个分片开始。
+type sharded[T any] struct {
+	shards      []shard[T]
+	cacheWarmth bool
+	shardFunc   func() int
+
+	next uint32 // Get 的轮转起点
+
+	// hints 借助 sync.Pool 的 per-P 私有槽位为每个 P 缓存一个分片下标，
+	// 从而在不依赖运行时内部接口的情况下获得近似的 P 亲和性。
+	hints   sync.Pool
+	hintSeq uint32
+}
+
+func newSharded[T any](n int, cacheWarmth bool, shardFunc func() int) *sharded[T] {
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	s := &sharded[T]{
+		shards:      make([]shard[T], n),
+		cacheWarmth: cacheWarmth,
+		shardFunc:   shardFunc,
+	}
+	s.hints.New = func() any {
+		i := int(atomic.AddUint32(&s.hintSeq, 1)-1) % len(s.shards)
+		return &i
+	}
+	return s
+}
+
+// local 返回本地分片的下标：设置了 shardFunc 时由它决定，否则为当前 P 对应的分片。
+func (s *sharded[T]) local() int {
+	if s.shardFunc != nil {
+		// 对分片数取模，使任意返回值（包括负数）都落在合法范围内。
+		n := len(s.shards)
+		return (s.shardFunc()%n + n) % n
+	}
+	h := s.hints.Get().(*int)
+	i := *h
+	s.hints.Put(h)
+	return i
+}
+
+func (s *sharded[T]) get() (T, bool) {
+	var start int
+	if s.cacheWarmth || s.shardFunc != nil {
+		start = s.local()
+	} else {
+		start = int(atomic.AddUint32(&s.next, 1)-1) % len(s.shards)
+	}
+	for i := 0; i < len(s.shards); i++ {
+		if x, ok := s.shards[(start+i)%len(s.shards)].get(); ok {
+			return x, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func (s *sharded[T]) put(x T) bool {
+	return s.shards[s.local()].put(x)
+}
+
+func (s *sharded[T]) len() int {
+	n := 0
+	for i := range s.shards {
+		n += s.shards[i].len()
+	}
+	return n
+}
+
+func (s *sharded[T]) drain() []T {
+	var items []T
+	for i := range s.shards {
+		items = append(items, s.shards[i].drain()...)
+	}
+	return items
+}
+
+// take 从本地分片开始在各分片中查找并取出对象 x。
+func (s *sharded[T]) take(x T) bool {
+	start := s.local()
+	for i := 0; i < len(s.shards); i++ {
+		if s.shards[(start+i)%len(s.shards)].take(x) {
+			return true
+		}
+	}
+	return false
+}
+
+// swap 同时锁住所有分片，使替换对其他 goroutine 来说是一次完成的。
+func (s *sharded[T]) swap(build func(n int) []T) []T {
+	for i := range s.shards {
+		s.shards[i].mu.Lock()
+	}
+	var old []T
+	for i := range s.shards {
+		sh := &s.shards[i]
+		old = append(old, sh.items...)
+		sh.items = build(len(sh.items))
+	}
+	for i := range s.shards {
+		s.shards[i].mu.Unlock()
+	}
+	return old
+}
+
+// peek 对第一个非空分片栈顶的对象调用 fn。
+func (s *sharded[T]) peek(fn func(T)) bool {
+	for i := range s.shards {
+		if s.shards[i].peek(fn) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *sharded[T]) compact(force bool) {
+	for i := range s.shards {
+		s.shards[i].compact(force)
+	}
+}
+
+func (s *sharded[T]) each(fn func(T)) {
+	for i := range s.shards {
+		s.shards[i].each(fn)
+	}
+}
+
+func (s *sharded[T]) filter(drop func(T) bool) []T {
+	var dropped []T
+	for i := range s.shards {
+		dropped = append(dropped, s.shards[i].filter(drop)...)
+	}
+	return dropped
+}
@@ -0,0 +1,431 @@
+package gpool
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// trackedObject 用于检测对象是否被同时交给多个调用方。
+type trackedObject struct {
+	inUse int32
+	data  []byte
+}
+
+// TestPool_Deterministic 测试确定性后端总是返回最近放入的对象，且对象不会被 GC 回收。
+func TestPool_Deterministic(t *testing.T) {
+	var newCounter int32
+	p := New(func() *trackedObject {
+		atomic.AddInt32(&newCounter, 1)
+		return &trackedObject{}
+	}, WithDeterministic[*trackedObject]())
+
+	a, b := p.Get(), p.Get()
+	p.Put(a)
+	p.Put(b)
+	runtime.GC()
+
+	if got := p.Get(); got != b {
+		t.Fatal("确定性后端应该是 LIFO 的, 期望先取回最后放入的对象")
+	}
+	if got := p.Get(); got != a {
+		t.Fatal("确定性后端应该按 LIFO 顺序取回第二个对象")
+	}
+	if n := atomic.LoadInt32(&newCounter); n != 2 {
+		t.Fatalf("期望 newFunc 被调用 2 次, 实际 %d 次", n)
+	}
+}
+
+// TestPool_Sharded_CacheWarmth 测试分片后端在启用 WithCacheWarmth 时，
+// 同一个 goroutine 放入后立即取回的是同一个对象。
+func TestPool_Sharded_CacheWarmth(t *testing.T) {
+	p := New(func() *trackedObject {
+		return &trackedObject{}
+	}, WithSharded[*trackedObject](4), WithCacheWarmth[*trackedObject]())
+
+	obj := p.Get()
+	p.Put(obj)
+	if got := p.Get(); got != obj {
+		t.Fatal("启用 WithCacheWarmth 后, 应该优先取回本地分片中最近放入的对象")
+	}
+}
+
+// TestPool_ShardedNoLossOrDoubleIssue 测试分片后端在并发下既不会丢失对象，
+// 也不会把同一个对象同时交给两个调用方。
+func TestPool_ShardedNoLossOrDoubleIssue(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts []Option[*trackedObject]
+	}{
+		{"Default", []Option[*trackedObject]{WithSharded[*trackedObject](0)}},
+		{"CacheWarmth", []Option[*trackedObject]{WithSharded[*trackedObject](0), WithCacheWarmth[*trackedObject]()}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var created int32
+			p := New(func() *trackedObject {
+				atomic.AddInt32(&created, 1)
+				return &trackedObject{}
+			}, tc.opts...)
+
+			numGoroutines := runtime.GOMAXPROCS(0) * 4
+			var wg sync.WaitGroup
+			wg.Add(numGoroutines)
+			for i := 0; i < numGoroutines; i++ {
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 1000; j++ {
+						obj := p.Get()
+						if !atomic.CompareAndSwapInt32(&obj.inUse, 0, 1) {
+							t.Error("同一个对象被同时交给了两个调用方")
+							return
+						}
+						atomic.StoreInt32(&obj.inUse, 0)
+						p.Put(obj)
+					}
+				}()
+			}
+			wg.Wait()
+
+			// 所有对象都已归还，池中的闲置对象应该恰好是所有创建过的对象。
+			seen := make(map[*trackedObject]bool)
+			for {
+				obj, ok := p.store.get()
+				if !ok {
+					break
+				}
+				if seen[obj] {
+					t.Fatal("同一个对象在池中出现了两次")
+				}
+				seen[obj] = true
+			}
+			if n := int(atomic.LoadInt32(&created)); len(seen) != n {
+				t.Fatalf("期望池中有 %d 个对象, 实际有 %d 个, 有对象丢失", n, len(seen))
+			}
+		})
+	}
+}
+
+// BenchmarkPool_CacheWarmth 对比分片后端在启用和不启用 WithCacheWarmth 时，
+// 访问对象内存的工作负载的吞吐量。
+func BenchmarkPool_CacheWarmth(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		opts []Option[*trackedObject]
+	}{
+		{"Sharded", []Option[*trackedObject]{WithSharded[*trackedObject](0)}},
+		{"ShardedCacheWarmth", []Option[*trackedObject]{WithSharded[*trackedObject](0), WithCacheWarmth[*trackedObject]()}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			p := New(func() *trackedObject {
+				return &trackedObject{data: make([]byte, 8<<10)}
+			}, bc.opts...)
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					obj := p.Get()
+					for i := range obj.data {
+						obj.data[i]++
+					}
+					p.Put(obj)
+				}
+			})
+		})
+	}
+}
+
+// TestPool_ShardFunc 测试 WithShardFunc 将对象放入指定的分片，并在分片内复用。
+func TestPool_ShardFunc(t *testing.T) {
+	tenant := 0
+	p := New(func() *trackedObject {
+		return &trackedObject{}
+	}, WithSharded[*trackedObject](4), WithShardFunc[*trackedObject](func() int {
+		return tenant
+	}))
+	s := p.store.(*sharded[*trackedObject])
+
+	a, b, c := p.Get(), p.Get(), p.Get()
+	tenant = 1
+	p.Put(a)
+	// 超出范围的下标会对分片数取模：6 % 4 == 2，-1 落在分片 3。
+	tenant = 6
+	p.Put(b)
+	tenant = -1
+	p.Put(c)
+
+	for i, want := range []int{0, 1, 1, 1} {
+		if n := s.shards[i].len(); n != want {
+			t.Fatalf("分片 %d 中期望有 %d 个对象, 实际有 %d 个", i, want, n)
+		}
+	}
+
+	tenant = 1
+	if got := p.Get(); got != a {
+		t.Fatal("应该复用同一分片中的对象")
+	}
+	tenant = 2
+	if got := p.Get(); got != b {
+		t.Fatal("应该复用同一分片中的对象")
+	}
+	tenant = 3
+	if got := p.Get(); got != c {
+		t.Fatal("应该复用同一分片中的对象")
+	}
+}
+
+// TestPool_Capacity 测试固定容量后端最多保存 capacity 个闲置对象，多余的对象以 DiscardOverflow 被丢弃。
+func TestPool_Capacity(t *testing.T) {
+	p := New(func() *closerObject {
+		return &closerObject{}
+	}, WithCapacity[*closerObject](2))
+
+	xs := p.GetAll(3)
+	p.PutAll(xs)
+
+	s := p.Stats()
+	if s.Idle != 2 || s.DiscardsByReason[DiscardOverflow] != 1 {
+		t.Fatalf("期望保存 2 个对象并以 %q 丢弃 1 个, 得到 %+v", DiscardOverflow, s)
+	}
+	if xs[2].closed != 1 {
+		t.Error("超出容量的对象应该被关闭")
+	}
+	if p.Get() != xs[1] || p.Get() != xs[0] {
+		t.Error("固定容量后端应该按 LIFO 顺序返回对象")
+	}
+}
+
+// TestPool_Capacity_Concurrent 测试并发放回时固定容量后端保存的闲置对象也不超过容量，
+// 每个没有被保存的对象都以 DiscardOverflow 被丢弃并关闭。
+func TestPool_Capacity_Concurrent(t *testing.T) {
+	const capacity, workers, rounds = 4, 8, 100
+	var closed int64
+	p := New(func() *closerObject {
+		return &closerObject{}
+	}, WithCapacity[*closerObject](capacity), WithOnDiscard(func(x *closerObject, reason string) {
+		if reason == DiscardOverflow {
+			atomic.AddInt64(&closed, 1)
+		}
+	}))
+
+	var wg sync.WaitGroup
+	for g := 0; g < workers; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				p.Put(&closerObject{})
+				if n := p.Stats().Idle; n > capacity {
+					t.Errorf("闲置对象 %d 个, 超过了容量 %d", n, capacity)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if s := p.Stats(); s.Idle != capacity || s.Idle+atomic.LoadInt64(&closed) != workers*rounds {
+		t.Fatalf("期望保存 %d 个对象并丢弃其余的对象, 得到 %+v", capacity, s)
+	}
+}
+
+// point 是一个用于测试值类型池的小结构体。
+type point struct {
+	X, Y int64
+}
+
+// TestPool_NewValue 测试 NewValue 创建的池在并发使用时正确复制值，且 Get/Put 不产生内存分配。
+func TestPool_NewValue(t *testing.T) {
+	p := NewValue(func() point { return point{} })
+	if got := p.Config().Backend; got != BackendDeterministic {
+		t.Fatalf("期望使用空闲列表后端, 得到 %q", got)
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int64) {
+			defer wg.Done()
+			for i := int64(0); i < 1000; i++ {
+				v := p.Get()
+				// 值被复制出来，其他 goroutine 不会看到这里的修改。
+				if v.X != v.Y {
+					t.Errorf("取回的值被破坏: %+v", v)
+					return
+				}
+				v.X, v.Y = g*i, g*i
+				p.Put(v)
+			}
+		}(int64(g))
+	}
+	wg.Wait()
+
+	if s := p.Stats(); s.Outstanding != 0 || s.Idle != s.Misses {
+		t.Fatalf("期望所有值都被放回, 得到 %+v", s)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		p.Put(p.Get())
+	})
+	if allocs != 0 {
+		t.Errorf("期望 Get/Put 不产生内存分配, 实际每次 %v 次", allocs)
+	}
+}
+
+// BenchmarkPool_Value 比较小的值类型在 sync.Pool 后端和 NewValue 空闲列表中的开销。
+func BenchmarkPool_Value(b *testing.B) {
+	newPoint := func() point { return point{} }
+	for _, bc := range []struct {
+		name string
+		pool *Pool[point]
+	}{
+		{"SyncPool", New(newPoint)},
+		{"FreeList", NewValue(newPoint)},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				v := bc.pool.Get()
+				v.X++
+				bc.pool.Put(v)
+			}
+		})
+	}
+}
+
+// handoff 是在 goroutine 之间传递的测试对象，它的字段在 Put 之前被写入，在 Get 之后被读取。
+type handoff struct {
+	seq     int
+	payload [8]int
+}
+
+// TestPool_HappensBefore 测试各个后端的 Put 与随后取回同一对象的 Get 之间存在 happens-before 关系：
+// 一个 goroutine 在 Put 之前对对象的写入，对之后 Get 到它的 goroutine 可见。
+// 缺少同步时，这个测试在 -race 下会报告数据竞争，也可能观察到写了一半的对象。
+func TestPool_HappensBefore(t *testing.T) {
+	backends := map[string][]Option[*handoff]{
+		"sync.Pool":     nil,
+		"deterministic": {WithDeterministic[*handoff]()},
+		"sharded":       {WithSharded[*handoff](4)},
+		"fixed":         {WithCapacity[*handoff](8)},
+		"affinity":      {WithGoroutineAffinity[*handoff]()},
+		"async-put":     {WithDeterministic[*handoff](), WithAsyncPut[*handoff](8)},
+	}
+	for name, opts := range backends {
+		t.Run(name, func(t *testing.T) {
+			p := New(func() *handoff { return new(handoff) }, opts...)
+			defer p.Close()
+			exerciseHandoff(t, func() *handoff { return p.Get() }, func(h *handoff) { p.Put(h) })
+		})
+	}
+
+	// 值类型对象本身在 Put 和 Get 时被复制，但它引用的底层数组仍在 goroutine 之间共享。
+	t.Run("value", func(t *testing.T) {
+		p := NewValue(func() []int { return make([]int, 8) })
+		defer p.Close()
+		var wg sync.WaitGroup
+		for g := 0; g < 4; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 500; i++ {
+					s := p.Get()
+					for j := range s {
+						if s[j] != s[0] {
+							t.Errorf("观察到写了一半的对象: %v", s)
+							return
+						}
+					}
+					next := s[0] + 1
+					for j := range s {
+						s[j] = next
+					}
+					p.Put(s)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+// exerciseHandoff 让多个 goroutine 反复通过 get 取出对象、检查它的内容是否完整、写入新内容再通过 put 放回。
+func exerciseHandoff(t *testing.T, get func() *handoff, put func(*handoff)) {
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				h := get()
+				for _, v := range h.payload {
+					if v != h.seq {
+						t.Errorf("观察到写了一半的对象: seq %d, payload %v", h.seq, h.payload)
+						return
+					}
+				}
+				h.seq++
+				for j := range h.payload {
+					h.payload[j] = h.seq
+				}
+				put(h)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// TestPool_Compact 测试大量闲置对象被取出后，Compact 把底层数组收缩到当前的闲置数量。
+func TestPool_Compact(t *testing.T) {
+	p := New(func() *handoff { return new(handoff) }, WithDeterministic[*handoff]())
+	s := baseStore(p.store).(*stack[*handoff])
+
+	p.PutAll(p.GetAll(1000))
+	p.GetAll(990)
+	if c := cap(s.items); c < 1000 {
+		t.Fatalf("取出对象后底层数组应该保持原来的容量, 得到 %d", c)
+	}
+	p.Compact()
+	if n, c := len(s.items), cap(s.items); n != 10 || c != 10 {
+		t.Fatalf("Compact 后期望长度和容量都为 10, 得到 %d 和 %d", n, c)
+	}
+	if got := p.Stats().Idle; got != 10 {
+		t.Errorf("Compact 不应该改变闲置对象, 得到 %d 个", got)
+	}
+
+	p.GetAll(10)
+	p.Compact()
+	if s.items != nil {
+		t.Error("没有闲置对象时应该释放整个底层数组")
+	}
+}
+
+// TestPool_AutoCompact 测试后台维护只在底层数组明显过大时收缩它，固定容量的后端不会被收缩。
+func TestPool_AutoCompact(t *testing.T) {
+	p := New(func() *handoff { return new(handoff) }, WithSharded[*handoff](1), WithAutoCompact[*handoff]())
+	defer p.Close()
+	s := &baseStore(p.store).(*sharded[*handoff]).shards[0]
+
+	p.PutAll(p.GetAll(100))
+	p.GetAll(50)
+	p.maintain()
+	if c := cap(s.items); c < 100 {
+		t.Fatalf("闲置对象不少于容量的四分之一时不应该收缩, 容量变为 %d", c)
+	}
+	p.GetAll(40)
+	p.maintain()
+	if n, c := len(s.items), cap(s.items); n != 10 || c != 10 {
+		t.Fatalf("期望收缩到 10 个, 得到长度 %d、容量 %d", n, c)
+	}
+	if !p.Config().AutoCompact {
+		t.Error("Config 应该报告启用了自动收缩")
+	}
+
+	f := New(func() *handoff { return new(handoff) }, WithCapacity[*handoff](8), WithAutoCompact[*handoff]())
+	defer f.Close()
+	f.Put(new(handoff))
+	f.Compact()
+	if c := cap(baseStore(f.store).(*fixed[*handoff]).items); c != 8 {
+		t.Errorf("固定容量的后端不应该被收缩, 容量变为 %d", c)
+	}
+	if f.Config().AutoCompact || f.maint != nil {
+		t.Error("固定容量的后端不应该启用自动收缩")
+	}
+}
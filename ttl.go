@@ -0,0 +1,109 @@
+package gpool
+
+import "time"
+
+// WithTTL 让对象在最近一次被使用（创建、借出、放回或通过 Touch 标记）之后超过 d 仍未被使用时过期：
+// 后台维护会定期丢弃过期的闲置对象，Get 也不会交出过期的对象；在借出期间过期的对象会在放回时直接被丢弃。
+// 它们都以 DiscardExpired 为原因被丢弃（实现了 io.Closer 的对象会被关闭）。
+// 需要长时间持有对象的调用方可以在持有期间调用 Touch，避免对象在仍被使用时过期。
+//
+// 过期依据 WithMetadata 记录的 LastUsedAt 判断，因此该选项会同时启用 WithMetadata，并且只对指针类型 T 有效：
+// 其他类型的池上它不起作用，也不会改变池的后端。d <= 0 时对象不会过期。
+func WithTTL[T any](d time.Duration) Option[T] {
+	return func(c *config[T]) {
+		c.ttl = d
+		if d > 0 && hasMeta[T]() {
+			c.metadata = true
+			c.needStore = true
+		}
+	}
+}
+
+// WithIdleTimeout 让后台维护 goroutine 定期清理在池中闲置超过 d 的对象，
+// 使长期运行的服务在流量高峰过后不会一直持有高峰时的全部对象；Get 也不会交出闲置太久的对象。
+// 它们以 DiscardExpired 为原因被丢弃：实现了 io.Closer 的对象会被关闭，需要其他销毁逻辑时可以在 WithOnDiscard 中处理。
+//
+// 与 WithTTL 不同，它只关心对象在池中闲置的时间，借出期间不会计时，放回时也不会因此被丢弃。
+// 闲置时间依据 WithMetadata 记录的 LastUsedAt 判断，因此该选项会同时启用 WithMetadata，并且只对指针类型 T 有效：
+// 其他类型的池上它不起作用，也不会改变池的后端。由于 sync.Pool 的闲置对象由 GC 管理，
+// 未指定其他后端时会改用确定性后端。d <= 0 时不启用。
+func WithIdleTimeout[T any](d time.Duration) Option[T] {
+	return func(c *config[T]) {
+		c.idleTimeout = d
+		if d > 0 && hasMeta[T]() {
+			c.metadata = true
+			c.needStore = true
+		}
+	}
+}
+
+// WithClock 设置池读取当前时间所用的函数，默认为 time.Now。
+// WithMetadata 记录的时间和 WithTTL 的过期判断都使用这个时钟，测试中可以借助它模拟时间的流逝。
+func WithClock[T any](now func() time.Time) Option[T] {
+	return func(c *config[T]) {
+		c.now = now
+	}
+}
+
+// Touch 把借出的对象 x 标记为刚刚被使用过，重新开始 WithTTL 的计时，而不必放回再重新取出它。
+// 它适合在一次耗时很长的操作中持有对象的调用方，避免对象在仍被使用时过期、放回时被丢弃。
+// 池没有记录元数据（参见 WithMetadata），或者 x 不是池中（或借出）的对象时，Touch 不做任何事。
+func (p *Pool[T]) Touch(x T) {
+	if p.meta != nil && !isNil(x) {
+		p.meta.touch(x)
+	}
+}
+
+// now 返回池的时钟的当前时间。
+func (p *Pool[T]) now() time.Time {
+	if p.cfg.now != nil {
+		return p.cfg.now()
+	}
+	return time.Now()
+}
+
+// expires 报告池中的对象是否会按 WithTTL 过期。
+func (p *Pool[T]) expires() bool {
+	return p.cfg.ttl > 0 && p.meta != nil
+}
+
+// expired 报告对象 x 是否已经超过 WithTTL 设置的时间未被使用。池没有见过的对象不会过期。
+func (p *Pool[T]) expired(x T) bool {
+	return p.unusedFor(x) > p.cfg.ttl
+}
+
+// idleTimeouts 报告池是否会丢弃超过 WithIdleTimeout 设置的时间一直闲置的对象。
+func (p *Pool[T]) idleTimeouts() bool {
+	return p.cfg.idleTimeout > 0 && p.meta != nil
+}
+
+// stale 报告闲置对象 x 是否已经按 WithTTL 过期，或者超过 WithIdleTimeout 设置的时间一直闲置。
+// 对闲置对象来说，最近一次被使用就是它被放回池中的时候。
+func (p *Pool[T]) stale(x T) bool {
+	return (p.expires() && p.expired(x)) || (p.idleTimeouts() && p.unusedFor(x) > p.cfg.idleTimeout)
+}
+
+// unusedFor 返回对象 x 自最近一次被使用以来经过的时间，池没有见过 x 时返回 0。
+func (p *Pool[T]) unusedFor(x T) time.Duration {
+	last, ok := p.meta.lastUsed(x)
+	if !ok {
+		return 0
+	}
+	return p.now().Sub(last)
+}
+
+// DrainOlderThan 立即丢弃所有闲置时间超过 d 的闲置对象，并返回丢弃的数量。
+// 它适合在收到内存告警等情况下手动裁剪长期闲置的对象，而不必等待 WithTTL 的下一轮后台清理。
+// 被丢弃的对象以 DiscardExpired 为原因通知 WithOnDiscard 的回调，实现了 io.Closer 的对象会被关闭。
+//
+// 闲置时间是对象自最近一次被使用（通常是被放回池中）以来经过的时间，按 WithClock 设置的时钟计算。
+// 它依据 WithMetadata 记录的时间判断：池没有记录元数据（未启用 WithMetadata 或 WithTTL，或者 T 不是指针类型）
+// 或已经关闭时，DrainOlderThan 不做任何事并返回 0。
+func (p *Pool[T]) DrainOlderThan(d time.Duration) int {
+	if p.meta == nil || p.isClosed() {
+		return 0
+	}
+	return p.sweep(func(x T) bool {
+		return p.unusedFor(x) > d
+	}, DiscardExpired)
+}
@@ -0,0 +1,223 @@
+package gpool
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock 是测试使用的假时钟，只有调用 advance 时时间才会流逝。
+type fakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	c.t = c.t.Add(d)
+	c.mu.Unlock()
+}
+
+// newTTLPool 创建一个对象在 ttl 之后过期、使用假时钟的池。
+func newTTLPool(ttl time.Duration, clk *fakeClock) *Pool[*closerObject] {
+	return New(func() *closerObject {
+		return &closerObject{}
+	}, WithTTL[*closerObject](ttl), WithClock[*closerObject](clk.now))
+}
+
+// TestPool_TTL 测试闲置时间超过 TTL 的对象被后台维护丢弃，Get 也不会交出过期的对象。
+func TestPool_TTL(t *testing.T) {
+	clk := newFakeClock()
+	p := newTTLPool(time.Minute, clk)
+	defer p.Close()
+	if c := p.Config(); c.TTL != time.Minute || !c.Metadata || !c.Clock || p.maint == nil {
+		t.Fatalf("期望启用元数据和后台维护, 得到 %+v", c)
+	}
+
+	old := p.Get()
+	p.Put(old)
+	clk.advance(40 * time.Second)
+	fresh := &closerObject{}
+	p.Put(fresh)
+	clk.advance(30 * time.Second)
+
+	// old 已闲置 70 秒，fresh 只闲置了 30 秒。
+	p.maintain()
+	if old.closed != 1 || fresh.closed != 0 || p.Stats().Idle != 1 {
+		t.Fatal("期望只有闲置时间超过 TTL 的对象被丢弃并关闭")
+	}
+
+	clk.advance(time.Minute)
+	if x := p.Get(); x == fresh || fresh.closed != 1 {
+		t.Fatal("Get 不应该交出过期的对象")
+	}
+	if s := p.Stats(); s.DiscardsByReason[DiscardExpired] != 2 {
+		t.Fatalf("期望 2 个对象因过期被丢弃, 得到 %+v", s)
+	}
+}
+
+// TestPool_Touch 测试被 Touch 的借出对象重新开始计时，在放回时不会因过期而被丢弃。
+func TestPool_Touch(t *testing.T) {
+	clk := newFakeClock()
+	p := newTTLPool(time.Minute, clk)
+	defer p.Close()
+
+	touched, idle := p.Get(), p.Get()
+	clk.advance(50 * time.Second)
+	p.Touch(touched)
+	if m := p.Meta(touched); !m.LastUsedAt.Equal(clk.now()) || m.Borrows != 1 {
+		t.Fatalf("Touch 应该只更新最近使用时间, 得到 %+v", m)
+	}
+	clk.advance(20 * time.Second)
+
+	// 两个对象都已借出 70 秒，但 touched 在 20 秒前被标记为使用中。
+	p.Put(touched)
+	p.Put(idle)
+	if touched.closed != 0 || idle.closed != 1 {
+		t.Fatal("期望只有没有被 Touch 的对象在放回时因过期被丢弃")
+	}
+	p.maintain()
+	if x := p.Get(); x != touched {
+		t.Fatal("被 Touch 的对象应该保留在池中")
+	}
+
+	// 不是池中的对象，以及未启用元数据的池上的 Touch 都不做任何事。
+	p.Touch(&closerObject{})
+	New(func() *closerObject { return &closerObject{} }).Touch(touched)
+}
+
+// TestPool_IdleTimeout 测试只有在池中闲置超过超时时间的对象才会被清理，借出期间不计时。
+func TestPool_IdleTimeout(t *testing.T) {
+	clk := newFakeClock()
+	p := New(func() *closerObject {
+		return &closerObject{}
+	}, WithIdleTimeout[*closerObject](time.Minute), WithClock[*closerObject](clk.now))
+	defer p.Close()
+	if c := p.Config(); c.IdleTimeout != time.Minute || c.TTL != 0 || c.Backend != BackendDeterministic || p.maint == nil {
+		t.Fatalf("期望启用闲置超时和后台维护, 得到 %+v", c)
+	}
+
+	held := p.Get()
+	clk.advance(2 * time.Minute)
+	p.Put(held)
+	if held.closed != 0 || p.Stats().Idle != 1 {
+		t.Fatal("借出期间不应该计入闲置时间")
+	}
+
+	clk.advance(30 * time.Second)
+	p.maintain()
+	if held.closed != 0 {
+		t.Fatal("闲置时间未超过超时的对象不应该被清理")
+	}
+	clk.advance(time.Minute)
+	p.maintain()
+	if s := p.Stats(); held.closed != 1 || s.Idle != 0 || s.DiscardsByReason[DiscardExpired] != 1 {
+		t.Fatalf("期望闲置超时的对象被清理并关闭, 得到 %+v", s)
+	}
+}
+
+// TestPool_IdleTimeout_Concurrent 测试后台清理在存储中原地筛选闲置对象：与它并发的 Get 不会因为池被临时清空而创建新对象，
+// 有闲置上限的池也不会因为并发的 Put 占满空位而丢弃仍然有效的对象。
+func TestPool_IdleTimeout_Concurrent(t *testing.T) {
+	clk := newFakeClock()
+	p := New(func() *closerObject {
+		return &closerObject{}
+	}, WithIdleTimeout[*closerObject](time.Minute), WithClock[*closerObject](clk.now), WithMaxIdle[*closerObject](8))
+	defer p.Close()
+	p.WarmUp(8)
+
+	// 每个 worker 最多同时借出一个对象，因此池中总有闲置对象可用。
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 2000; j++ {
+				p.Put(p.Get())
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	for {
+		select {
+		case <-done:
+			if s := p.Stats(); s.Misses != 0 || s.Discards != 0 {
+				t.Fatalf("后台清理不应该使 Get 创建新对象或丢弃有效的对象, 得到 %+v", s)
+			}
+			return
+		default:
+			p.maintain()
+		}
+	}
+}
+
+// TestPool_DrainOlderThan 测试 DrainOlderThan 只丢弃闲置时间足够长的对象，并返回准确的数量。
+func TestPool_DrainOlderThan(t *testing.T) {
+	clk := newFakeClock()
+	p := New(func() *closerObject {
+		return &closerObject{}
+	}, WithMetadata[*closerObject](), WithClock[*closerObject](clk.now))
+
+	var old []*closerObject
+	for i := 0; i < 3; i++ {
+		old = append(old, &closerObject{})
+		p.Put(old[i])
+	}
+	clk.advance(10 * time.Minute)
+	recent := &closerObject{}
+	p.Put(recent)
+	clk.advance(time.Minute)
+
+	if n := p.DrainOlderThan(11 * time.Minute); n != 0 {
+		t.Fatalf("没有对象闲置超过 11 分钟, 却丢弃了 %d 个", n)
+	}
+	if n := p.DrainOlderThan(5 * time.Minute); n != 3 {
+		t.Fatalf("期望丢弃 3 个闲置超过 5 分钟的对象, 实际丢弃了 %d 个", n)
+	}
+	for i, x := range old {
+		if x.closed != 1 {
+			t.Errorf("第 %d 个旧对象应该被关闭", i)
+		}
+	}
+	s := p.Stats()
+	if recent.closed != 0 || s.Idle != 1 || s.DiscardsByReason[DiscardExpired] != 3 {
+		t.Fatalf("期望只保留最近放回的对象, 得到 %+v", s)
+	}
+
+	// 没有记录元数据的池无法判断闲置时间。
+	if n := New(func() *closerObject { return &closerObject{} }).DrainOlderThan(0); n != 0 {
+		t.Fatalf("未记录元数据的池不应该丢弃对象, 丢弃了 %d 个", n)
+	}
+}
+
+// TestPool_TTL_NonPointer 测试 T 不是指针类型时 WithTTL 不起作用，池仍然使用 sync.Pool 后端，也不启动维护 goroutine。
+func TestPool_TTL_NonPointer(t *testing.T) {
+	p := New(func() []byte { return make([]byte, 0, 64) }, WithTTL[[]byte](time.Second))
+	defer p.Close()
+	if c := p.Config(); c.Backend != BackendSyncPool || c.Metadata || c.TTL != 0 || p.maint != nil {
+		t.Fatalf("WithTTL 不应该对非指针类型生效或改变后端, 得到 %+v", c)
+	}
+}
+
+// TestPool_IdleTimeout_NonPointer 测试 T 不是指针类型时 WithIdleTimeout 不起作用，池仍然使用 sync.Pool 后端，也不启动维护 goroutine。
+func TestPool_IdleTimeout_NonPointer(t *testing.T) {
+	p := New(func() []byte { return make([]byte, 0, 64) }, WithIdleTimeout[[]byte](time.Minute))
+	defer p.Close()
+	if c := p.Config(); c.Backend != BackendSyncPool || c.Metadata || c.IdleTimeout != 0 || p.maint != nil {
+		t.Fatalf("WithIdleTimeout 不应该对非指针类型生效或改变后端, 得到 %+v", c)
+	}
+}
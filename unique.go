@@ -0,0 +1,78 @@
+package gpool
+
+import (
+	"fmt"
+	"sync"
+)
+
+// WithUniqueIssue 为必须独占使用的资源（例如唯一的文件句柄、硬件槽位）启用严格的身份记账：
+// 池记录每个对象当前是闲置还是已借出，保证同一个对象绝不会同时交给两个调用方，
+// 也绝不会同时出现在池中和调用方手里。
+//
+// 最常见的违规是把同一个对象放回两次，或者放回后继续使用并再次放回：启用该选项后，
+// 放回一个已经闲置在池中的对象会 panic 并说明原因，而不是让它在之后被两个调用方同时取走；
+// 如果池即将借出一个已经借出的对象，Get 也会 panic，而不是交出它。
+// 从未被借出过的对象（例如直接放入池中的初始资源）可以正常放回。
+//
+// 检查需要以对象本身作为键，T 必须可以比较且不是接口类型，否则 New 会 panic。
+// 由于 sync.Pool 的对象会被 GC 静默回收，未指定其他后端时会改用确定性后端；
+// 通常与 WithMax 一起使用，同时限制资源的借出数量。
+func WithUniqueIssue[T any]() Option[T] {
+	return func(c *config[T]) {
+		c.uniqueIssue = true
+		c.needStore = true
+	}
+}
+
+// issueState 是 WithUniqueIssue 记录的对象状态。
+type issueState uint8
+
+const (
+	issueIdle issueState = iota + 1
+	issueOut
+)
+
+// issueLedger 记录 WithUniqueIssue 池中每个对象是闲置还是已借出。
+type issueLedger struct {
+	mu    sync.Mutex
+	state map[any]issueState
+	typ   string
+}
+
+// newIssueLedger 为类型 T 创建一个 issueLedger，未启用 WithUniqueIssue 时返回 nil。
+func newIssueLedger[T any](enabled bool) *issueLedger {
+	if !enabled {
+		return nil
+	}
+	if !hasIdentity[T]() {
+		panic(fmt.Sprintf("gpool: WithUniqueIssue requires a comparable, non-interface type, got %s", typeOf[T]()))
+	}
+	return &issueLedger{state: make(map[any]issueState), typ: typeOf[T]().String()}
+}
+
+// issue 把对象 x 标记为已借出，x 已经借出时 panic。
+func (l *issueLedger) issue(x any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.state[x] == issueOut {
+		panic("gpool: object of type " + l.typ + " is about to be issued while it is already outstanding (WithUniqueIssue)")
+	}
+	l.state[x] = issueOut
+}
+
+// returned 把对象 x 标记为闲置，x 已经闲置在池中时 panic。
+func (l *issueLedger) returned(x any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.state[x] == issueIdle {
+		panic("gpool: object of type " + l.typ + " was put back while it is already idle in the pool (WithUniqueIssue)")
+	}
+	l.state[x] = issueIdle
+}
+
+// forget 停止跟踪离开池的对象 x。
+func (l *issueLedger) forget(x any) {
+	l.mu.Lock()
+	delete(l.state, x)
+	l.mu.Unlock()
+}
@@ -0,0 +1,87 @@
+package gpool
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// slot 是一个只能被独占使用的资源，inUse 在借出时置位、放回前清除。
+type slot struct {
+	id    int
+	inUse int32
+}
+
+// TestPool_UniqueIssue_Stress 测试高并发下同一个对象从不会同时被两个 goroutine 持有。
+func TestPool_UniqueIssue_Stress(t *testing.T) {
+	const slots, workers, rounds = 4, 32, 500
+	var next int32
+	p := New(func() *slot {
+		return &slot{id: int(atomic.AddInt32(&next, 1))}
+	}, WithUniqueIssue[*slot](), WithMax[*slot](slots))
+	p.WarmUp(slots)
+
+	var doubled int32
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < rounds; j++ {
+				s := p.Get()
+				if !atomic.CompareAndSwapInt32(&s.inUse, 0, 1) {
+					atomic.AddInt32(&doubled, 1)
+				}
+				runtime.Gosched()
+				atomic.StoreInt32(&s.inUse, 0)
+				p.Put(s)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if doubled != 0 {
+		t.Fatalf("有 %d 次同一个对象被同时借给了两个 goroutine", doubled)
+	}
+	if n := atomic.LoadInt32(&next); n != slots {
+		t.Errorf("期望只创建 %d 个资源, 实际创建了 %d 个", slots, n)
+	}
+	if !p.Config().UniqueIssue {
+		t.Error("Config 应该报告启用了 WithUniqueIssue")
+	}
+}
+
+// TestPool_UniqueIssue_DoublePut 测试放回一个已经闲置的对象会 panic，而不是让它之后被借出两次。
+func TestPool_UniqueIssue_DoublePut(t *testing.T) {
+	p := New(func() *slot { return &slot{} }, WithUniqueIssue[*slot]())
+	initial := &slot{id: 1}
+	p.Put(initial) // 从未借出过的初始资源可以直接放入
+
+	x := p.Get()
+	if x != initial {
+		t.Fatal("期望借出放入的初始资源")
+	}
+	p.Put(x)
+	defer func() {
+		r := recover()
+		if msg, _ := r.(string); !strings.Contains(msg, "already idle") {
+			t.Fatalf("重复放回应该 panic, 得到 %v", r)
+		}
+		if s := p.Stats(); s.Idle != 1 {
+			t.Errorf("重复放回的对象不应该再次存入池中: %+v", s)
+		}
+	}()
+	p.Put(x)
+}
+
+// TestPool_UniqueIssue_RequiresIdentity 测试 T 不可比较时 New 会 panic。
+func TestPool_UniqueIssue_RequiresIdentity(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("T 不可比较时期望 panic")
+		}
+	}()
+	New(func() []byte { return nil }, WithUniqueIssue[[]byte]())
+}
@@ -0,0 +1,184 @@
+package gpool
+
+import "testing"
+
+// validatedObject 是一个可以被标记为失效的测试对象。
+type validatedObject struct {
+	broken bool
+	closerObject
+}
+
+func isHealthy(o *validatedObject) bool {
+	return !o.broken
+}
+
+// TestPool_ValidateOnPut 测试 Put 时未通过校验的对象会被关闭并丢弃，通过校验的对象会被存入。
+func TestPool_ValidateOnPut(t *testing.T) {
+	var reasons []string
+	p := New(func() *validatedObject {
+		return &validatedObject{}
+	}, WithDeterministic[*validatedObject](),
+		WithValidateOnPut(isHealthy),
+		WithOnDiscard(func(_ *validatedObject, reason string) {
+			reasons = append(reasons, reason)
+		}))
+
+	good, bad := p.Get(), p.Get()
+	bad.broken = true
+	p.Put(bad)
+	p.Put(good)
+
+	if n := p.store.len(); n != 1 {
+		t.Fatalf("只有通过校验的对象应该被存入, 期望池中有 1 个对象, 实际有 %d 个", n)
+	}
+	if bad.closed != 1 || good.closed != 0 {
+		t.Fatalf("只有未通过校验的对象应该被关闭, 实际关闭次数为 bad=%d good=%d", bad.closed, good.closed)
+	}
+	if len(reasons) != 1 || reasons[0] != DiscardInvalid {
+		t.Fatalf("期望以 %q 丢弃一次, 实际丢弃原因为 %v", DiscardInvalid, reasons)
+	}
+	if got := p.Get(); got != good {
+		t.Fatal("应该取回通过校验的对象")
+	}
+}
+
+// TestPool_Validator 测试 Get 时会跳过并丢弃在闲置期间失效的对象。
+func TestPool_Validator(t *testing.T) {
+	p := New(func() *validatedObject {
+		return &validatedObject{}
+	}, WithDeterministic[*validatedObject](), WithValidator(isHealthy))
+
+	good, bad := p.Get(), p.Get()
+	p.Put(good)
+	p.Put(bad)
+	// bad 在闲置期间失效。
+	bad.broken = true
+
+	if got := p.Get(); got != good {
+		t.Fatal("Get() 应该跳过失效的对象, 返回下一个有效的对象")
+	}
+	if bad.closed != 1 {
+		t.Fatalf("失效的对象应该被关闭一次, 实际 %d 次", bad.closed)
+	}
+	if got := p.Get(); got == bad || got == good {
+		t.Fatal("池中没有有效对象时, Get() 应该创建新对象")
+	}
+}
+
+// TestPool_Validator_SyncPool 测试 sync.Pool 后端在校验失败后直接创建新对象。
+func TestPool_Validator_SyncPool(t *testing.T) {
+	p := New(func() *validatedObject {
+		return &validatedObject{}
+	}, WithValidator(isHealthy))
+
+	obj := p.Get()
+	obj.broken = true
+	p.Put(obj)
+
+	if got := p.Get(); got.broken {
+		t.Fatal("Get() 不应该返回未通过校验的对象")
+	}
+}
+
+// TestPool_Validator_SyncPoolFresh 测试 sync.Pool 后端也不校验由 newFunc 新建的对象，
+// 新建的对象不会被丢弃，统计中的 Hits 也不会变成负数。
+func TestPool_Validator_SyncPoolFresh(t *testing.T) {
+	var validated int
+	p := New(func() *validatedObject {
+		return &validatedObject{}
+	}, WithValidator(func(*validatedObject) bool {
+		validated++
+		return false
+	}))
+
+	if got := p.Get(); got == nil {
+		t.Fatal("期望 Get() 返回新建的对象")
+	}
+	if s := p.Stats(); validated != 0 || s.Misses != 1 || s.Hits != 0 || s.Discards != 0 {
+		t.Fatalf("新建的对象不应该被校验或丢弃: 校验了 %d 次, %+v", validated, s)
+	}
+}
+
+// TestPool_Validator_BothSet 测试同时设置两个校验函数时，它们各自独立运行。
+func TestPool_Validator_BothSet(t *testing.T) {
+	var onGet, onPut int
+	p := New(func() *validatedObject {
+		return &validatedObject{}
+	}, WithDeterministic[*validatedObject](),
+		WithValidator(func(o *validatedObject) bool {
+			onGet++
+			return isHealthy(o)
+		}),
+		WithValidateOnPut(func(o *validatedObject) bool {
+			onPut++
+			return isHealthy(o)
+		}))
+
+	obj := p.Get()
+	p.Put(obj)
+	p.Get()
+
+	if onPut != 1 || onGet != 1 {
+		t.Fatalf("期望两个校验函数各运行一次, 实际 onPut=%d onGet=%d", onPut, onGet)
+	}
+}
+
+// TestPool_RetainGuard 测试仍然引用外部数据的对象在 Put 时被丢弃，清理干净的对象被保留。
+func TestPool_RetainGuard(t *testing.T) {
+	type request struct {
+		body *[]byte // 指向外部的大块数据
+	}
+	p := New(func() *request {
+		return &request{}
+	}, WithDeterministic[*request](), WithRetainGuard(func(r *request) bool {
+		return r.body != nil
+	}))
+
+	body := make([]byte, 1<<20)
+	dirty, clean := p.Get(), p.Get()
+	dirty.body = &body
+	clean.body = &body
+	clean.body = nil
+	p.Put(dirty)
+	p.Put(clean)
+
+	s := p.Stats()
+	if s.Idle != 1 || s.DiscardsByReason[DiscardRetains] != 1 {
+		t.Fatalf("期望以 %q 丢弃 1 个对象并保留 1 个, 得到 %+v", DiscardRetains, s)
+	}
+	if p.Get() != clean {
+		t.Error("期望保留清理干净的对象")
+	}
+}
+
+// TestPool_Dedup 测试与闲置对象相等的对象在 Put 时被丢弃并计数，不相等的对象正常存入。
+func TestPool_Dedup(t *testing.T) {
+	type entry struct{ key string }
+	var discarded []string
+	p := New(func() *entry {
+		return &entry{}
+	}, WithDedup(func(a, b *entry) bool {
+		return a.key == b.key
+	}), WithOnDiscard(func(_ *entry, reason string) {
+		discarded = append(discarded, reason)
+	}))
+
+	a, dup, b := &entry{"a"}, &entry{"a"}, &entry{"b"}
+	p.Put(a)
+	p.Put(dup)
+	p.Put(b)
+
+	s := p.Stats()
+	if s.Idle != 2 || s.DiscardsByReason[DiscardDuplicate] != 1 {
+		t.Fatalf("期望重复的对象被丢弃、不同的对象被存入: %+v", s)
+	}
+	if len(discarded) != 1 || discarded[0] != DiscardDuplicate {
+		t.Errorf("期望以 %q 丢弃重复的对象, 得到 %v", DiscardDuplicate, discarded)
+	}
+	if got := p.Get(); got != b {
+		t.Error("期望取回不同的对象 b")
+	}
+	if got := p.Get(); got != a {
+		t.Error("期望取回最先放入的对象 a, 而不是与它相等的副本")
+	}
+}
@@ -0,0 +1,190 @@
+package gpool
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// seed 调用 newFunc 创建一个新对象并将它存入池中。预热创建的对象不计入 Stats 的 Misses。
+func (p *Pool[T]) seed() {
+	p.put(p.construct())
+}
+
+// WarmUp 调用 newFunc 创建 n 个对象并放入池中，使启动后的第一波请求不必承担创建对象的延迟。
+// 预热创建的对象不计入 Stats 的 Misses。
+//
+// sync.Pool 后端中的对象仍可能在之后的 GC 中被回收；需要保证闲置对象数量时请使用 EnsureAvailable。
+func (p *Pool[T]) WarmUp(n int) {
+	for i := 0; i < n; i++ {
+		p.seed()
+	}
+}
+
+// WarmUpContext 与 WarmUp 相同，但在 ctx 结束时提前停止，返回实际创建并放入池中的对象数量，
+// 使构造函数偶尔很慢甚至卡住时也不会无限期地阻塞启动。
+//
+// 即使某次 newFunc 调用卡住，WarmUpContext 也会在 ctx 结束时立即返回；
+// 这次调用在后台继续，它最终创建的对象仍会被放入池中，但不计入返回值。
+func (p *Pool[T]) WarmUpContext(ctx context.Context, n int) int {
+	if n <= 0 || ctx.Err() != nil {
+		return 0
+	}
+	var created int64
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < n && ctx.Err() == nil; i++ {
+			p.seed()
+			atomic.AddInt64(&created, 1)
+		}
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+	return int(atomic.LoadInt64(&created))
+}
+
+// WarmUpConcurrent 与 WarmUp 相同，但由 workers 个 goroutine 并发创建 n 个对象，
+// 适合构造函数较慢（例如需要建立网络连接）而需要预热大量对象的场景。workers <= 0 时使用 runtime.GOMAXPROCS(0)。
+//
+// 所有 goroutine 共享同一个由 ctx 派生的 context：ctx 结束时它们都会在当前的构造调用完成后停止，
+// WarmUpConcurrent 也会立即返回，不等待仍在进行中的构造调用。
+//
+// 返回值 created 是成功创建并放入池中的对象数量。通过 NewE 创建的池中构造函数返回的错误不会中断预热，
+// 而是被收集起来，连同 ctx 提前结束时对应的 ErrTimeout 或 ErrCancelled 类别的错误一起通过 errors.Join 合并返回；
+// 全部成功时 err 为 nil。达到 WithAllocCap 的上限后，各 goroutine 各报告一次 ErrAllocCap 后停止。
+func (p *Pool[T]) WarmUpConcurrent(ctx context.Context, n, workers int) (created int, err error) {
+	if n <= 0 {
+		return 0, nil
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > n {
+		workers = n
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		next, count int64
+		mu          sync.Mutex
+		errs        []error
+		wg          sync.WaitGroup
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil && atomic.AddInt64(&next, 1) <= int64(n) {
+				x, err := p.tryConstruct()
+				if err != nil {
+					mu.Lock()
+					errs = append(errs, err)
+					mu.Unlock()
+					if errors.Is(err, ErrAllocCap) {
+						return
+					}
+					continue
+				}
+				p.put(x)
+				atomic.AddInt64(&count, 1)
+			}
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+	// ctx 结束时只要还有对象没来得及创建，就报告 ctx 提前结束的错误。
+	var ctxErr error
+	if ctx.Err() != nil {
+		select {
+		case <-done:
+			if atomic.LoadInt64(&next) < int64(n) {
+				ctxErr = contextError(ctx.Err())
+			}
+		default:
+			ctxErr = contextError(ctx.Err())
+		}
+	}
+
+	// ctx 提前结束时仍有 goroutine 可能在追加错误，这里复制一份再合并。
+	mu.Lock()
+	all := append([]error(nil), errs...)
+	mu.Unlock()
+	return int(atomic.LoadInt64(&count)), errors.Join(append(all, ctxErr)...)
+}
+
+// EnsureAvailable 补充闲置对象，使池中至少有 n 个闲置对象可以立即取出，适合在已知的突发负载之前定向预热。
+// 需要时会调用 newFunc 创建新对象，这些对象不计入 Stats 的 Misses。
+//
+// 使用 WithCapacity 的固定容量后端或设置了 WithMaxIdle 的池最多补充到容量（闲置上限）为止，
+// 返回值是 n 超出容量而无法满足的数量；
+// 其他后端返回 0。
+// sync.Pool 后端无法统计闲置对象，也无法阻止它们被 GC 回收，因此 EnsureAvailable 对它不起作用，直接返回 n；
+// 已关闭的池同样返回 n。
+func (p *Pool[T]) EnsureAvailable(n int) int {
+	if n <= 0 {
+		return 0
+	}
+	if p.store == nil || p.isClosed() {
+		return n
+	}
+	target := p.idleLimit(n)
+	// 只补充一次差额，不反复检查闲置数量，以免 newFunc 返回的对象被丢弃时陷入死循环。
+	for i := p.store.len(); i < target; i++ {
+		p.seed()
+	}
+	return n - target
+}
+
+// idleLimit 返回 n 与池最多能保留的闲置对象数量（固定容量后端的容量和 WithMaxIdle 的上限）中较小的一个。
+func (p *Pool[T]) idleLimit(n int) int {
+	if p.cfg.backend == backendFixed && n > p.cfg.capacity {
+		n = p.cfg.capacity
+		if n < 0 {
+			n = 0
+		}
+	}
+	if p.cfg.maxIdle > 0 && n > p.cfg.maxIdle {
+		n = p.cfg.maxIdle
+	}
+	return n
+}
+
+// MarkWarm 通知使用 WithWarmupBarrier 创建的池预热已经完成，放行所有等待中和之后的 Get。
+// 重复调用是安全的；未设置 WithWarmupBarrier 的池调用它不起作用。Close 会自动调用它。
+func (p *Pool[T]) MarkWarm() {
+	if p.warm != nil && atomic.CompareAndSwapInt32(&p.warmed, 0, 1) {
+		close(p.warm)
+	}
+}
+
+// awaitWarm 在池启用了 WithWarmupBarrier 且尚未预热完成时等待 MarkWarm，最多等待配置的超时时间。
+func (p *Pool[T]) awaitWarm() {
+	if p.warm == nil || atomic.LoadInt32(&p.warmed) != 0 {
+		return
+	}
+	if p.cfg.warmupTimeout <= 0 {
+		<-p.warm
+		return
+	}
+	t := time.NewTimer(p.cfg.warmupTimeout)
+	defer t.Stop()
+	select {
+	case <-p.warm:
+	case <-t.C:
+	}
+}
@@ -0,0 +1,259 @@
+package gpool
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestPool_EnsureAvailable 测试 EnsureAvailable 将闲置对象补充到目标数量，超出容量时报告差额。
+func TestPool_EnsureAvailable(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithCapacity[*bytes.Buffer](4))
+
+	p.Put(new(bytes.Buffer))
+	if short := p.EnsureAvailable(3); short != 0 {
+		t.Fatalf("期望差额为 0, 得到 %d", short)
+	}
+	if s := p.Stats(); s.Idle != 3 || s.Misses != 0 {
+		t.Fatalf("期望 Idle=3 且预热不计入 Misses, 得到 %+v", s)
+	}
+
+	if short := p.EnsureAvailable(10); short != 6 {
+		t.Fatalf("期望差额为 6, 得到 %d", short)
+	}
+	if s := p.Stats(); s.Idle != 4 || s.Discards != 0 {
+		t.Fatalf("期望补充到容量 4 且没有丢弃, 得到 %+v", s)
+	}
+
+	// 闲置对象已经足够时不创建新对象。
+	if short := p.EnsureAvailable(2); short != 0 || p.Stats().Idle != 4 {
+		t.Fatalf("闲置对象足够时不应改变池, 差额 %d, %+v", short, p.Stats())
+	}
+}
+
+// TestPool_EnsureAvailable_SyncPool 测试 sync.Pool 后端无法保证闲置对象，EnsureAvailable 返回 n。
+func TestPool_EnsureAvailable_SyncPool(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	})
+	if short := p.EnsureAvailable(3); short != 3 {
+		t.Fatalf("期望差额为 3, 得到 %d", short)
+	}
+}
+
+// TestPool_WarmUp 测试 WarmUp 创建 n 个对象并放入池中，且不计入未命中。
+func TestPool_WarmUp(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer]())
+
+	p.WarmUp(3)
+	if s := p.Stats(); s.Idle != 3 || s.Misses != 0 {
+		t.Fatalf("期望 Idle=3 Misses=0, 得到 %+v", s)
+	}
+}
+
+// TestPool_WarmUp_Backends 测试 WarmUp 对 sync.Pool 后端和有界的后端同样有效：
+// 预热之后的 Get 直接复用预热的对象，固定容量后端只保留容量以内的对象，预热也不占用 WithMax 的借出额度。
+func TestPool_WarmUp_Backends(t *testing.T) {
+	var created int32
+	newBuffer := func() *bytes.Buffer {
+		atomic.AddInt32(&created, 1)
+		return new(bytes.Buffer)
+	}
+
+	// 预热足够多的对象，使竞态检测模式下 sync.Pool 随机丢弃一部分对象时仍能命中。
+	p := New(newBuffer)
+	p.WarmUp(16)
+	p.Get()
+	if n := atomic.LoadInt32(&created); n != 16 {
+		t.Fatalf("sync.Pool 后端预热之后的 Get 应该复用预热的对象, newFunc 被调用了 %d 次", n)
+	}
+
+	atomic.StoreInt32(&created, 0)
+	f := New(newBuffer, WithCapacity[*bytes.Buffer](2))
+	f.WarmUp(5)
+	if s := f.Stats(); s.Idle != 2 || s.DiscardsByReason[DiscardOverflow] != 3 {
+		t.Fatalf("固定容量后端应该只保留 2 个预热的对象, 得到 %+v", s)
+	}
+
+	b := New(newBuffer, WithDeterministic[*bytes.Buffer](), WithMax[*bytes.Buffer](2))
+	b.WarmUp(2)
+	x, y := b.Get(), b.Get()
+	if s := b.Stats(); s.Misses != 0 || s.Outstanding != 2 || x == y {
+		t.Fatalf("有界池应该借出预热的对象而不受预热影响, 得到 %+v", s)
+	}
+}
+
+// TestPool_WarmUpContext 测试 ctx 中途结束时 WarmUpContext 提前停止并返回实际创建的数量，
+// 即使构造函数卡住也不会阻塞。
+func TestPool_WarmUpContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	hang := make(chan struct{})
+	defer close(hang)
+	calls := 0
+	p := New(func() *bytes.Buffer {
+		calls++
+		if calls == 3 {
+			cancel()
+			<-hang // 第三次调用卡住，直到测试结束
+		}
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer]())
+
+	done := make(chan int)
+	go func() {
+		done <- p.WarmUpContext(ctx, 10)
+	}()
+	select {
+	case n := <-done:
+		if n != 2 {
+			t.Fatalf("期望创建 2 个对象, 得到 %d", n)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("构造函数卡住时 WarmUpContext 应该在 ctx 结束后返回")
+	}
+	if s := p.Stats(); s.Idle != 2 {
+		t.Fatalf("期望池中有 2 个闲置对象, 得到 %+v", s)
+	}
+
+	if n := p.WarmUpContext(ctx, 10); n != 0 {
+		t.Fatalf("ctx 已结束时不应创建对象, 得到 %d", n)
+	}
+}
+
+// TestPool_WarmupBarrier 测试 Get 等待 MarkWarm 之后再取出预热好的对象。
+func TestPool_WarmupBarrier(t *testing.T) {
+	var created int32
+	p := New(func() *bytes.Buffer {
+		atomic.AddInt32(&created, 1)
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer](), WithWarmupBarrier[*bytes.Buffer](0))
+
+	got := make(chan *bytes.Buffer, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			got <- p.Get()
+		}()
+	}
+	select {
+	case <-got:
+		t.Fatal("MarkWarm 之前 Get 不应返回")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	p.WarmUp(2)
+	p.MarkWarm()
+	p.MarkWarm()
+	for i := 0; i < 2; i++ {
+		select {
+		case <-got:
+		case <-time.After(time.Second):
+			t.Fatal("MarkWarm 之后 Get 应该返回")
+		}
+	}
+	if s := p.Stats(); s.Misses != 0 || atomic.LoadInt32(&created) != 2 {
+		t.Fatalf("期望早期的 Get 复用预热的对象, 创建了 %d 个, %+v", created, s)
+	}
+}
+
+// TestPool_WarmupBarrier_Timeout 测试从未调用 MarkWarm 时 Get 在超时后照常返回。
+func TestPool_WarmupBarrier_Timeout(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithWarmupBarrier[*bytes.Buffer](10*time.Millisecond))
+
+	start := time.Now()
+	if p.Get() == nil {
+		t.Fatal("超时后 Get 应该返回新对象")
+	}
+	if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+		t.Fatalf("Get 应该先等待超时, 实际只等待了 %v", elapsed)
+	}
+}
+
+// TestPool_WarmUpConcurrent 测试并发预热时部分构造失败不会中断预热，错误被合并返回，成功创建的对象都被放入池中。
+func TestPool_WarmUpConcurrent(t *testing.T) {
+	errOdd := errors.New("odd attempt")
+	var calls int64
+	p := NewE(func() (*bytes.Buffer, error) {
+		if atomic.AddInt64(&calls, 1)%2 == 1 {
+			return nil, errOdd
+		}
+		return new(bytes.Buffer), nil
+	}, WithDeterministic[*bytes.Buffer]())
+
+	created, err := p.WarmUpConcurrent(context.Background(), 10, 4)
+	if created != 5 {
+		t.Errorf("期望成功创建 5 个对象, 得到 %d", created)
+	}
+	if got := p.Stats().Idle; got != 5 {
+		t.Errorf("成功创建的对象都应该被放入池中, Idle 为 %d", got)
+	}
+	if !errors.Is(err, errOdd) {
+		t.Fatalf("返回的错误应该包含构造函数的错误, 得到 %v", err)
+	}
+	if n := len(err.(interface{ Unwrap() []error }).Unwrap()); n != 5 {
+		t.Errorf("期望合并 5 个错误, 得到 %d", n)
+	}
+	if s := p.Stats(); s.Misses != 0 {
+		t.Errorf("预热创建的对象不应计入 Misses, 得到 %d", s.Misses)
+	}
+}
+
+// TestPool_WarmUpConcurrent_Cancel 测试 ctx 取消后所有预热 goroutine 都会停止，并返回取消错误。
+func TestPool_WarmUpConcurrent_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int64
+	p := NewE(func() (*bytes.Buffer, error) {
+		if atomic.AddInt64(&calls, 1) == 8 {
+			cancel()
+		}
+		return new(bytes.Buffer), nil
+	}, WithDeterministic[*bytes.Buffer]())
+
+	created, err := p.WarmUpConcurrent(ctx, 1000, 4)
+	if !errors.Is(err, ErrCancelled) || !errors.Is(err, context.Canceled) {
+		t.Errorf("期望得到取消错误, 得到 %v", err)
+	}
+	if created >= 1000 {
+		t.Errorf("取消后不应继续创建对象, 创建了 %d 个", created)
+	}
+	// 每个 goroutine 最多完成它正在进行的那次构造。
+	time.Sleep(10 * time.Millisecond)
+	if n := atomic.LoadInt64(&calls); n > 8+4 {
+		t.Errorf("取消后各 goroutine 应该及时停止, 构造函数被调用了 %d 次", n)
+	}
+}
+
+// TestPool_WarmUpConcurrent_AllocCap 测试达到创建上限时预热停止，并报告 ErrAllocCap。
+func TestPool_WarmUpConcurrent_AllocCap(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer](), WithAllocCap[*bytes.Buffer](3))
+
+	created, err := p.WarmUpConcurrent(context.Background(), 10, 2)
+	if created != 3 || !errors.Is(err, ErrAllocCap) {
+		t.Errorf("期望创建 3 个对象并报告 ErrAllocCap, 得到 %d 和 %v", created, err)
+	}
+}
+
+// TestNewE_GetPanics 测试 NewE 创建的池在构造失败时 Get 以 KindNewFailed 类别的 PoolError panic。
+func TestNewE_GetPanics(t *testing.T) {
+	errDial := errors.New("dial failed")
+	p := NewE(func() (*bytes.Buffer, error) {
+		return nil, errDial
+	})
+	defer func() {
+		err, _ := recover().(error)
+		if !errors.Is(err, ErrNewFailed) || !errors.Is(err, errDial) {
+			t.Errorf("期望以包装了构造错误的 ErrNewFailed panic, 得到 %v", err)
+		}
+	}()
+	p.Get()
+}
@@ -0,0 +1,136 @@
+//go:build go1.24
+
+package gpool
+
+import (
+	"sync"
+	"weak"
+)
+
+// weakStore 是一个只通过弱引用持有闲置对象的 LIFO 栈。
+type weakStore[E any] struct {
+	mu    sync.Mutex
+	items []weak.Pointer[E]
+}
+
+func newWeakStore[E any]() store[*E] {
+	return &weakStore[E]{}
+}
+
+func (s *weakStore[E]) get() (*E, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// 从栈顶开始查找，跳过已被 GC 回收的对象。
+	for len(s.items) > 0 {
+		n := len(s.items) - 1
+		x := s.items[n].Value()
+		s.items[n] = weak.Pointer[E]{}
+		s.items = s.items[:n]
+		if x != nil {
+			return x, true
+		}
+	}
+	return nil, false
+}
+
+func (s *weakStore[E]) put(x *E) bool {
+	s.mu.Lock()
+	s.items = append(s.items, weak.Make(x))
+	s.mu.Unlock()
+	return true
+}
+
+// len 返回尚未被 GC 回收的闲置对象数量。
+func (s *weakStore[E]) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, w := range s.items {
+		if w.Value() != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// drain 返回所有尚未被 GC 回收的闲置对象。
+func (s *weakStore[E]) drain() []*E {
+	s.mu.Lock()
+	items := s.items
+	s.items = nil
+	s.mu.Unlock()
+
+	var xs []*E
+	for _, w := range items {
+		if x := w.Value(); x != nil {
+			xs = append(xs, x)
+		}
+	}
+	return xs
+}
+
+// each 对每个尚未被 GC 回收的闲置对象调用 fn。
+func (s *weakStore[E]) each(fn func(*E)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, w := range s.items {
+		if x := w.Value(); x != nil {
+			fn(x)
+		}
+	}
+}
+
+// filter 在检查对象的同时移除已被 GC 回收的对象。
+func (s *weakStore[E]) filter(drop func(*E) bool) []*E {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var dropped []*E
+	kept := s.items[:0]
+	for _, w := range s.items {
+		x := w.Value()
+		switch {
+		case x == nil:
+		case drop(x):
+			dropped = append(dropped, x)
+		default:
+			kept = append(kept, w)
+		}
+	}
+	for i := len(kept); i < len(s.items); i++ {
+		s.items[i] = weak.Pointer[E]{}
+	}
+	s.items = kept
+	return dropped
+}
+
+func (s *weakStore[E]) compact(force bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !shouldCompact(len(s.items), cap(s.items), force) {
+		return
+	}
+	if len(s.items) == 0 {
+		s.items = nil
+		return
+	}
+	items := make([]weak.Pointer[E], len(s.items))
+	copy(items, s.items)
+	s.items = items
+}
+
+// swap 用 build 返回的对象替换所有尚未被 GC 回收的闲置对象。
+func (s *weakStore[E]) swap(build func(n int) []*E) []*E {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var old []*E
+	for _, w := range s.items {
+		if x := w.Value(); x != nil {
+			old = append(old, x)
+		}
+	}
+	s.items = s.items[:0]
+	for _, x := range build(len(old)) {
+		s.items = append(s.items, weak.Make(x))
+	}
+	return old
+}
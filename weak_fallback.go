@@ -0,0 +1,8 @@
+//go:build !go1.24
+
+package gpool
+
+// newWeakStore 在 Go 1.24 之前没有 weak 包可用，返回 nil 使池回退到 sync.Pool。
+func newWeakStore[E any]() store[*E] {
+	return nil
+}
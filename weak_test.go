@@ -0,0 +1,111 @@
+//go:build go1.24
+
+package gpool
+
+import (
+	"bytes"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestPool_WeakRetention_Reuse 测试未被 GC 回收的闲置对象会被复用。
+func TestPool_WeakRetention_Reuse(t *testing.T) {
+	var newCounter int32
+	p := New(func() *bytes.Buffer {
+		atomic.AddInt32(&newCounter, 1)
+		return new(bytes.Buffer)
+	}, WithWeakRetention[bytes.Buffer]())
+
+	buf := p.Get()
+	p.Put(buf)
+
+	if got := p.Get(); got != buf {
+		t.Fatal("未被回收的闲置对象应该被复用")
+	}
+	// buf 始终被本函数引用，即使发生 GC 也不会被回收。
+	p.Put(buf)
+	runtime.GC()
+	if got := p.Get(); got != buf {
+		t.Fatal("仍被引用的对象在 GC 之后应该依然可以复用")
+	}
+	if n := atomic.LoadInt32(&newCounter); n != 1 {
+		t.Fatalf("期望 newFunc 只被调用一次, 实际 %d 次", n)
+	}
+}
+
+// TestPool_WeakRetention_Collected 测试闲置对象被 GC 回收后，Get 会回退到 newFunc。
+func TestPool_WeakRetention_Collected(t *testing.T) {
+	var newCounter int32
+	p := New(func() *bytes.Buffer {
+		atomic.AddInt32(&newCounter, 1)
+		return new(bytes.Buffer)
+	}, WithWeakRetention[bytes.Buffer]())
+
+	// 在单独的函数中放回对象，保证当前栈上不再持有它的强引用。
+	func() {
+		p.Put(p.Get())
+	}()
+	runtime.GC()
+
+	if n := p.store.len(); n != 0 {
+		t.Fatalf("GC 之后闲置对象应该已被回收, 但池中还有 %d 个", n)
+	}
+	if buf := p.Get(); buf == nil {
+		t.Fatal("闲置对象被回收后, Get() 应该回退到 newFunc 创建新对象")
+	}
+	if n := atomic.LoadInt32(&newCounter); n != 2 {
+		t.Fatalf("期望 newFunc 被调用 2 次, 实际 %d 次", n)
+	}
+}
+
+// TestPool_WeakRetention_Config 测试 Config 报告弱引用后端。
+func TestPool_WeakRetention_Config(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithWeakRetention[bytes.Buffer]())
+
+	if got := p.Config().Backend; got != BackendWeak {
+		t.Fatalf("期望后端为 %q, 得到 %q", BackendWeak, got)
+	}
+}
+
+// TestPool_Detach_Collected 测试被分离后直接丢掉的对象可以被 GC 回收，池为它们保留的记录也会随之被清理。
+func TestPool_Detach_Collected(t *testing.T) {
+	p := New(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, WithDeterministic[*bytes.Buffer]())
+
+	var collected int32
+	for i := 0; i < 4*detachPruneMin; i++ {
+		x := p.Get()
+		runtime.SetFinalizer(x, func(*bytes.Buffer) { atomic.AddInt32(&collected, 1) })
+		p.Detach(x)
+		if i == 2*detachPruneMin {
+			// 让前一半对象在后续的 Detach 清理记录之前被回收。
+			runtime.GC()
+			runtime.GC()
+		}
+	}
+	// finalizer 在单独的 goroutine 中异步运行。
+	for i := 0; i < 100 && atomic.LoadInt32(&collected) == 0; i++ {
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+	if atomic.LoadInt32(&collected) == 0 {
+		t.Fatal("被分离后不再被引用的对象应该可以被 GC 回收")
+	}
+	if n := atomic.LoadInt32(&p.detached.n); n >= 4*detachPruneMin {
+		t.Errorf("已被回收的对象的记录应该被清理, 仍有 %d 条", n)
+	}
+
+	// 仍被引用的被分离对象放回时依然会被识别出来。
+	x := p.Get()
+	p.Detach(x)
+	runtime.GC()
+	p.Put(x)
+	if s := p.Stats(); s.Idle != 0 || s.DiscardsByReason[DiscardDetached] != 1 {
+		t.Errorf("被分离的对象放回时应该被丢弃: %+v", s)
+	}
+}